@@ -1,27 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gifflet/dlock/pkg/dlock"
+	"github.com/gifflet/dlock/pkg/dlock/httpserver"
+	"github.com/gifflet/dlock/pkg/dlock/server"
 )
 
 func main() {
-	// Handle Ctrl+C gracefully
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		<-c
-		fmt.Println("\n\n⛔ Script interrupted by user. Exiting...")
-		os.Exit(0)
-	}()
-
 	// Handle panics gracefully
 	defer func() {
 		if r := recover(); r != nil {
@@ -31,7 +28,23 @@ func main() {
 	}()
 
 	// Parse command line arguments
-	var devicesFlag = flag.String("devices", "", "Space-separated list of device UDIDs to process (optional). If not specified, all connected devices will be processed.")
+	flag.String("devices", "", "Space-separated list of device UDIDs to process (optional). If not specified, all connected devices will be processed.")
+	var devicesFileFlag = flag.String("devices-file", "", "File listing the device UDIDs to process, one per line (optional, cannot be combined with -devices)")
+	var configFlag = flag.String("config", "", "Path to a JSON or YAML config file (optional)")
+	var explainConfigFlag = flag.Bool("explain-config", false, "Show the resolved configuration and conflicts between sources")
+	var scriptOutputFlag = flag.String("script-output", "", "Write the ADB commands to a shell script instead of executing them (requires -devices)")
+	flag.Int("method", 0, "Only try this disable method (1-5) instead of falling back through all of them")
+	flag.Bool("dry-run", false, "Report what would be done without changing any device")
+	flag.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	flag.Bool("no-color", false, "Disable colored output (also disabled by the NO_COLOR environment variable)")
+	flag.Bool("desktop-notification", false, "Show a desktop notification when the batch completes")
+	flag.String("report-output", "", "Write an HTML report of the run to this path (optional)")
+	flag.String("csv-output", "", "Write the per-device results as CSV to this path (optional)")
+	flag.String("screenshot-dir", "", "Save before/after screenshots of each device to this directory (optional)")
+	flag.String("audit-log", "", "Append every ADB command and its raw output to this file as JSON lines (optional)")
+	flag.String("alias-file", "", "JSON file mapping device serials to human-readable names (optional)")
+	var httpAddrFlag = flag.String("http-addr", "", "Run as an HTTP JSON API server listening on this address (e.g. :8080) instead of processing devices once")
+	var grpcAddrFlag = flag.String("grpc-addr", "", "Run as a gRPC server listening on this address (e.g. :50051) instead of processing devices once")
 	var helpFlag = flag.Bool("help", false, "Show help information")
 	flag.Parse()
 
@@ -47,6 +60,47 @@ func main() {
 		fmt.Println("  -devices string")
 		fmt.Println("        Space-separated list of device UDIDs to process (optional)")
 		fmt.Println("        Example: -devices \"device1 device2 device3\"")
+		fmt.Println("  -devices-file string")
+		fmt.Println("        File listing the device UDIDs to process, separated by newlines or spaces")
+		fmt.Println("        Blank lines and lines starting with # are ignored; cannot be combined with -devices")
+		fmt.Println("  -config string")
+		fmt.Println("        Path to a JSON or YAML (.yaml, .yml) config file (optional)")
+		fmt.Println("        Precedence: config file < DLOCK_* environment variables < flags")
+		fmt.Println("  -explain-config")
+		fmt.Println("        Show the resolved configuration and conflicts between sources")
+		fmt.Println("  -script-output string")
+		fmt.Println("        Write the ADB commands to an executable shell script instead of running them")
+		fmt.Println("        Requires -devices since no device is contacted")
+		fmt.Println("  -method int")
+		fmt.Println("        Only try this disable method (1-5) instead of falling back through all of them")
+		fmt.Println("  -dry-run")
+		fmt.Println("        Detect devices and lock screens but skip every command that changes a device")
+		fmt.Println("  -log-level string")
+		fmt.Println("        Minimum log level: debug, info, warn or error (default \"info\")")
+		fmt.Println("        Use debug to see device detection and every method attempt")
+		fmt.Println("  -no-color")
+		fmt.Println("        Disable colored output; setting the NO_COLOR environment variable does the same")
+		fmt.Println("  -desktop-notification")
+		fmt.Println("        Show a desktop notification when the batch completes (osascript, notify-send or BurntToast)")
+		fmt.Println("  -report-output string")
+		fmt.Println("        Write a self-contained HTML report of the run to this path (optional)")
+		fmt.Println("  -csv-output string")
+		fmt.Println("        Write the per-device results as CSV to this path (optional)")
+		fmt.Println("  -screenshot-dir string")
+		fmt.Println("        Save <serial>-before.png and <serial>-after.png for each device to this directory (optional)")
+		fmt.Println("  -audit-log string")
+		fmt.Println("        Append every ADB command and its raw output to this file as JSON lines (optional)")
+		fmt.Println("        Example: jq 'select(.exit_code != 0)' audit.jsonl")
+		fmt.Println("  -alias-file string")
+		fmt.Println("        JSON file mapping device serials to human-readable names (optional)")
+		fmt.Println("        Example: {\"R58MC1ABLWF\": \"Rack Slot 3 - Samsung S21\"}")
+		fmt.Println("  -http-addr string")
+		fmt.Println("        Run as an HTTP JSON API server listening on this address instead of processing devices once")
+		fmt.Println("        Routes: GET /health, GET /devices, POST /devices/{serial}/disable,")
+		fmt.Println("        GET /devices/{serial}/status, GET /devices/{serial}/info, GET /jobs/{id}")
+		fmt.Println("  -grpc-addr string")
+		fmt.Println("        Run as a gRPC server listening on this address instead of processing devices once")
+		fmt.Println("        The service is defined in proto/dlock.proto")
 		fmt.Println("  -help")
 		fmt.Println("        Show this help information")
 		fmt.Println()
@@ -57,19 +111,235 @@ func main() {
 		fmt.Println("  # Process specific devices:")
 		fmt.Println("  dlock -devices \"ABC123DEF456 789GHI012JKL\"")
 		fmt.Println()
+		fmt.Println("  # Preview what would be done:")
+		fmt.Println("  dlock -dry-run")
+		fmt.Println()
+		fmt.Println("  # Save an HTML report of the run:")
+		fmt.Println("  dlock -report-output report.html")
+		fmt.Println()
+		fmt.Println("  # Generate a script for manual execution:")
+		fmt.Println("  dlock -devices \"ABC123DEF456\" -script-output disable.sh")
+		fmt.Println()
+		fmt.Println("  # Serve a JSON API over HTTP:")
+		fmt.Println("  dlock -http-addr :8080")
+		fmt.Println()
+		fmt.Println("  # Serve requests over gRPC:")
+		fmt.Println("  dlock -grpc-addr :50051")
+		fmt.Println()
 		fmt.Println("  # List connected devices to get their UDIDs:")
 		fmt.Println("  adb devices")
 		return
 	}
 
-	// Parse target devices from command line argument
-	var targetDevices []string
-	if *devicesFlag != "" {
-		targetDevices = strings.Fields(*devicesFlag)
+	// Resolve configuration from file, environment and flags
+	builder := dlock.NewConfigBuilder()
+	if *configFlag != "" {
+		builder.FromFile(*configFlag)
+	}
+	cfg, conflicts, err := builder.FromEnv().FromFlags(flag.CommandLine).Build()
+	if err != nil {
+		fmt.Printf("❌ Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *explainConfigFlag {
+		explainConfig(cfg, conflicts)
+		return
+	}
+
+	// Load target devices from a file instead of -devices
+	if *devicesFileFlag != "" {
+		if flagSet("devices") {
+			fmt.Println("❌ -devices-file cannot be combined with -devices")
+			os.Exit(1)
+		}
+		devices, err := dlock.ReadDevicesFromFile(*devicesFileFlag)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		cfg.Devices = devices
+	}
+
+	// Parse target devices from configuration
+	targetDevices := cfg.Devices
+	if len(targetDevices) > 0 {
 		fmt.Printf("🎯 Target devices specified: %s\n", strings.Join(targetDevices, ", "))
 	}
 
+	// Write a script of the ADB commands instead of executing them
+	if *scriptOutputFlag != "" {
+		if len(targetDevices) == 0 {
+			fmt.Println("❌ -script-output requires -devices to be specified")
+			os.Exit(1)
+		}
+
+		script := dlock.NewScriptExecutor()
+		disabler := dlock.NewAndroidLockScreenDisabler(targetDevices, append(cfg.Options(), dlock.WithADBExecutor(script))...)
+		disabler.SetLogging(false)
+		disabler.ProcessDevices(context.Background(), targetDevices)
+
+		if err := script.WriteFile(*scriptOutputFlag); err != nil {
+			fmt.Printf("❌ Failed to write script: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 Script written to %s\n", *scriptOutputFlag)
+		return
+	}
+
 	// Create and run the disabler
-	disabler := dlock.NewAndroidLockScreenDisabler(targetDevices)
-	disabler.Run()
+	disabler, err := dlock.NewAndroidLockScreenDisablerWithError(cfg.Devices, append(cfg.Options(), dlock.WithDrainOnCancel(true))...)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	// Serve requests over HTTP or gRPC instead of processing devices once
+	if *httpAddrFlag != "" {
+		serveHTTP(*httpAddrFlag, disabler)
+		return
+	}
+	if *grpcAddrFlag != "" {
+		serveGRPC(*grpcAddrFlag, disabler)
+		return
+	}
+
+	// On Ctrl+C or SIGTERM, stop starting new ADB commands, let running ones finish and save what completed.
+	// A second signal exits immediately.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		signal.Stop(c)
+		fmt.Println("\n\n⛔ Interrupted, waiting for running ADB commands to finish (press Ctrl+C again to force)...")
+		cancel()
+	}()
+
+	report := disabler.RunContext(ctx)
+	if ctx.Err() != nil {
+		savePartialResults(report)
+		os.Exit(exitInterrupted)
+	}
+}
+
+// partialResultsFile receives the results of an interrupted run
+const partialResultsFile = "partial-results.json"
+
+// exitInterrupted is the exit code of a run stopped by SIGINT or SIGTERM
+const exitInterrupted = 2
+
+// flagSet reports whether the named flag was given on the command line
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// savePartialResults writes the results of an interrupted run to partialResultsFile
+func savePartialResults(report dlock.ProcessingReport) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(partialResultsFile, data, 0o644)
+	}
+	if err != nil {
+		fmt.Printf("❌ Failed to save partial results: %v\n", err)
+		return
+	}
+	fmt.Printf("💾 Partial results of %d device(s) saved to %s\n", len(report.Results), partialResultsFile)
+}
+
+// serverShutdownTimeout is how long in-flight requests get to finish once a server is asked to stop
+const serverShutdownTimeout = 30 * time.Second
+
+// serveHTTP runs the HTTP API on addr until SIGINT or SIGTERM, then shuts it down gracefully
+func serveHTTP(addr string, disabler *dlock.AndroidLockScreenDisabler) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	api := httpserver.NewServer(disabler)
+	srv := &http.Server{Addr: addr, Handler: api}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+	fmt.Printf("🌐 HTTP server listening on %s\n", addr)
+
+	select {
+	case err := <-serveErr:
+		fmt.Printf("❌ HTTP server stopped: %v\n", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	fmt.Println("\n⏳ Shutting down HTTP server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("❌ HTTP server did not shut down cleanly: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Running jobs stop between ADB commands, recording their devices as cancelled
+	if err := api.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("❌ Disable jobs did not stop in time: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// serveGRPC runs the gRPC service on addr until SIGINT or SIGTERM, then stops it gracefully
+func serveGRPC(addr string, disabler *dlock.AndroidLockScreenDisabler) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Printf("❌ Failed to listen on %s: %v\n", addr, err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.NewServer(disabler)
+	go func() {
+		<-ctx.Done()
+		fmt.Println("\n⏳ Shutting down gRPC server...")
+		time.AfterFunc(serverShutdownTimeout, srv.Stop)
+		srv.GracefulStop()
+	}()
+
+	fmt.Printf("🌐 gRPC server listening on %s\n", lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		fmt.Printf("❌ gRPC server stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// explainConfig prints the resolved configuration and any conflicts between sources
+func explainConfig(cfg *dlock.Config, conflicts []dlock.ConfigConflict) {
+	resolved, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		fmt.Printf("❌ Failed to format configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Resolved configuration:")
+	fmt.Println(string(resolved))
+	fmt.Println()
+
+	if len(conflicts) == 0 {
+		fmt.Println("No conflicts between configuration sources.")
+		return
+	}
+
+	fmt.Println("Conflicts (precedence: file < env < flags):")
+	for _, conflict := range conflicts {
+		fmt.Printf("  %s: file=%q env=%q flag=%q -> %q\n",
+			conflict.Key, conflict.FileValue, conflict.EnvValue, conflict.FlagValue, conflict.ResolvedValue)
+	}
 }
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gifflet/dlock/pkg/dlock"
 )
@@ -26,10 +28,17 @@ func main() {
 
 	fmt.Printf("Found %d devices: %v\n", len(devices), devices)
 
-	// Process all devices
-	successCount, failedDevices, totalDevices := disabler.ProcessDevices(devices)
+	// Process all devices, giving up after 10 minutes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
 
-	fmt.Printf("Results: %d/%d successful, failed: %v\n", successCount, totalDevices, failedDevices)
+	results := disabler.ProcessDevices(ctx, devices)
+	summary := dlock.NewBatchSummary(results)
+
+	fmt.Printf("Results: %d/%d successful, failed: %v\n", summary.SuccessCount, summary.TotalDevices, summary.FailedDevices)
+	for _, result := range results {
+		fmt.Printf("  %s: method %d in %s\n", result.Serial, result.MethodUsed, result.Duration)
+	}
 
 	// Example 2: Process specific devices
 	fmt.Println("\n=== Example 2: Process specific devices ===")
@@ -41,10 +50,10 @@ func main() {
 	specificDisabler.SetLogging(false)
 
 	devices = specificDisabler.GetConnectedDevices()
-	successCount, failedDevices, totalDevices = specificDisabler.ProcessDevices(devices)
+	summary = dlock.NewBatchSummary(specificDisabler.ProcessDevices(ctx, devices))
 
 	fmt.Printf("Targeted processing results: %d/%d successful, failed: %v\n",
-		successCount, totalDevices, failedDevices)
+		summary.SuccessCount, summary.TotalDevices, summary.FailedDevices)
 
 	// Example 3: Process single device
 	fmt.Println("\n=== Example 3: Process single device ===")
@@ -69,7 +78,22 @@ func main() {
 		fmt.Printf("  API Level: %s\n", deviceInfo.APILevel)
 
 		// Check if device has lock screen
-		hasLock, lockType := infoDisabler.CheckExistingLockScreen(devices[0])
-		fmt.Printf("  Has Lock Screen: %t (%s)\n", hasLock, lockType)
+		lockInfo, err := infoDisabler.CheckExistingLockScreen(devices[0])
+		if err != nil {
+			fmt.Printf("  Lock Screen: unknown (%v)\n", err)
+		} else {
+			fmt.Printf("  Lock Screen: %s (%s)\n", lockInfo.Type, lockInfo.Description)
+		}
+	}
+
+	// Example 5: Report each device as soon as it finishes
+	fmt.Println("\n=== Example 5: Stream results as devices finish ===")
+
+	streamDisabler := dlock.NewAndroidLockScreenDisabler(nil)
+	streamDisabler.SetLogging(false)
+
+	stream, _ := streamDisabler.ProcessDevicesStream(ctx, devices)
+	for result := range stream {
+		fmt.Printf("  %s finished: %s in %s\n", result.Serial, result.Status, result.Duration)
 	}
 }
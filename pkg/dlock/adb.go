@@ -3,35 +3,256 @@ package dlock
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"math/rand"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// CommandCategory groups ADB commands that share a timeout override
+type CommandCategory string
+
+// Command categories
+const (
+	CommandCategoryPropertyRead CommandCategory = "property-read"
+	CommandCategoryReboot       CommandCategory = "reboot"
+)
+
+// commandCategory returns the category of an ADB command, or "" if it has none
+func commandCategory(command string) CommandCategory {
+	switch {
+	case strings.HasPrefix(command, "shell getprop"):
+		return CommandCategoryPropertyRead
+	case strings.HasPrefix(command, "reboot"):
+		return CommandCategoryReboot
+	default:
+		return ""
+	}
+}
+
+// commandTimeout returns the deadline to apply to an ADB command
+func (a *AndroidLockScreenDisabler) commandTimeout(command string) time.Duration {
+	if timeout, ok := a.cfg.categoryTimeouts[commandCategory(command)]; ok {
+		return timeout
+	}
+	return a.cfg.commandTimeout
+}
+
 // runADBCommand executes an ADB command and returns success, output, and error
-func (a *AndroidLockScreenDisabler) runADBCommand(command string, deviceSerial string) (bool, string, string) {
-	var fullCommand string
+func (a *AndroidLockScreenDisabler) runADBCommand(ctx context.Context, command string, deviceSerial string) (bool, string, string) {
+	return a.runADBCommandWithTimeout(ctx, command, deviceSerial, a.commandTimeout(command))
+}
+
+// runADBCommandWithTimeout is runADBCommand bounding each attempt by timeout instead of the command's timeout
+func (a *AndroidLockScreenDisabler) runADBCommandWithTimeout(ctx context.Context, command string, deviceSerial string, timeout time.Duration) (bool, string, string) {
+	args := []string{command}
 	if deviceSerial != "" {
-		fullCommand = fmt.Sprintf("adb -s %s %s", deviceSerial, command)
-	} else {
-		fullCommand = fmt.Sprintf("adb %s", command)
+		if err := a.checkSerial(deviceSerial); err != nil {
+			return false, "", err.Error()
+		}
+		args = []string{"-s", deviceSerial, command}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	if a.cfg.dryRun && isMutatingCommand(command) {
+		a.log(LogLevelInfo, fmt.Sprintf("[DRY-RUN] Skipping adb %s", strings.Join(args, " ")), "📝", "device", deviceSerial)
+		return true, "", ""
+	}
 
-	var cmd *exec.Cmd
+	success, output, errorMsg := a.executeADBInvocation(ctx, args, timeout, nil)
+	for retry := 1; !success && retry <= a.cfg.maxRetries && isRetryableADBError(errorMsg); retry++ {
+		if !a.cfg.sleep(ctx, a.retryDelay(retry)) {
+			break
+		}
+		success, output, errorMsg = a.executeADBInvocation(ctx, args, timeout, nil)
+	}
 
-	// Use appropriate shell based on operating system
-	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(ctx, "cmd", "/c", fullCommand)
-	} else {
-		cmd = exec.CommandContext(ctx, "sh", "-c", fullCommand)
+	if success {
+		a.consecutiveFailures.Store(0)
+	} else if a.cfg.autoRestartADB && a.consecutiveFailures.Add(1) >= adbRestartThreshold {
+		// Many failures in a row usually mean the ADB server died, so check it and run the command again once it is back
+		a.consecutiveFailures.Store(0)
+		if err := a.CheckADBHealth(ctx); err == nil {
+			success, output, errorMsg = a.executeADBInvocation(ctx, args, timeout, nil)
+		}
+	}
+
+	return success, output, errorMsg
+}
+
+// adbRestartThreshold is the number of consecutive failed commands that triggers an ADB health check
+const adbRestartThreshold = 5
+
+// adbRestartAttempts is how many times CheckADBHealth restarts the ADB server before giving up
+const adbRestartAttempts = 3
+
+// CheckADBHealth runs `adb version` and, if it fails, restarts the ADB server until it responds.
+// Concurrent calls are serialized so only one restart happens at a time.
+func (a *AndroidLockScreenDisabler) CheckADBHealth(ctx context.Context) error {
+	a.adbRestartMu.Lock()
+	defer a.adbRestartMu.Unlock()
+
+	if success, _, _ := a.executeADBCommand(ctx, []string{"version"}, "version"); success {
+		return nil
 	}
 
-	output, err := cmd.CombinedOutput()
+	a.log(LogLevelWarn, "ADB server is not responding, restarting it...", "🔄")
+	for attempt := 1; attempt <= adbRestartAttempts; attempt++ {
+		a.executeADBCommand(ctx, []string{"kill-server"}, "kill-server")
+		if !a.cfg.sleep(ctx, 1*time.Second) {
+			return ctx.Err()
+		}
+
+		a.executeADBCommand(ctx, []string{"start-server"}, "start-server")
+		if !a.cfg.sleep(ctx, 2*time.Second) {
+			return ctx.Err()
+		}
+
+		if success, _, _ := a.executeADBCommand(ctx, []string{"version"}, "version"); success {
+			a.log(LogLevelInfo, fmt.Sprintf("ADB server restarted (attempt %d)", attempt), "✅")
+			return nil
+		}
+	}
+
+	a.log(LogLevelError, "ADB server did not recover", "❌")
+	return fmt.Errorf("ADB server did not respond after %d restart attempts", adbRestartAttempts)
+}
+
+// mutatingCommandFragments identify ADB commands that change device state and are skipped in dry-run mode
+var mutatingCommandFragments = []string{
+	"settings put",
+	"settings delete",
+	"reboot",
+	"dpm remove-active-admin",
+	"am task lock stop",
+	"wm dismiss-keyguard",
+	"date @",
+	"input ",
+	"> /dev/cpuset/",
+}
+
+// isMutatingCommand reports whether an ADB command changes device state
+func isMutatingCommand(command string) bool {
+	for _, fragment := range mutatingCommandFragments {
+		if strings.Contains(command, fragment) {
+			return true
+		}
+	}
+	return isMutatingLocksettings(command)
+}
+
+// isMutatingLocksettings reports whether a command runs a locksettings subcommand other than get-*.
+// Options such as --user and --old may sit between locksettings and its subcommand, and each takes a value.
+func isMutatingLocksettings(command string) bool {
+	fields := strings.Fields(command)
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], `"'`) != "locksettings" {
+			continue
+		}
+		for i++; i < len(fields); i++ {
+			arg := strings.Trim(fields[i], `"'`)
+			if strings.HasPrefix(arg, "-") {
+				i++ // Skip the option's value
+				continue
+			}
+			if !strings.HasPrefix(arg, "get-") {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// nonRetryableADBErrors are error fragments that retrying cannot fix
+var nonRetryableADBErrors = []string{
+	"command not found",
+	": not found", // The device shell's "/system/bin/sh: cmd: not found", unlike adb's "device 'X' not found"
+	"unauthorized",
+	"no such file or directory",
+	"is not recognized as an internal or external command",
+}
+
+// isRetryableADBError reports whether a failed ADB command may succeed when retried
+func isRetryableADBError(errorMsg string) bool {
+	lower := strings.ToLower(errorMsg)
+	for _, fragment := range nonRetryableADBErrors {
+		if strings.Contains(lower, fragment) {
+			return false
+		}
+	}
+	return true
+}
+
+// retryDelay returns the exponential backoff with jitter to wait before the given retry (starting at 1)
+func (a *AndroidLockScreenDisabler) retryDelay(retry int) time.Duration {
+	delay := a.cfg.retryInitial
+	for i := 1; i < retry && delay < a.cfg.retryMax; i++ {
+		delay *= 2
+	}
+	if delay > a.cfg.retryMax {
+		delay = a.cfg.retryMax
+	}
+
+	// Wait between half and the full delay so concurrent devices do not retry in lockstep
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// execute runs adb with the given arguments, over the connection pool when it can handle them
+func (a *AndroidLockScreenDisabler) execute(ctx context.Context, args []string) (int, []byte, error) {
+	if a.cfg.connPool != nil {
+		// The pool bypasses the adb client, so only use it in place of the default executor
+		if _, ok := a.cfg.executor.(RealADBExecutor); ok {
+			if _, _, poolable := poolableCommand(args); poolable {
+				exitCode, output, err := a.cfg.connPool.Execute(ctx, args)
+				if !errors.Is(err, errPoolUnavailable) {
+					return exitCode, output, err
+				}
+				a.log(LogLevelDebug, fmt.Sprintf("Connection pool unavailable, using adb: %v", err), "🔌")
+			}
+		}
+	}
+	return a.cfg.executor.Execute(ctx, args)
+}
+
+// executeADBCommand performs a single ADB invocation bounded by the command's timeout
+func (a *AndroidLockScreenDisabler) executeADBCommand(parent context.Context, args []string, command string) (bool, string, string) {
+	return a.executeADBInvocation(parent, args, a.commandTimeout(command), nil)
+}
+
+// executeADBInvocation performs a single ADB invocation bounded by timeout, feeding input to its
+// standard input unless it is nil
+func (a *AndroidLockScreenDisabler) executeADBInvocation(parent context.Context, args []string, timeout time.Duration, input []byte) (bool, string, string) {
+	if a.cfg.commandLimiter != nil {
+		if err := a.cfg.commandLimiter.Wait(parent); err != nil {
+			return false, "", fmt.Sprintf("rate limit: %v", err)
+		}
+	}
+
+	if a.cfg.drainOnCancel {
+		if err := parent.Err(); err != nil {
+			return false, "", err.Error()
+		}
+		parent = context.WithoutCancel(parent)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var exitCode int
+	var output []byte
+	var err error
+	if input != nil {
+		exitCode, output, err = a.cfg.executor.(ADBInputExecutor).ExecuteWithInput(ctx, args, input)
+	} else {
+		exitCode, output, err = a.execute(ctx, args)
+	}
+	a.audit(args, exitCode, output, err, time.Since(start))
 
 	if err != nil {
 		if ctx.Err() == context.DeadlineExceeded {
@@ -40,93 +261,316 @@ func (a *AndroidLockScreenDisabler) runADBCommand(command string, deviceSerial s
 		return false, "", err.Error()
 	}
 
-	return cmd.ProcessState.ExitCode() == 0, strings.TrimSpace(string(output)), ""
+	if exitCode != 0 {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return false, "", fmt.Sprintf("exit status %d: %s", exitCode, message)
+		}
+		return false, "", fmt.Sprintf("exit status %d", exitCode)
+	}
+
+	return true, strings.TrimSpace(string(output)), ""
 }
 
 // CheckADBAvailability checks if ADB is available in the system
 func (a *AndroidLockScreenDisabler) CheckADBAvailability() bool {
-	a.log("Checking ADB availability...", "🔍")
-	success, _, errorMsg := a.runADBCommand("version", "")
+	return a.checkADBAvailability(context.Background())
+}
+
+// checkADBAvailability implements CheckADBAvailability, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) checkADBAvailability(ctx context.Context) bool {
+	a.log(LogLevelDebug, "Checking ADB availability...", "🔍")
+	success, output, errorMsg := a.runADBCommand(ctx, "version", "")
 
 	if success {
-		a.log("ADB is available and working!", "✅")
+		a.log(LogLevelInfo, "ADB is available and working!", "✅")
+		if version, err := parseADBVersion(output); err == nil {
+			a.log(LogLevelInfo, fmt.Sprintf("ADB version: %s", version), "ℹ️")
+		}
 		return true
 	}
 
-	a.log("ADB is not available or not working properly!", "❌")
-	a.log(fmt.Sprintf("Error: %s", errorMsg), "⚠️")
+	a.log(LogLevelError, "ADB is not available or not working properly!", "❌")
+	a.log(LogLevelError, fmt.Sprintf("Error: %s", errorMsg), "⚠️")
 	return false
 }
 
+// ADBVersion is the version of the installed adb client
+type ADBVersion struct {
+	Major       int
+	Minor       int
+	Patch       int
+	BuildNumber string // Revision, or platform-tools version on newer adb such as 34.0.5-10900879; empty if not reported
+}
+
+// adbVersionPattern extracts the version from the first line of `adb version`, e.g. "Android Debug Bridge version 1.0.41"
+var adbVersionPattern = regexp.MustCompile(`Android Debug Bridge version (\d+)\.(\d+)\.(\d+)`)
+
+// adbBuildPattern extracts the build from the "Revision" line of older adb or the "Version" line of newer adb
+var adbBuildPattern = regexp.MustCompile(`(?m)^(?:Revision|Version) (\S+)`)
+
+// GetADBVersion runs `adb version` and parses the version it reports.
+// For example `adb pair` needs Android Debug Bridge 1.0.41 or later: version.AtLeast(1, 0, 41).
+func (a *AndroidLockScreenDisabler) GetADBVersion(ctx context.Context) (ADBVersion, error) {
+	success, output, errorMsg := a.runADBCommand(ctx, "version", "")
+	if !success {
+		return ADBVersion{}, fmt.Errorf("%w: adb version failed: %s", ErrADBNotAvailable, errorMsg)
+	}
+	return parseADBVersion(output)
+}
+
+// parseADBVersion parses the output of `adb version`
+func parseADBVersion(output string) (ADBVersion, error) {
+	match := adbVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return ADBVersion{}, fmt.Errorf("unrecognized adb version output: %q", output)
+	}
+
+	var version ADBVersion
+	version.Major, _ = strconv.Atoi(match[1])
+	version.Minor, _ = strconv.Atoi(match[2])
+	version.Patch, _ = strconv.Atoi(match[3])
+	if build := adbBuildPattern.FindStringSubmatch(output); build != nil {
+		version.BuildNumber = build[1]
+	}
+	return version, nil
+}
+
+// AtLeast reports whether v is the given version or newer
+func (v ADBVersion) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+// String formats the version like adb does, followed by the build number if known
+func (v ADBVersion) String() string {
+	version := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.BuildNumber != "" {
+		version += " (" + v.BuildNumber + ")"
+	}
+	return version
+}
+
 // GetConnectedDevices gets list of connected Android devices
 func (a *AndroidLockScreenDisabler) GetConnectedDevices() []string {
-	a.log("Scanning for connected Android devices...", "📱")
-	success, output, _ := a.runADBCommand("devices", "")
+	return a.getConnectedDevices(context.Background())
+}
 
-	if !success {
-		a.log("Failed to get device list!", "❌")
-		return []string{}
+// getConnectedDevices implements GetConnectedDevices, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getConnectedDevices(ctx context.Context) []string {
+	return deviceSerials(a.getConnectedDevicesWithStatus(ctx))
+}
+
+// GetConnectedDevicesWithStatus gets the connected Android devices together with their state and transport
+func (a *AndroidLockScreenDisabler) GetConnectedDevicesWithStatus() []DeviceStatus {
+	return a.getConnectedDevicesWithStatus(context.Background())
+}
+
+// getConnectedDevicesWithStatus implements GetConnectedDevicesWithStatus, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getConnectedDevicesWithStatus(ctx context.Context) []DeviceStatus {
+	statuses, ok := a.getAllDeviceStatuses(ctx)
+	if !ok {
+		return []DeviceStatus{}
 	}
+	return a.selectConnectedDevices(ctx, statuses)
+}
 
-	allDevices := make([]string, 0)
-	scanner := bufio.NewScanner(strings.NewReader(output))
-	firstLine := true
+// applyDeviceFilters returns the devices selected by the configured device filters, or none if a filter fails
+func (a *AndroidLockScreenDisabler) applyDeviceFilters(ctx context.Context, devices []DeviceStatus) []DeviceStatus {
+	selected, err := a.FilterDevices(ctx, deviceSerials(devices), a.cfg.deviceFilters...)
+	if err != nil {
+		a.log(LogLevelError, fmt.Sprintf("Device filter failed: %v", err), "❌")
+		return []DeviceStatus{}
+	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if firstLine {
-			firstLine = false
-			continue // Skip the header line
+	keep := make(map[string]bool, len(selected))
+	for _, serial := range selected {
+		keep[serial] = true
+	}
+	filtered := make([]DeviceStatus, 0, len(selected))
+	for _, device := range devices {
+		if keep[device.Serial] {
+			filtered = append(filtered, device)
+		} else {
+			a.log(LogLevelDebug, fmt.Sprintf("Device %s excluded by device filters", a.deviceName(device.Serial)), "🎯", "device", device.Serial)
 		}
+	}
+	return filtered
+}
 
-		if line != "" && strings.Contains(line, "\tdevice") {
-			parts := strings.Split(line, "\t")
-			if len(parts) > 0 {
-				allDevices = append(allDevices, parts[0])
-			}
+// deviceSerials returns the serials of statuses
+func deviceSerials(statuses []DeviceStatus) []string {
+	serials := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		serials = append(serials, status.Serial)
+	}
+	return serials
+}
+
+// GetAllDeviceStatuses gets every device listed by `adb devices`, including offline and unauthorized ones
+func (a *AndroidLockScreenDisabler) GetAllDeviceStatuses() []DeviceStatus {
+	statuses, _ := a.getAllDeviceStatuses(context.Background())
+	return statuses
+}
+
+// getAllDeviceStatuses implements GetAllDeviceStatuses, reporting whether the device list could be read
+func (a *AndroidLockScreenDisabler) getAllDeviceStatuses(ctx context.Context) ([]DeviceStatus, bool) {
+	a.log(LogLevelDebug, "Scanning for connected Android devices...", "📱")
+	success, output, _ := a.runADBCommand(ctx, "devices", "")
+
+	if !success {
+		a.log(LogLevelError, "Failed to get device list!", "❌")
+		return []DeviceStatus{}, false
+	}
+
+	statuses := make([]DeviceStatus, 0)
+	for _, status := range parseDeviceStatuses(output) {
+		if a.checkSerial(status.Serial) == nil {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, true
+}
+
+// selectConnectedDevices returns the devices in the device state, restricted to the target devices if specified
+// and to the devices selected by the device filters
+func (a *AndroidLockScreenDisabler) selectConnectedDevices(ctx context.Context, statuses []DeviceStatus) []DeviceStatus {
+	allDevices := make([]DeviceStatus, 0)
+	for _, status := range statuses {
+		if status.State != DeviceStateDevice {
+			continue
+		}
+		if a.cfg.skipEmulators && status.IsEmulator {
+			a.log(LogLevelDebug, fmt.Sprintf("Skipping emulator %s", a.deviceName(status.Serial)), "⏭️", "device", status.Serial)
+			continue
 		}
+		allDevices = append(allDevices, status)
 	}
 
 	// Filter devices based on target UDIDs if specified
-	var devices []string
-	if len(a.targetDevices) > 0 {
-		a.log(fmt.Sprintf("Filtering devices based on specified UDIDs: %s", strings.Join(a.targetDevices, ", ")), "🎯")
+	var devices []DeviceStatus
+	if len(a.cfg.targetDevices) > 0 {
+		a.log(LogLevelDebug, fmt.Sprintf("Filtering devices based on specified UDIDs: %s", a.deviceNames(a.cfg.targetDevices)), "🎯")
 
-		deviceMap := make(map[string]bool)
+		deviceMap := make(map[string]DeviceStatus)
 		for _, device := range allDevices {
-			deviceMap[device] = true
+			deviceMap[device.Serial] = device
 		}
 
-		for _, targetDevice := range a.targetDevices {
-			if deviceMap[targetDevice] {
-				devices = append(devices, targetDevice)
+		for _, targetDevice := range a.cfg.targetDevices {
+			if device, ok := deviceMap[targetDevice]; ok {
+				devices = append(devices, device)
 			} else {
-				a.log(fmt.Sprintf("Warning: Device %s not found in connected devices", targetDevice), "⚠️")
+				a.log(LogLevelWarn, fmt.Sprintf("Warning: Device %s not found in connected devices", targetDevice), "⚠️")
 			}
 		}
 	} else {
 		devices = allDevices
 	}
 
+	if len(a.cfg.deviceFilters) > 0 && len(devices) > 0 {
+		devices = a.applyDeviceFilters(ctx, devices)
+	}
+
+	serials := deviceSerials(devices)
 	if len(devices) > 0 {
-		a.log(fmt.Sprintf("Found %d device(s) to process: %s", len(devices), strings.Join(devices, ", ")), "🎯")
-		if len(a.targetDevices) > 0 {
-			a.log(fmt.Sprintf("Total connected devices: %d, Processing: %d", len(allDevices), len(devices)), "ℹ️")
+		a.log(LogLevelInfo, fmt.Sprintf("Found %d device(s) to process: %s", len(devices), a.deviceNames(serials)), "🎯")
+		if len(a.cfg.targetDevices) > 0 {
+			a.log(LogLevelDebug, fmt.Sprintf("Total connected devices: %d, Processing: %d", len(allDevices), len(devices)), "ℹ️")
 		}
 	} else {
-		if len(a.targetDevices) > 0 {
-			a.log("None of the specified devices are connected!", "❌")
+		if len(a.cfg.targetDevices) > 0 {
+			a.log(LogLevelError, "None of the specified devices are connected!", "❌")
 		} else {
-			a.log("No connected devices found!", "❌")
+			a.log(LogLevelError, "No connected devices found!", "❌")
 		}
 	}
 
-	a.connectedDevices = devices
+	a.connectedDevices = serials
 	return devices
 }
 
 // GetDeviceInfo gets device information
 func (a *AndroidLockScreenDisabler) GetDeviceInfo(deviceSerial string) DeviceInfo {
+	if a.checkSerial(deviceSerial) != nil {
+		return DeviceInfo{}
+	}
+	return a.getDeviceInfo(context.Background(), deviceSerial)
+}
+
+// GetDeviceInfoContext gets device information like GetDeviceInfo, bounding every ADB command by ctx.
+// It returns ErrInvalidSerial for a malformed serial, ErrDeviceOffline for a device that is not connected
+// and ErrDeviceUnauthorized for a device that has not accepted USB debugging.
+func (a *AndroidLockScreenDisabler) GetDeviceInfoContext(ctx context.Context, deviceSerial string) (DeviceInfo, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return DeviceInfo{}, err
+	}
+
+	success, state, errorMsg := a.runADBCommand(ctx, "get-state", deviceSerial)
+	switch {
+	case ctx.Err() != nil:
+		return DeviceInfo{}, ctx.Err()
+	case !success && strings.Contains(strings.ToLower(errorMsg), DeviceStateUnauthorized):
+		return DeviceInfo{}, fmt.Errorf("%w: %s", ErrDeviceUnauthorized, deviceSerial)
+	case !success:
+		return DeviceInfo{}, commandError(deviceSerial, errorMsg)
+	case state != DeviceStateDevice:
+		return DeviceInfo{}, fmt.Errorf("%w: %s is %s", ErrDeviceOffline, deviceSerial, state)
+	}
+
+	info := a.getDeviceInfo(ctx, deviceSerial)
+	if err := ctx.Err(); err != nil {
+		return DeviceInfo{}, err
+	}
+	return info, nil
+}
+
+// BatchGetDeviceInfo gets the information of several devices concurrently, at most as many at a time as the
+// configured concurrency, keyed by serial. Devices not reached before ctx is done are left out.
+func (a *AndroidLockScreenDisabler) BatchGetDeviceInfo(ctx context.Context, serials []string) map[string]DeviceInfo {
+	infos := make(map[string]DeviceInfo, len(serials))
+	slots := make(chan struct{}, a.cfg.concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, serial := range serials {
+		if a.checkSerial(serial) != nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(serial string) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			info := a.getDeviceInfo(ctx, serial)
+			if ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			infos[serial] = info
+			mu.Unlock()
+		}(serial)
+	}
+
+	wg.Wait()
+	return infos
+}
+
+// getDeviceInfo implements GetDeviceInfo, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getDeviceInfo(ctx context.Context, deviceSerial string) DeviceInfo {
 	info := DeviceInfo{
 		Model:          "Unknown",
 		Manufacturer:   "Unknown",
@@ -134,77 +578,257 @@ func (a *AndroidLockScreenDisabler) GetDeviceInfo(deviceSerial string) DeviceInf
 		APILevel:       "Unknown",
 	}
 
-	// Get device model
-	if success, output, _ := a.runADBCommand("shell getprop ro.product.model", deviceSerial); success && output != "" {
-		info.Model = output
+	// Read the properties, memory, biometric and SIM state in one shell session
+	commands := []string{
+		"getprop ro.product.model",
+		"getprop ro.product.manufacturer",
+		"getprop ro.build.version.release",
+		"getprop ro.build.version.sdk",
+		"cat /proc/meminfo",
 	}
-
-	// Get manufacturer
-	if success, output, _ := a.runADBCommand("shell getprop ro.product.manufacturer", deviceSerial); success && output != "" {
-		info.Manufacturer = output
+	commands = append(commands, biometricCommands...)
+	commands = append(commands, simCommands...)
+	results, _ := a.runShellCommands(ctx, deviceSerial, commands)
+	values := make([]string, 5)
+	for i, result := range results {
+		if i < len(values) && result.ExitCode == 0 {
+			values[i] = result.Output
+		}
 	}
 
-	// Get Android version
-	if success, output, _ := a.runADBCommand("shell getprop ro.build.version.release", deviceSerial); success && output != "" {
-		info.AndroidVersion = output
+	if values[0] != "" {
+		info.Model = values[0]
+	}
+	if values[1] != "" {
+		info.Manufacturer = values[1]
+	}
+	if values[2] != "" {
+		info.AndroidVersion = values[2]
+	}
+	if values[3] != "" {
+		info.APILevel = values[3]
+		if level, err := strconv.Atoi(values[3]); err == nil {
+			info.SDKInt = level
+		}
+	}
+	if values[4] != "" {
+		info.TotalMemoryKB = parseMemTotal(values[4])
+	}
+	if len(results) == len(commands) {
+		info.Biometrics = parseBiometricStatus(results[5], results[6])
+		info.SIMStatus = parseSIMStatus(results[7], results[8])
 	}
 
-	// Get API level
-	if success, output, _ := a.runADBCommand("shell getprop ro.build.version.sdk", deviceSerial); success && output != "" {
-		info.APILevel = output
+	// Get USB topology path (Linux only)
+	if usbPath, err := a.getUSBPath(ctx, deviceSerial); err == nil {
+		info.USBPath = usbPath
 	}
 
 	return info
 }
 
-// RebootDevice reboots the Android device
-func (a *AndroidLockScreenDisabler) RebootDevice(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Rebooting device %s...", deviceSerial), "🔄")
+// parseDeviceStates parses `adb devices` style output into the state of each device keyed by serial
+func parseDeviceStates(output string) map[string]string {
+	states := make(map[string]string)
+	for _, status := range parseDeviceStatuses(output) {
+		states[status.Serial] = status.State
+	}
+	return states
+}
+
+// parseDeviceStatuses parses `adb devices` style output into the state of each device, in listing order
+func parseDeviceStatuses(output string) []DeviceStatus {
+	var statuses []DeviceStatus
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "List of devices") || strings.HasPrefix(line, "*") {
+			continue
+		}
 
-	success, _, errorMsg := a.runADBCommand("reboot", deviceSerial)
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
 
-	if success {
-		a.log(fmt.Sprintf("Reboot command sent to device %s", deviceSerial), "✅")
+		// adb explains missing udev permissions in free text, e.g. "no permissions (user in plugdev group; ...)"
+		state := fields[1]
+		if state == "no" && len(fields) > 2 && fields[2] == "permissions" {
+			state = DeviceStateNoPermissions
+		}
+		statuses = append(statuses, DeviceStatus{Serial: fields[0], State: state, Transport: transportOf(fields[0]), IsEmulator: IsEmulator(fields[0])})
+	}
+	return statuses
+}
+
+// sleepContext pauses for d or until ctx is done, reporting whether the full pause elapsed
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
 		return true
 	}
+}
 
-	a.log(fmt.Sprintf("Failed to reboot device %s: %s", deviceSerial, errorMsg), "❌")
-	return false
+// parseMemTotal extracts the MemTotal value in kB from /proc/meminfo output
+func parseMemTotal(meminfo string) int64 {
+	scanner := bufio.NewScanner(strings.NewReader(meminfo))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			if value, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				return value
+			}
+		}
+	}
+	return 0
 }
 
-// WaitForDeviceReady waits for device to be ready after reboot
-func (a *AndroidLockScreenDisabler) WaitForDeviceReady(deviceSerial string, maxWaitMinutes int) bool {
-	a.log(fmt.Sprintf("Waiting for device %s to be ready after reboot...", deviceSerial), "⏳")
+// RebootDevice reboots the Android device, returning an error wrapping ErrRebootFailed if it cannot
+func (a *AndroidLockScreenDisabler) RebootDevice(deviceSerial string) error {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return err
+	}
+	return a.rebootDevice(context.Background(), deviceSerial)
+}
+
+// rebootDevice implements RebootDevice, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) rebootDevice(ctx context.Context, deviceSerial string) error {
+	a.log(LogLevelDebug, fmt.Sprintf("Rebooting device %s...", a.deviceName(deviceSerial)), "🔄", "device", deviceSerial)
 
-	maxAttempts := maxWaitMinutes * 12 // Check every 5 seconds
+	success, _, errorMsg := a.runADBCommand(ctx, "reboot", deviceSerial)
+
+	if success {
+		a.log(LogLevelDebug, fmt.Sprintf("Reboot command sent to device %s", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		return nil
+	}
+
+	a.log(LogLevelWarn, fmt.Sprintf("Failed to reboot device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	return fmt.Errorf("%w: %s: %s", ErrRebootFailed, deviceSerial, errorMsg)
+}
+
+// WaitForDeviceReady waits for device to be ready after reboot, returning an error wrapping ErrDeviceNotReady
+// if it is not ready within maxWaitMinutes
+func (a *AndroidLockScreenDisabler) WaitForDeviceReady(deviceSerial string, maxWaitMinutes int) error {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return err
+	}
+	return a.waitForDeviceReady(context.Background(), deviceSerial, time.Duration(maxWaitMinutes)*time.Minute)
+}
+
+// rebootProgressInterval is how often waitForDeviceReady reports that it is still waiting
+const rebootProgressInterval = 30 * time.Second
+
+// waitForDeviceReady waits up to maxWait for device to be ready after reboot, giving up with ctx.Err() once ctx is done
+func (a *AndroidLockScreenDisabler) waitForDeviceReady(ctx context.Context, deviceSerial string, maxWait time.Duration) error {
+	a.log(LogLevelDebug, fmt.Sprintf("Waiting for device %s to be ready after reboot...", a.deviceName(deviceSerial)), "⏳", "device", deviceSerial)
+	deadline := time.Now().Add(maxWait)
+
+	// Block until the device is back instead of sleeping between polls; the polls below then succeed right away
+	waitCtx, cancel := context.WithDeadline(ctx, deadline)
+	connected, supported := a.waitForDevice(waitCtx, deviceSerial)
+	cancel()
+	if supported && !connected {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		a.log(LogLevelError, fmt.Sprintf("Timeout waiting for device %s to be ready after %s",
+			a.deviceName(deviceSerial), maxWait), "⏰", "device", deviceSerial)
+		return fmt.Errorf("%w: %s not connected within %s", ErrDeviceNotReady, deviceSerial, maxWait)
+	}
+
+	poll := a.cfg.rebootPollInterval
+	remaining := time.Until(deadline)
+	maxAttempts := int((remaining + poll - 1) / poll) // Check every poll interval
+	progressEvery := int(rebootProgressInterval / poll)
+	if progressEvery < 1 {
+		progressEvery = 1
+	}
 	attempt := 0
 
 	for attempt < maxAttempts {
 		// First check if device appears in device list
-		success, _, _ := a.runADBCommand("get-state", deviceSerial)
+		success, _, _ := a.runADBCommand(ctx, "get-state", deviceSerial)
 		if success {
-			// Wait a bit more for system to fully boot
-			a.log(fmt.Sprintf("Device %s detected, waiting for system to fully boot...", deviceSerial), "⏱️")
-			time.Sleep(10 * time.Second)
+			a.log(LogLevelDebug, fmt.Sprintf("Device %s detected, waiting for system to fully boot...", a.deviceName(deviceSerial)), "⏱️", "device", deviceSerial)
 
 			// Test if we can execute shell commands
-			success, _, _ := a.runADBCommand("shell echo 'test'", deviceSerial)
+			success, _, _ := a.runADBCommand(ctx, "shell echo 'test'", deviceSerial)
 			if success {
-				a.log(fmt.Sprintf("Device %s is ready!", deviceSerial), "✅")
-				return true
+				// The shell answers before the keyguard is up, so wait for the boot to complete
+				if !a.waitForBootCompleted(ctx, deviceSerial) && ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				// Wait a bit more for the lock screen framework to settle
+				if !sleepContext(ctx, a.cfg.rebootSettleTime) {
+					return ctx.Err()
+				}
+
+				a.log(LogLevelDebug, fmt.Sprintf("Device %s is ready!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+				return nil
 			}
 		}
 
 		attempt++
-		if attempt%6 == 0 { // Log every 30 seconds
-			minutesWaited := attempt / 12
-			a.log(fmt.Sprintf("Still waiting for device %s... (%d/%d minutes)",
-				deviceSerial, minutesWaited, maxWaitMinutes), "⌛")
+		if attempt%progressEvery == 0 {
+			a.log(LogLevelDebug, fmt.Sprintf("Still waiting for device %s... (%s/%s)",
+				a.deviceName(deviceSerial), time.Duration(attempt)*poll, maxWait), "⌛", "device", deviceSerial)
+		}
+		if !sleepContext(ctx, poll) {
+			return ctx.Err()
 		}
-		time.Sleep(5 * time.Second)
 	}
 
-	a.log(fmt.Sprintf("Timeout waiting for device %s to be ready after %d minutes",
-		deviceSerial, maxWaitMinutes), "⏰")
-	return false
+	a.log(LogLevelError, fmt.Sprintf("Timeout waiting for device %s to be ready after %s",
+		a.deviceName(deviceSerial), maxWait), "⏰", "device", deviceSerial)
+	return fmt.Errorf("%w: %s not ready within %s", ErrDeviceNotReady, deviceSerial, maxWait)
+}
+
+// waitForDevice runs `adb wait-for-device`, which returns as soon as the device is connected again.
+// It reports whether the device connected before ctx was done, and whether the installed adb supports the command.
+func (a *AndroidLockScreenDisabler) waitForDevice(ctx context.Context, deviceSerial string) (connected, supported bool) {
+	// Bound the wait by ctx rather than the per-command timeout, which is far shorter than a reboot
+	timeout := a.cfg.rebootWaitTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	success, _, errorMsg := a.runADBCommandWithTimeout(ctx, "wait-for-device", deviceSerial, timeout)
+	switch {
+	case success:
+		return true, true
+	case ctx.Err() != nil:
+		return false, true
+	default:
+		a.log(LogLevelDebug, fmt.Sprintf("adb wait-for-device failed (%s), polling device %s instead",
+			errorMsg, a.deviceName(deviceSerial)), "ℹ️", "device", deviceSerial)
+		return false, false
+	}
+}
+
+// bootCompletedPollInterval is how often sys.boot_completed is read while waiting for the boot to complete
+const bootCompletedPollInterval = 2 * time.Second
+
+// waitForBootCompleted polls sys.boot_completed until it is 1, giving up after the configured boot timeout or once ctx is done
+func (a *AndroidLockScreenDisabler) waitForBootCompleted(ctx context.Context, deviceSerial string) bool {
+	deadline := time.Now().Add(a.cfg.bootTimeout)
+	for {
+		success, output, _ := a.runADBCommand(ctx, "shell getprop sys.boot_completed", deviceSerial)
+		if success && output == "1" {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			a.log(LogLevelWarn, fmt.Sprintf("Device %s did not report sys.boot_completed=1 within %s, continuing anyway",
+				a.deviceName(deviceSerial), a.cfg.bootTimeout), "⚠️", "device", deviceSerial)
+			return false
+		}
+		if !sleepContext(ctx, bootCompletedPollInterval) {
+			return false
+		}
+	}
 }
@@ -0,0 +1,266 @@
+package dlock
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockADBExecutor(t *testing.T) {
+	mock := NewMockADBExecutor(map[string]MockResponse{
+		"shell":                   ok("generic"),
+		"shell getprop":           ok("specific"),
+		"shell getprop ro.serial": {ExitCode: 2, Stdout: "denied"},
+	})
+
+	tests := []struct {
+		args         []string
+		wantExitCode int
+		wantStdout   string
+	}{
+		{[]string{"-s", testSerial, "shell getprop ro.product.model"}, 0, "specific"},
+		{[]string{"-s", testSerial, "shell getprop ro.serialno"}, 2, "denied"},
+		{[]string{"-s", testSerial, "shell dumpsys trust"}, 0, "generic"},
+		{[]string{"devices"}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		exitCode, stdout, err := mock.Execute(context.Background(), tt.args)
+		if err != nil || exitCode != tt.wantExitCode || string(stdout) != tt.wantStdout {
+			t.Errorf("Execute(%v) = %d, %q, %v, want %d, %q, nil", tt.args, exitCode, stdout, err, tt.wantExitCode, tt.wantStdout)
+		}
+	}
+
+	if calls := mock.Calls(); len(calls) != len(tests) || calls[3] != "devices" {
+		t.Errorf("Calls() = %v, want the %d executed commands", calls, len(tests))
+	}
+}
+
+func TestRunADBCommandRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    MockResponse
+		recoverOn   int // Retry after which the command starts succeeding, never when 0
+		wantSuccess bool
+		wantCalls   int
+	}{
+		{
+			name:        "retryable failure exhausts retries",
+			response:    MockResponse{ExitCode: 1, Stdout: "error: closed"},
+			wantSuccess: false,
+			wantCalls:   3,
+		},
+		{
+			name:        "retryable failure recovers",
+			response:    MockResponse{ExitCode: 1, Stdout: "error: closed"},
+			recoverOn:   1,
+			wantSuccess: true,
+			wantCalls:   2,
+		},
+		{
+			name:        "device not found is retried",
+			response:    MockResponse{ExitCode: 1, Stdout: "error: device 'TEST123' not found"},
+			recoverOn:   1,
+			wantSuccess: true,
+			wantCalls:   2,
+		},
+		{
+			name:        "shell command not found",
+			response:    MockResponse{ExitCode: 127, Stdout: "/system/bin/sh: locksettings: not found"},
+			wantSuccess: false,
+			wantCalls:   1,
+		},
+		{
+			name:        "non-retryable failure",
+			response:    MockResponse{ExitCode: 1, Stdout: "error: device unauthorized"},
+			wantSuccess: false,
+			wantCalls:   1,
+		},
+		{
+			name:        "success",
+			response:    ok("value"),
+			wantSuccess: true,
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell getprop": tt.response}, WithMaxRetries(2))
+			retries := 0
+			a.cfg.sleep = func(ctx context.Context, _ time.Duration) bool {
+				retries++
+				if retries == tt.recoverOn {
+					mock.SetResponse("shell getprop", ok("value"))
+				}
+				return true
+			}
+
+			success, _, _ := a.runADBCommand(context.Background(), "shell getprop ro.build.version.sdk", testSerial)
+			if success != tt.wantSuccess {
+				t.Errorf("runADBCommand() success = %v, want %v", success, tt.wantSuccess)
+			}
+			if calls := len(mock.Calls()); calls != tt.wantCalls {
+				t.Errorf("runADBCommand() ran adb %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunADBCommandRetryCancelled(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{"shell getprop": {ExitCode: 1}}, WithMaxRetries(5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if success, _, _ := a.runADBCommand(ctx, "shell getprop ro.build.version.sdk", testSerial); success {
+		t.Error("runADBCommand() succeeded, want failure")
+	}
+	if calls := len(mock.Calls()); calls != 1 {
+		t.Errorf("runADBCommand() ran adb %d times after cancellation, want 1", calls)
+	}
+}
+
+func TestRunADBCommandDryRun(t *testing.T) {
+	tests := []struct {
+		command  string
+		wantCall bool
+	}{
+		{"shell settings put secure lockscreen.disabled 1", false},
+		{"shell locksettings clear --old 1234", false},
+		{"shell locksettings --user 10 clear", false},
+		{"shell locksettings --user 10 set-disabled true", false},
+		{"shell locksettings --old 1234 --user 10 set-pin 5678", false},
+		{`shell "su -c 'locksettings set-disabled true'"`, false},
+		{"shell locksettings --user 10 get-disabled", true},
+		{"reboot", false},
+		{"shell date @1700000000", false},
+		{"shell input swipe 540 2160 540 240", false},
+		{"shell 'echo com.android.providers.settings > /dev/cpuset/foreground/tasks 2>/dev/null'", false},
+		{"shell date +%s%N", true},
+		{"shell settings get secure lockscreen.disabled", true},
+		{"shell dumpsys trust", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell": ok("")}, WithDryRun(true))
+
+			success, _, errorMsg := a.runADBCommand(context.Background(), tt.command, testSerial)
+			if !success {
+				t.Errorf("runADBCommand() failed: %s", errorMsg)
+			}
+			if called := len(mock.Calls()) > 0; called != tt.wantCall {
+				t.Errorf("runADBCommand() ran adb = %v, want %v", called, tt.wantCall)
+			}
+		})
+	}
+}
+
+func TestRunADBCommandInvalidSerial(t *testing.T) {
+	for _, serial := range []string{"DEV; reboot", "DEV$(id)", "DEV SERIAL", "DEV|sh"} {
+		t.Run(serial, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell": ok("")})
+
+			success, _, errorMsg := a.runADBCommand(context.Background(), "shell dumpsys trust", serial)
+			if success || !strings.Contains(errorMsg, ErrInvalidSerial.Error()) {
+				t.Errorf("runADBCommand() = %v, %q, want an invalid serial error", success, errorMsg)
+			}
+			if calls := mock.Calls(); len(calls) != 0 {
+				t.Errorf("runADBCommand() ran %v, want no adb invocation", calls)
+			}
+		})
+	}
+}
+
+func TestRunPostUnlockStepsDryRun(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{"shell": ok("")}, WithDryRun(true), WithSyncTime(true),
+		WithDisableAnimations(true), WithPostUnlockCommands("shell input keyevent KEYCODE_HOME"))
+
+	result := DeviceResult{Serial: testSerial}
+	a.runPostUnlockSteps(context.Background(), testSerial, "["+testSerial+"]", &result)
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("runPostUnlockSteps() ran %v in dry-run mode, want nothing", calls)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("runPostUnlockSteps() warnings = %v, want none", result.Warnings)
+	}
+}
+
+// funcExecutor adapts a function to ADBExecutor
+type funcExecutor func(ctx context.Context, args []string) (int, []byte, error)
+
+func (f funcExecutor) Execute(ctx context.Context, args []string) (int, []byte, error) {
+	return f(ctx, args)
+}
+
+func TestWaitForDevice(t *testing.T) {
+	tests := []struct {
+		name          string
+		executor      funcExecutor
+		wantConnected bool
+		wantSupported bool
+	}{
+		{
+			name: "connected after the command timeout",
+			executor: func(ctx context.Context, args []string) (int, []byte, error) {
+				select {
+				case <-time.After(50 * time.Millisecond):
+					return 0, nil, nil
+				case <-ctx.Done():
+					return -1, nil, ctx.Err()
+				}
+			},
+			wantConnected: true,
+			wantSupported: true,
+		},
+		{
+			name: "not connected before the deadline",
+			executor: func(ctx context.Context, args []string) (int, []byte, error) {
+				<-ctx.Done()
+				return -1, nil, ctx.Err()
+			},
+			wantConnected: false,
+			wantSupported: true,
+		},
+		{
+			name: "unsupported",
+			executor: func(ctx context.Context, args []string) (int, []byte, error) {
+				return 1, []byte("unknown command wait-for-device"), nil
+			},
+			wantConnected: false,
+			wantSupported: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var audit bytes.Buffer
+			a, _ := newMockDisabler(t, nil, WithADBExecutor(tt.executor), WithCommandTimeout(10*time.Millisecond), WithAuditLog(&audit))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			connected, supported := a.waitForDevice(ctx, testSerial)
+			if connected != tt.wantConnected || supported != tt.wantSupported {
+				t.Errorf("waitForDevice() = %v, %v, want %v, %v", connected, supported, tt.wantConnected, tt.wantSupported)
+			}
+			if !strings.Contains(audit.String(), "wait-for-device") {
+				t.Errorf("waitForDevice() was not audited: %q", audit.String())
+			}
+		})
+	}
+}
+
+func TestWaitForDeviceInvalidSerial(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{"wait-for-device": ok("")})
+
+	if connected, supported := a.waitForDevice(context.Background(), "DEV; reboot"); connected || supported {
+		t.Errorf("waitForDevice() = %v, %v, want false, false", connected, supported)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("waitForDevice() ran %v, want no adb invocation", calls)
+	}
+}
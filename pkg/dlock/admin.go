@@ -0,0 +1,178 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Patterns locating device admin components in `dpm list-owners` and `dumpsys device_policy` output
+var (
+	componentInfoPattern = regexp.MustCompile(`ComponentInfo\{([\w.]+/[\w.$]+)\}`)
+	adminFieldPattern    = regexp.MustCompile(`admin=([\w.]+/[\w.$]+)`)
+	adminEntryPattern    = regexp.MustCompile(`^\s+([\w.]+/[\w.$]+):\s*$`)
+)
+
+// adminComponentPattern matches a whole device admin component as found by the patterns above
+var adminComponentPattern = regexp.MustCompile(`^[\w.]+/[\w.$]+$`)
+
+// GetDeviceAdminApps returns the active device admin components (package/receiver) on the device
+func (a *AndroidLockScreenDisabler) GetDeviceAdminApps(deviceSerial string) ([]string, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return nil, err
+	}
+	return a.getDeviceAdminApps(context.Background(), deviceSerial)
+}
+
+// getDeviceAdminApps implements GetDeviceAdminApps, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getDeviceAdminApps(ctx context.Context, deviceSerial string) ([]string, error) {
+	ownersOK, owners, _ := a.runADBCommand(ctx, "shell dpm list-owners", deviceSerial)
+	policyOK, policy, errorMsg := a.runADBCommand(ctx, "shell dumpsys device_policy", deviceSerial)
+	if !ownersOK && !policyOK {
+		return nil, fmt.Errorf("failed to read device policy on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	seen := make(map[string]bool)
+	var admins []string
+	add := func(component string) {
+		if !seen[component] {
+			seen[component] = true
+			admins = append(admins, component)
+		}
+	}
+
+	for _, match := range adminFieldPattern.FindAllStringSubmatch(owners, -1) {
+		add(match[1])
+	}
+	for _, match := range componentInfoPattern.FindAllStringSubmatch(policy, -1) {
+		add(match[1])
+	}
+
+	// Enabled admins are listed one per line under their section header
+	inAdminSection := false
+	for _, line := range strings.Split(policy, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "Enabled Device Admins") {
+			inAdminSection = true
+			continue
+		}
+		if !inAdminSection {
+			continue
+		}
+		if match := adminEntryPattern.FindStringSubmatch(line); match != nil {
+			add(match[1])
+		} else if trimmed != "" && !strings.HasPrefix(line, "   ") {
+			inAdminSection = false
+		}
+	}
+
+	return admins, nil
+}
+
+// RemoveDeviceAdmin deactivates a device admin component as returned by GetDeviceAdminApps.
+// The shell user can only remove admins of test-only apps; other admins must be removed on the device.
+func (a *AndroidLockScreenDisabler) RemoveDeviceAdmin(deviceSerial, admin string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.removeDeviceAdmin(context.Background(), deviceSerial, admin)
+}
+
+// removeDeviceAdmin implements RemoveDeviceAdmin, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) removeDeviceAdmin(ctx context.Context, deviceSerial, admin string) bool {
+	// The component ends up in a shell command, and inner classes put a $ in it
+	if !adminComponentPattern.MatchString(admin) {
+		a.log(LogLevelWarn, fmt.Sprintf("Rejected device admin component %q on device %s", admin, a.deviceName(deviceSerial)), "🚫", "device", deviceSerial)
+		return false
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("Removing device admin %s on device %s...", admin, a.deviceName(deviceSerial)), "🛡️", "device", deviceSerial)
+
+	success, output, errorMsg := a.runADBCommand(ctx, "shell dpm remove-active-admin "+shellArg(admin), deviceSerial)
+	if success && !strings.Contains(strings.ToLower(output), "exception") {
+		a.log(LogLevelInfo, fmt.Sprintf("Removed device admin %s on device %s", admin, a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		return true
+	}
+
+	if errorMsg == "" {
+		errorMsg = output
+	}
+	a.log(LogLevelWarn, fmt.Sprintf("Failed to remove device admin %s on device %s: %s", admin, a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	return false
+}
+
+// MDMInfo describes the device and profile owners installed by an MDM/EMM solution
+type MDMInfo struct {
+	DeviceOwnerPackage   string
+	ProfileOwnerPackage  string
+	IsWorkProfilePresent bool // A profile owner manages a secondary user such as a work profile
+}
+
+// ownerLinePattern matches `dpm list-owners` lines such as "User  0: admin=com.mdm/.Receiver,DeviceOwner"
+var ownerLinePattern = regexp.MustCompile(`User\s+(\d+):\s*admin=([\w.]+)/[\w.$]+((?:,\w+)*)`)
+
+// DetectMDM reports whether the device is managed by an MDM through a device or profile owner
+func (a *AndroidLockScreenDisabler) DetectMDM(deviceSerial string) (bool, MDMInfo, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return false, MDMInfo{}, err
+	}
+	return a.detectMDM(context.Background(), deviceSerial)
+}
+
+// detectMDM implements DetectMDM, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) detectMDM(ctx context.Context, deviceSerial string) (bool, MDMInfo, error) {
+	var info MDMInfo
+
+	ownersOK, owners, _ := a.runADBCommand(ctx, "shell dpm list-owners", deviceSerial)
+	if ownersOK {
+		for _, match := range ownerLinePattern.FindAllStringSubmatch(owners, -1) {
+			user, pkg, roles := match[1], match[2], match[3]
+			switch {
+			case strings.Contains(roles, "DeviceOwner"):
+				info.DeviceOwnerPackage = pkg
+			case strings.Contains(roles, "ProfileOwner"):
+				info.ProfileOwnerPackage = pkg
+				info.IsWorkProfilePresent = info.IsWorkProfilePresent || user != "0"
+			}
+		}
+	}
+
+	// Older releases lack list-owners, so fall back to the owner sections of the policy dump
+	policyOK, policy, errorMsg := a.runADBCommand(ctx, "shell dumpsys device_policy", deviceSerial)
+	if policyOK {
+		section := ""
+		for _, line := range strings.Split(policy, "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(trimmed, "Device Owner"):
+				section = "device"
+				continue
+			case strings.HasPrefix(trimmed, "Profile Owner"):
+				section = "profile"
+				info.IsWorkProfilePresent = info.IsWorkProfilePresent || !strings.Contains(trimmed, "(User 0)")
+				continue
+			}
+
+			match := componentInfoPattern.FindStringSubmatch(trimmed)
+			if section == "" || match == nil {
+				continue
+			}
+			pkg := strings.SplitN(match[1], "/", 2)[0]
+			if section == "device" && info.DeviceOwnerPackage == "" {
+				info.DeviceOwnerPackage = pkg
+			}
+			if section == "profile" && info.ProfileOwnerPackage == "" {
+				info.ProfileOwnerPackage = pkg
+			}
+			section = ""
+		}
+	}
+
+	if !ownersOK && !policyOK {
+		return false, info, fmt.Errorf("failed to read device owners on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	managed := info.DeviceOwnerPackage != "" || info.ProfileOwnerPackage != ""
+	return managed, info, nil
+}
@@ -0,0 +1,39 @@
+package dlock
+
+import "testing"
+
+func TestRemoveDeviceAdmin(t *testing.T) {
+	tests := []struct {
+		name        string
+		admin       string
+		wantSuccess bool
+		wantCommand string
+	}{
+		{"component", "com.example.mdm/.AdminReceiver", true, "shell dpm remove-active-admin " + shellArg("com.example.mdm/.AdminReceiver")},
+		{"inner class", "com.example/.Admin$Receiver", true, "shell dpm remove-active-admin " + shellArg("com.example/.Admin$Receiver")},
+		{"command injection", "com.example/.Admin; reboot", false, ""},
+		{"command substitution", "com.example/.$(reboot)", false, ""},
+		{"missing receiver", "com.example", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell dpm remove-active-admin": ok("Success: Admin removed")})
+
+			if success := a.RemoveDeviceAdmin(testSerial, tt.admin); success != tt.wantSuccess {
+				t.Errorf("RemoveDeviceAdmin(%q) = %v, want %v", tt.admin, success, tt.wantSuccess)
+			}
+
+			calls := mock.Calls()
+			if tt.wantCommand == "" {
+				if len(calls) != 0 {
+					t.Errorf("RemoveDeviceAdmin(%q) ran %v, want no adb invocation", tt.admin, calls)
+				}
+				return
+			}
+			if want := "-s " + testSerial + " " + tt.wantCommand; len(calls) != 1 || calls[0] != want {
+				t.Errorf("RemoveDeviceAdmin(%q) ran %v, want [%s]", tt.admin, calls, want)
+			}
+		})
+	}
+}
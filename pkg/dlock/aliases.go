@@ -0,0 +1,47 @@
+package dlock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DeviceAlias maps a device serial to a human-readable name such as "Rack Slot 3 - Samsung S21"
+type DeviceAlias map[string]string
+
+// Label returns "<alias> (<serial>)" when the serial has an alias, or the serial itself otherwise
+func (m DeviceAlias) Label(serial string) string {
+	if alias := m[serial]; alias != "" {
+		return fmt.Sprintf("%s (%s)", alias, serial)
+	}
+	return serial
+}
+
+// LoadAliasFile reads a JSON object mapping device serials to aliases
+func LoadAliasFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias file: %w", err)
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse alias file %s: %w", path, err)
+	}
+	return aliases, nil
+}
+
+// deviceName returns the name of a device as shown in log messages
+func (a *AndroidLockScreenDisabler) deviceName(serial string) string {
+	return a.cfg.deviceAliases.Label(serial)
+}
+
+// deviceNames returns a comma-separated list of device names for log messages
+func (a *AndroidLockScreenDisabler) deviceNames(serials []string) string {
+	names := make([]string, len(serials))
+	for i, serial := range serials {
+		names[i] = a.deviceName(serial)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,47 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+)
+
+// animationSettings are the global settings scaling UI animations, which UI test frameworks such as Espresso
+// expect to be off
+var animationSettings = [][2]string{
+	{"global", "window_animation_scale"},
+	{"global", "transition_animation_scale"},
+	{"global", "animator_duration_scale"},
+}
+
+// DisableAnimations sets every animation scale to 0 to speed up UI automation and reports whether all were set
+func (a *AndroidLockScreenDisabler) DisableAnimations(serial string) bool {
+	if a.checkSerial(serial) != nil {
+		return false
+	}
+	return a.setAnimationScale(context.Background(), serial, "0")
+}
+
+// EnableAnimations sets every animation scale back to 1 and reports whether all were set
+func (a *AndroidLockScreenDisabler) EnableAnimations(serial string) bool {
+	if a.checkSerial(serial) != nil {
+		return false
+	}
+	return a.setAnimationScale(context.Background(), serial, "1")
+}
+
+// setAnimationScale writes scale to every animation scale setting, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) setAnimationScale(ctx context.Context, serial, scale string) bool {
+	allSet := true
+	for _, setting := range animationSettings {
+		command := fmt.Sprintf("shell settings put %s %s %s", setting[0], setting[1], scale)
+		if success, _, errorMsg := a.runADBCommand(ctx, command, serial); !success {
+			a.log(LogLevelDebug, fmt.Sprintf("Could not set %s on device %s: %s", setting[1], a.deviceName(serial), errorMsg), "❌", "device", serial)
+			allSet = false
+		}
+	}
+
+	if allSet {
+		a.log(LogLevelDebug, fmt.Sprintf("Animation scales set to %s on device %s", scale, a.deviceName(serial)), "🎬", "device", serial)
+	}
+	return allSet
+}
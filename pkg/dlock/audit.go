@@ -0,0 +1,89 @@
+package dlock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// auditOutputLimit is the number of output bytes kept in each audit log entry
+const auditOutputLimit = 4096
+
+// auditCredentialPattern matches the credential passed to locksettings, e.g. "set-pin 1234" or "--old 1234"
+var auditCredentialPattern = regexp.MustCompile(`((?:set-pin|set-password|set-pattern|--old)\s+)('[^']*'|"[^"]*"|\S+)`)
+
+// auditEchoedCredentialPattern matches the credential locksettings echoes back, e.g. "Pin set to '1234'"
+var auditEchoedCredentialPattern = regexp.MustCompile(`((?i:pin|password|pattern) set to )'[^']*'`)
+
+// redactCredentials hides the lock screen credentials in a command line or its output
+func redactCredentials(text string) string {
+	text = auditCredentialPattern.ReplaceAllString(text, "${1}[REDACTED]")
+	return auditEchoedCredentialPattern.ReplaceAllString(text, "${1}[REDACTED]")
+}
+
+// auditEntry is one newline-delimited JSON record of the audit log
+type auditEntry struct {
+	Timestamp  string `json:"ts"`
+	Device     string `json:"device,omitempty"`
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"`
+	Output     string `json:"output"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// audit records an executed ADB command in the audit log, if one is configured
+func (a *AndroidLockScreenDisabler) audit(args []string, exitCode int, output []byte, err error, elapsed time.Duration) {
+	if a.cfg.auditLog == nil {
+		return
+	}
+
+	entry := auditEntry{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339Nano),
+		Command:    redactCredentials("adb " + strings.Join(args, " ")),
+		ExitCode:   exitCode,
+		Output:     redactCredentials(string(output)),
+		DurationMS: elapsed.Milliseconds(),
+	}
+	if len(args) > 1 && args[0] == "-s" {
+		entry.Device = args[1]
+	}
+	if len(output) > auditOutputLimit {
+		entry.Output = fmt.Sprintf("%s... [truncated %d bytes]", redactCredentials(string(output[:auditOutputLimit])), len(output)-auditOutputLimit)
+		entry.Truncated = true
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return
+	}
+
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+	a.cfg.auditLog.Write(append(line, '\n'))
+}
+
+// appendFileWriter appends every write to a file, opening and closing it each time
+type appendFileWriter struct {
+	path string
+}
+
+// Write implements io.Writer
+func (w appendFileWriter) Write(p []byte) (int, error) {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	n, err := file.Write(p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
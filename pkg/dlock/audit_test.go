@@ -0,0 +1,43 @@
+package dlock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAuditRedactsCredentials(t *testing.T) {
+	var audit bytes.Buffer
+	a, _ := newMockDisabler(t, map[string]MockResponse{
+		"shell locksettings": ok("Pin set to '482913'"),
+	}, WithAuditLog(&audit))
+
+	for _, command := range []string{
+		"shell locksettings set-pin 482913",
+		"shell locksettings set-password 'hunter two'",
+		"shell locksettings --old 482913 set-pattern 1235789",
+	} {
+		a.runADBCommand(context.Background(), command, testSerial)
+	}
+
+	if strings.Contains(audit.String(), "482913") || strings.Contains(audit.String(), "hunter") || strings.Contains(audit.String(), "1235789") {
+		t.Errorf("audit log contains a credential:\n%s", audit.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("audit log has %d entries, want 3", len(lines))
+	}
+	var entry auditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid audit entry %q: %v", lines[0], err)
+	}
+	if want := "adb -s " + testSerial + " shell locksettings set-pin [REDACTED]"; entry.Command != want {
+		t.Errorf("audit command = %q, want %q", entry.Command, want)
+	}
+	if want := "Pin set to [REDACTED]"; entry.Output != want {
+		t.Errorf("audit output = %q, want %q", entry.Output, want)
+	}
+}
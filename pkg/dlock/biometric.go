@@ -0,0 +1,71 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BiometricStatus describes the biometric sensors of a device and whether any biometric data is enrolled
+type BiometricStatus struct {
+	HasFingerprintSensor bool
+	HasFaceSensor        bool
+	FingerprintsEnrolled bool
+	FacesEnrolled        bool
+}
+
+// HasEnrollment reports whether a fingerprint or a face is enrolled
+func (s BiometricStatus) HasEnrollment() bool {
+	return s.FingerprintsEnrolled || s.FacesEnrolled
+}
+
+// biometricCommands are the dumpsys services read for a BiometricStatus, fingerprint first
+var biometricCommands = []string{"dumpsys fingerprint", "dumpsys face"}
+
+// biometricCountPattern matches the enrollment count of a user, either in the JSON dump of older
+// releases ("count":2) or in the text dump of newer ones (enrolled: 2, Enrollments: 2)
+var biometricCountPattern = regexp.MustCompile(`(?i)(?:"count"|enrolled|enrollments)\s*[:=]\s*(\d+)`)
+
+// GetBiometricStatus reports which biometric sensors a device has and whether fingerprints or faces are enrolled
+func (a *AndroidLockScreenDisabler) GetBiometricStatus(serial string) (BiometricStatus, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return BiometricStatus{}, err
+	}
+	return a.getBiometricStatus(context.Background(), serial)
+}
+
+// getBiometricStatus implements GetBiometricStatus, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getBiometricStatus(ctx context.Context, serial string) (BiometricStatus, error) {
+	results, err := a.runShellCommands(ctx, serial, biometricCommands)
+	if err != nil {
+		return BiometricStatus{}, err
+	}
+	if results[0].ExitCode != 0 && results[1].ExitCode != 0 {
+		return BiometricStatus{}, fmt.Errorf("failed to read biometric state on device %s: %s", serial, results[0].Output)
+	}
+	return parseBiometricStatus(results[0], results[1]), nil
+}
+
+// parseBiometricStatus builds a BiometricStatus from the output of `dumpsys fingerprint` and `dumpsys face`
+func parseBiometricStatus(fingerprint, face CommandResult) BiometricStatus {
+	var status BiometricStatus
+	status.HasFingerprintSensor, status.FingerprintsEnrolled = parseBiometricDump(fingerprint)
+	status.HasFaceSensor, status.FacesEnrolled = parseBiometricDump(face)
+	return status
+}
+
+// parseBiometricDump reports whether a biometric service exists and whether any user enrolled data in it
+func parseBiometricDump(result CommandResult) (bool, bool) {
+	if result.ExitCode != 0 || result.Output == "" || strings.Contains(result.Output, "Can't find service") {
+		return false, false
+	}
+
+	for _, match := range biometricCountPattern.FindAllStringSubmatch(result.Output, -1) {
+		if count, err := strconv.Atoi(match[1]); err == nil && count > 0 {
+			return true, true
+		}
+	}
+	return true, false
+}
@@ -0,0 +1,67 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetDeviceTime reads the current device clock
+func (a *AndroidLockScreenDisabler) GetDeviceTime(deviceSerial string) (time.Time, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return time.Time{}, err
+	}
+
+	success, output, errorMsg := a.runADBCommand(context.Background(), "shell date +%s%N", deviceSerial)
+	if !success {
+		return time.Time{}, fmt.Errorf("failed to read time on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	// Older toolbox implementations don't support %N, so only keep the leading digits
+	digits := strings.TrimSpace(output)
+	for i, r := range digits {
+		if r < '0' || r > '9' {
+			digits = digits[:i]
+			break
+		}
+	}
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected date output on device %s: %q", deviceSerial, output)
+	}
+
+	// Seconds since epoch fit in 10 digits until the year 2286
+	if len(digits) <= 10 {
+		return time.Unix(value, 0), nil
+	}
+	return time.Unix(0, value), nil
+}
+
+// SyncDeviceTime sets the device clock to t (requires root or equivalent permission)
+func (a *AndroidLockScreenDisabler) SyncDeviceTime(deviceSerial string, t time.Time) error {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return err
+	}
+	return a.syncDeviceTime(context.Background(), deviceSerial, t)
+}
+
+// syncDeviceTime implements SyncDeviceTime, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) syncDeviceTime(ctx context.Context, deviceSerial string, t time.Time) error {
+	a.log(LogLevelDebug, fmt.Sprintf("Synchronising clock on device %s...", a.deviceName(deviceSerial)), "🕒", "device", deviceSerial)
+
+	success, output, errorMsg := a.runADBCommand(ctx, fmt.Sprintf("shell date @%d", t.Unix()), deviceSerial)
+	if !success {
+		return fmt.Errorf("failed to set time on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	// date reports permission problems on stdout while still exiting successfully on some builds
+	if strings.Contains(strings.ToLower(output), "not permitted") {
+		return fmt.Errorf("failed to set time on device %s: %s", deviceSerial, output)
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("Clock synchronised on device %s", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+	return nil
+}
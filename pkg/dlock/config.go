@@ -0,0 +1,849 @@
+package dlock
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a time.Duration encoded as a string such as "30m" in config files
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "30m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Config holds disabler settings loaded from a file, environment variables or flags
+type Config struct {
+	Devices             []string `json:"devices"`
+	SyncTime            bool     `json:"sync_time"`
+	ProcessingDeadline  Duration `json:"processing_deadline"`
+	PostUnlockCommands  []string `json:"post_unlock_commands"`
+	CommandTimeout      Duration `json:"command_timeout"`
+	MaxRetries          int      `json:"max_retries"`
+	AutoRetryFailures   int      `json:"auto_retry_failures"` // Passes retrying the devices that failed, disabled when 0
+	ADBPath             string   `json:"adb_path"`
+	LogLevel            string   `json:"log_level"`
+	Concurrency         int      `json:"concurrency"`
+	CommandRateLimit    float64  `json:"command_rate_limit"` // ADB commands per second across all devices, unlimited when 0
+	TCPPoolSize         int      `json:"tcp_pool_size"`      // Pooled ADB server sockets for TCP/IP devices, disabled when 0
+	TCPPoolIdleTimeout  Duration `json:"tcp_pool_idle_timeout"`
+	RetryBackoff        Duration `json:"retry_backoff"`
+	RetryBackoffMax     Duration `json:"retry_backoff_max"`
+	PropertyReadTimeout Duration `json:"property_read_timeout"`
+	RebootTimeout       Duration `json:"reboot_timeout"`
+	WatchGracePeriod    Duration `json:"watch_grace_period"`
+	DryRun              bool     `json:"dry_run"`
+	Method              int      `json:"method"` // Only disable method to try, all when 0
+	MinAPILevel         int      `json:"min_api_level"`
+	MaxAPILevel         int      `json:"max_api_level"`
+	AutoRestartADB      bool     `json:"auto_restart_adb"`
+	IgnoreMDM           bool     `json:"ignore_mdm"`
+	DisableWorkProfile  bool     `json:"disable_work_profile"`
+	AllUsers            bool     `json:"all_users"`
+	PerDeviceLogDir     string   `json:"per_device_log_dir"`
+	ReportOutput        string   `json:"report_output"`
+	CSVOutput           string   `json:"csv_output"`
+	AliasFile           string   `json:"alias_file"` // JSON object mapping serials to aliases
+	AuditLog            string   `json:"audit_log"`
+	TelemetryFile       string   `json:"telemetry_file"` // JSON lines of every method attempt, see GenerateMethodSuccessReport
+	CorrelationID       string   `json:"correlation_id"` // Prefixed to every log message, see WithCorrelationID
+	NoColor             bool     `json:"no_color"`
+	DesktopNotification bool     `json:"desktop_notification"`
+	SkipEmulators       bool     `json:"skip_emulators"`
+	DisableAnimations   bool     `json:"disable_animations"`
+	SkipRebootVerified  bool     `json:"skip_reboot_if_verified"`
+	AttemptAllMethods   bool     `json:"attempt_all_methods"`
+	SlackWebhook        string   `json:"slack_webhook"`
+	ProcessedStoreFile  string   `json:"processed_store_file"`
+	ProgressInterval    Duration `json:"progress_interval"` // Write progress lines to stderr this often, disabled when 0
+	ScreenshotDir       string   `json:"screenshot_dir"`
+	DisableAutoRestore  bool     `json:"disable_auto_restore"`
+	BootCompleteTimeout Duration `json:"boot_complete_timeout"`
+	SettingsDiff        bool     `json:"settings_diff"`
+	TemporaryUnlock     bool     `json:"temporary_unlock"` // Only dismiss the keyguard until the next reboot
+	RebootWaitTimeout   Duration `json:"reboot_wait_timeout"`
+	RebootPollInterval  Duration `json:"reboot_poll_interval"`
+	RebootSettleTime    Duration `json:"reboot_settle_time"`
+	MethodRetryDelay    Duration `json:"method_retry_delay"` // Pause between methods and before rebooting, see WithMethodRetryDelay
+}
+
+// LoadConfig reads and validates a JSON or YAML config file; unknown keys are rejected
+func LoadConfig(path string) (*Config, error) {
+	cfg, _, err := NewConfigBuilder().FromFile(path).Build()
+	return cfg, err
+}
+
+// Options converts the config into functional options for NewAndroidLockScreenDisabler
+func (c *Config) Options() []Option {
+	opts := []Option{
+		WithSyncTime(c.SyncTime),
+		WithDryRun(c.DryRun),
+		WithAutoRestartADB(c.AutoRestartADB),
+		WithIgnoreMDM(c.IgnoreMDM),
+		WithDisableWorkProfile(c.DisableWorkProfile),
+		WithAllUsers(c.AllUsers),
+		WithAutoRestore(!c.DisableAutoRestore),
+		WithSettingsDiff(c.SettingsDiff),
+		WithPersistentDisable(!c.TemporaryUnlock),
+		WithDesktopNotification(c.DesktopNotification),
+		WithSkipEmulators(c.SkipEmulators),
+		WithDisableAnimations(c.DisableAnimations),
+		WithSkipRebootIfVerified(c.SkipRebootVerified),
+		WithAttemptAllMethods(c.AttemptAllMethods),
+	}
+
+	if c.ProcessingDeadline > 0 {
+		opts = append(opts, WithProcessingDeadline(time.Duration(c.ProcessingDeadline)))
+	}
+
+	if c.CommandTimeout > 0 {
+		opts = append(opts, WithCommandTimeout(time.Duration(c.CommandTimeout)))
+	}
+
+	if c.MaxRetries > 0 {
+		opts = append(opts, WithMaxRetries(c.MaxRetries))
+	}
+
+	if c.AutoRetryFailures > 0 {
+		opts = append(opts, WithAutoRetryFailures(c.AutoRetryFailures))
+	}
+
+	if c.Method != 0 {
+		opts = append(opts, WithForceMethod(c.Method))
+	}
+
+	if c.MinAPILevel > 0 {
+		opts = append(opts, WithMinAPILevel(c.MinAPILevel))
+	}
+
+	if c.MaxAPILevel > 0 {
+		opts = append(opts, WithMaxAPILevel(c.MaxAPILevel))
+	}
+
+	if c.Concurrency > 0 {
+		opts = append(opts, WithConcurrency(c.Concurrency))
+	}
+
+	if c.CommandRateLimit > 0 {
+		opts = append(opts, WithCommandRateLimit(c.CommandRateLimit))
+	}
+
+	if c.TCPPoolSize > 0 {
+		idleTimeout := time.Duration(c.TCPPoolIdleTimeout)
+		if idleTimeout == 0 {
+			idleTimeout = defaultPoolIdleTimeout
+		}
+		opts = append(opts, WithConnectionPool(NewADBConnectionPool(c.TCPPoolSize, idleTimeout)))
+	}
+
+	if c.ADBPath != "" {
+		opts = append(opts, WithADBPath(c.ADBPath))
+	}
+
+	if c.PerDeviceLogDir != "" {
+		opts = append(opts, WithPerDeviceLogDir(c.PerDeviceLogDir))
+	}
+
+	if c.ReportOutput != "" {
+		opts = append(opts, WithReportOutput(c.ReportOutput))
+	}
+
+	if c.CSVOutput != "" {
+		opts = append(opts, WithCSVOutput(c.CSVOutput))
+	}
+
+	if c.AuditLog != "" {
+		opts = append(opts, WithAuditLogFile(c.AuditLog))
+	}
+
+	if c.ProcessedStoreFile != "" {
+		opts = append(opts, WithProcessedStore(FileStore(c.ProcessedStoreFile)))
+	}
+
+	if c.SlackWebhook != "" {
+		opts = append(opts, WithSlackWebhook(c.SlackWebhook))
+	}
+
+	if c.ProgressInterval > 0 {
+		opts = append(opts, WithProgressReporter(time.Duration(c.ProgressInterval), os.Stderr))
+	}
+
+	if c.NoColor {
+		opts = append(opts, WithColorOutput(false))
+	}
+
+	if c.CorrelationID != "" {
+		opts = append(opts, WithCorrelationID(c.CorrelationID))
+	}
+
+	if c.TelemetryFile != "" {
+		opts = append(opts, WithTelemetry(NewFileTelemetryRecorder(c.TelemetryFile)))
+	}
+
+	if c.ScreenshotDir != "" {
+		opts = append(opts, WithScreenshots(c.ScreenshotDir))
+	}
+
+	if c.AliasFile != "" {
+		path := c.AliasFile
+		opts = append(opts, func(cfg *config) error {
+			aliases, err := LoadAliasFile(path)
+			if err != nil {
+				return err
+			}
+			return WithDeviceAliases(aliases)(cfg)
+		})
+	}
+
+	if c.RetryBackoff > 0 || c.RetryBackoffMax > 0 {
+		initial, max := time.Duration(c.RetryBackoff), time.Duration(c.RetryBackoffMax)
+		if initial == 0 {
+			initial = defaultRetryInitial
+		}
+		if max == 0 {
+			max = defaultRetryMax
+		}
+		opts = append(opts, WithRetryBackoff(initial, max))
+	}
+
+	if c.PropertyReadTimeout > 0 {
+		opts = append(opts, WithCategoryTimeout(CommandCategoryPropertyRead, time.Duration(c.PropertyReadTimeout)))
+	}
+
+	if c.RebootTimeout > 0 {
+		opts = append(opts, WithCategoryTimeout(CommandCategoryReboot, time.Duration(c.RebootTimeout)))
+	}
+
+	if c.RebootWaitTimeout > 0 {
+		opts = append(opts, WithRebootWaitTimeout(time.Duration(c.RebootWaitTimeout)))
+	}
+
+	if c.RebootPollInterval > 0 {
+		opts = append(opts, WithRebootPollInterval(time.Duration(c.RebootPollInterval)))
+	}
+
+	if c.RebootSettleTime > 0 {
+		opts = append(opts, WithRebootSettleTime(time.Duration(c.RebootSettleTime)))
+	}
+
+	if c.BootCompleteTimeout > 0 {
+		opts = append(opts, WithBootCompletedTimeout(time.Duration(c.BootCompleteTimeout)))
+	}
+
+	if c.MethodRetryDelay > 0 {
+		opts = append(opts, WithMethodRetryDelay(time.Duration(c.MethodRetryDelay)))
+	}
+
+	if c.WatchGracePeriod > 0 {
+		opts = append(opts, WithWatchGracePeriod(time.Duration(c.WatchGracePeriod)))
+	}
+
+	if len(c.PostUnlockCommands) > 0 {
+		opts = append(opts, WithPostUnlockCommands(c.PostUnlockCommands...))
+	}
+
+	if c.LogLevel != "" {
+		level, err := ParseLogLevel(c.LogLevel)
+		opts = append(opts, func(cfg *config) error {
+			if err != nil {
+				return err
+			}
+			return WithLogLevel(level)(cfg)
+		})
+	}
+
+	return opts
+}
+
+// configKey describes a configuration key and how its value is applied to a Config
+type configKey struct {
+	name      string
+	apply     func(c *Config, value string) error
+	normalize func(value string) string // Canonical form the sources are compared in, if not the value itself
+}
+
+// normalized returns value in the form the sources of the key are compared in
+func (k configKey) normalized(value string) string {
+	if k.normalize == nil {
+		return value
+	}
+	return k.normalize(value)
+}
+
+// normalizeFields joins the whitespace-separated items of a list value with single spaces,
+// so a list from a config file matches the same list given as a flag or environment variable
+func normalizeFields(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// configKeys lists every supported configuration key in display order
+var configKeys = []configKey{
+	{name: "devices", apply: func(c *Config, value string) error {
+		c.Devices = strings.Fields(value)
+		return nil
+	}, normalize: normalizeFields},
+	{name: "sync_time", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.SyncTime = enabled
+		return nil
+	}},
+	{name: "auto_restart_adb", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.AutoRestartADB = enabled
+		return nil
+	}},
+	{name: "ignore_mdm", apply: func(c *Config, value string) error {
+		ignore, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.IgnoreMDM = ignore
+		return nil
+	}},
+	{name: "disable_work_profile", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.DisableWorkProfile = enabled
+		return nil
+	}},
+	{name: "all_users", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.AllUsers = enabled
+		return nil
+	}},
+	{name: "disable_auto_restore", apply: func(c *Config, value string) error {
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.DisableAutoRestore = disabled
+		return nil
+	}},
+	{name: "settings_diff", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.SettingsDiff = enabled
+		return nil
+	}},
+	{name: "temporary_unlock", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.TemporaryUnlock = enabled
+		return nil
+	}},
+	{name: "dry_run", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.DryRun = enabled
+		return nil
+	}},
+	{name: "processing_deadline", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.ProcessingDeadline = Duration(d)
+		return nil
+	}},
+	{name: "command_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.CommandTimeout = Duration(d)
+		return nil
+	}},
+	{name: "property_read_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.PropertyReadTimeout = Duration(d)
+		return nil
+	}},
+	{name: "reboot_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RebootTimeout = Duration(d)
+		return nil
+	}},
+	{name: "max_retries", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.MaxRetries = n
+		return nil
+	}},
+	{name: "auto_retry_failures", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.AutoRetryFailures = n
+		return nil
+	}},
+	{name: "retry_backoff", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RetryBackoff = Duration(d)
+		return nil
+	}},
+	{name: "retry_backoff_max", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RetryBackoffMax = Duration(d)
+		return nil
+	}},
+	{name: "method", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.Method = n
+		return nil
+	}},
+	{name: "min_api_level", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.MinAPILevel = n
+		return nil
+	}},
+	{name: "max_api_level", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.MaxAPILevel = n
+		return nil
+	}},
+	{name: "concurrency", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.Concurrency = n
+		return nil
+	}},
+	{name: "command_rate_limit", apply: func(c *Config, value string) error {
+		rps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		c.CommandRateLimit = rps
+		return nil
+	}},
+	{name: "tcp_pool_size", apply: func(c *Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		c.TCPPoolSize = n
+		return nil
+	}},
+	{name: "tcp_pool_idle_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.TCPPoolIdleTimeout = Duration(d)
+		return nil
+	}},
+	{name: "adb_path", apply: func(c *Config, value string) error {
+		c.ADBPath = value
+		return nil
+	}},
+	{name: "per_device_log_dir", apply: func(c *Config, value string) error {
+		c.PerDeviceLogDir = value
+		return nil
+	}},
+	{name: "report_output", apply: func(c *Config, value string) error {
+		c.ReportOutput = value
+		return nil
+	}},
+	{name: "csv_output", apply: func(c *Config, value string) error {
+		c.CSVOutput = value
+		return nil
+	}},
+	{name: "alias_file", apply: func(c *Config, value string) error {
+		c.AliasFile = value
+		return nil
+	}},
+	{name: "audit_log", apply: func(c *Config, value string) error {
+		c.AuditLog = value
+		return nil
+	}},
+	{name: "telemetry_file", apply: func(c *Config, value string) error {
+		c.TelemetryFile = value
+		return nil
+	}},
+	{name: "progress_interval", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.ProgressInterval = Duration(d)
+		return nil
+	}},
+	{name: "desktop_notification", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.DesktopNotification = enabled
+		return nil
+	}},
+	{name: "skip_emulators", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.SkipEmulators = enabled
+		return nil
+	}},
+	{name: "disable_animations", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.DisableAnimations = enabled
+		return nil
+	}},
+	{name: "skip_reboot_if_verified", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.SkipRebootVerified = enabled
+		return nil
+	}},
+	{name: "attempt_all_methods", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.AttemptAllMethods = enabled
+		return nil
+	}},
+	{name: "processed_store_file", apply: func(c *Config, value string) error {
+		c.ProcessedStoreFile = value
+		return nil
+	}},
+	{name: "slack_webhook", apply: func(c *Config, value string) error {
+		c.SlackWebhook = value
+		return nil
+	}},
+	{name: "no_color", apply: func(c *Config, value string) error {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		c.NoColor = enabled
+		return nil
+	}},
+	{name: "correlation_id", apply: func(c *Config, value string) error {
+		c.CorrelationID = value
+		return nil
+	}},
+	{name: "screenshot_dir", apply: func(c *Config, value string) error {
+		c.ScreenshotDir = value
+		return nil
+	}},
+	{name: "watch_grace_period", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.WatchGracePeriod = Duration(d)
+		return nil
+	}},
+	{name: "reboot_wait_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RebootWaitTimeout = Duration(d)
+		return nil
+	}},
+	{name: "reboot_poll_interval", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RebootPollInterval = Duration(d)
+		return nil
+	}},
+	{name: "reboot_settle_time", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.RebootSettleTime = Duration(d)
+		return nil
+	}},
+	{name: "method_retry_delay", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.MethodRetryDelay = Duration(d)
+		return nil
+	}},
+	{name: "boot_complete_timeout", apply: func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		c.BootCompleteTimeout = Duration(d)
+		return nil
+	}},
+	{name: "log_level", apply: func(c *Config, value string) error {
+		if _, err := ParseLogLevel(value); err != nil {
+			return err
+		}
+		c.LogLevel = value
+		return nil
+	}},
+	// One command per line, since commands contain spaces
+	{name: "post_unlock_commands", apply: func(c *Config, value string) error {
+		c.PostUnlockCommands = nil
+		for _, line := range strings.Split(value, "\n") {
+			if command := strings.TrimSpace(line); command != "" {
+				c.PostUnlockCommands = append(c.PostUnlockCommands, command)
+			}
+		}
+		return nil
+	}},
+}
+
+// lookupConfigKey returns the configuration key with the given name
+func lookupConfigKey(name string) (configKey, bool) {
+	for _, key := range configKeys {
+		if key.name == name {
+			return key, true
+		}
+	}
+	return configKey{}, false
+}
+
+// ConfigConflict describes a key set to different values by more than one source
+type ConfigConflict struct {
+	Key           string
+	FileValue     string
+	EnvValue      string
+	FlagValue     string
+	ResolvedValue string
+}
+
+// ConfigBuilder merges configuration sources with the precedence file < env < flags
+type ConfigBuilder struct {
+	fileValues map[string]string
+	envValues  map[string]string
+	flagValues map[string]string
+	err        error
+}
+
+// NewConfigBuilder creates an empty ConfigBuilder
+func NewConfigBuilder() *ConfigBuilder {
+	return &ConfigBuilder{
+		fileValues: make(map[string]string),
+		envValues:  make(map[string]string),
+		flagValues: make(map[string]string),
+	}
+}
+
+// FromFile records the values of a JSON or YAML (.yaml, .yml) config file
+func (b *ConfigBuilder) FromFile(path string) *ConfigBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.err = fmt.Errorf("failed to read config file: %w", err)
+		return b
+	}
+
+	var raw map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	default:
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		b.err = fmt.Errorf("%w: failed to parse config file %s: %w", ErrInvalidConfig, path, err)
+		return b
+	}
+
+	for name, value := range raw {
+		if _, ok := lookupConfigKey(name); !ok {
+			b.err = fmt.Errorf("%w: unknown config key %q in %s", ErrInvalidConfig, name, path)
+			return b
+		}
+
+		formatted, err := formatConfigValue(value)
+		if err != nil {
+			b.err = fmt.Errorf("%w: invalid value for config key %q: %w", ErrInvalidConfig, name, err)
+			return b
+		}
+		b.fileValues[name] = formatted
+	}
+
+	return b
+}
+
+// FromEnv records values from DLOCK_<KEY> environment variables
+func (b *ConfigBuilder) FromEnv() *ConfigBuilder {
+	for _, key := range configKeys {
+		if value, ok := os.LookupEnv(envVarName(key.name)); ok {
+			b.envValues[key.name] = value
+		}
+	}
+	return b
+}
+
+// FromFlags records the values of flags explicitly set on the command line
+func (b *ConfigBuilder) FromFlags(fs *flag.FlagSet) *ConfigBuilder {
+	fs.Visit(func(f *flag.Flag) {
+		name := strings.ReplaceAll(f.Name, "-", "_")
+		if _, ok := lookupConfigKey(name); ok {
+			b.flagValues[name] = f.Value.String()
+		}
+	})
+	return b
+}
+
+// Build resolves all sources into a Config and reports keys whose sources disagree
+func (b *ConfigBuilder) Build() (*Config, []ConfigConflict, error) {
+	if b.err != nil {
+		return nil, nil, b.err
+	}
+
+	cfg := &Config{}
+	var conflicts []ConfigConflict
+
+	for _, key := range configKeys {
+		fileValue, inFile := b.fileValues[key.name]
+		envValue, inEnv := b.envValues[key.name]
+		flagValue, inFlags := b.flagValues[key.name]
+
+		var resolved string
+		var sources []string
+		if inFile {
+			resolved = fileValue
+			sources = append(sources, fileValue)
+		}
+		if inEnv {
+			resolved = envValue
+			sources = append(sources, envValue)
+		}
+		if inFlags {
+			resolved = flagValue
+			sources = append(sources, flagValue)
+		}
+
+		if len(sources) == 0 {
+			continue
+		}
+
+		if err := key.apply(cfg, resolved); err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid value for config key %q: %w", ErrInvalidConfig, key.name, err)
+		}
+
+		for _, value := range sources[1:] {
+			if key.normalized(value) != key.normalized(sources[0]) {
+				conflicts = append(conflicts, ConfigConflict{
+					Key:           key.name,
+					FileValue:     fileValue,
+					EnvValue:      envValue,
+					FlagValue:     flagValue,
+					ResolvedValue: resolved,
+				})
+				break
+			}
+		}
+	}
+
+	return cfg, conflicts, nil
+}
+
+// envVarName returns the environment variable used for a config key
+func envVarName(key string) string {
+	return "DLOCK_" + strings.ToUpper(key)
+}
+
+// formatConfigValue converts a decoded JSON value into its flag-style string form
+func formatConfigValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			formatted, err := formatConfigValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, formatted)
+		}
+		return strings.Join(parts, "\n"), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
@@ -0,0 +1,40 @@
+package dlock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigBuilderListConflicts(t *testing.T) {
+	tests := []struct {
+		name         string
+		envDevices   string
+		wantConflict bool
+	}{
+		{"same devices", "DEV1 DEV2", false},
+		{"same devices with extra whitespace", " DEV1\tDEV2 ", false},
+		{"different devices", "DEV1 DEV3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dlock.json")
+			if err := os.WriteFile(path, []byte(`{"devices": ["DEV1", "DEV2"]}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(envVarName("devices"), tt.envDevices)
+
+			cfg, conflicts, err := NewConfigBuilder().FromFile(path).FromEnv().Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if gotConflict := len(conflicts) > 0; gotConflict != tt.wantConflict {
+				t.Errorf("Build() conflicts = %+v, want conflict %v", conflicts, tt.wantConflict)
+			}
+			if len(cfg.Devices) != 2 || cfg.Devices[0] != "DEV1" {
+				t.Errorf("Build() devices = %v, want the environment's", cfg.Devices)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package dlock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// deviceCorrelationIDKey is the context key carrying the correlation ID of a device run
+type deviceCorrelationIDKey struct{}
+
+// DeviceCorrelationID returns the correlation ID of the device run that ctx belongs to, such as the ctx
+// passed to the WithPreProcess and WithPostProcess hooks, or "" if ctx does not belong to a device run
+func DeviceCorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(deviceCorrelationIDKey{}).(string)
+	return id
+}
+
+// startDeviceCorrelation assigns a new correlation ID to a device run, prefixed with the correlation ID
+// of the disabler if one is configured, and returns it with a context carrying it
+func (a *AndroidLockScreenDisabler) startDeviceCorrelation(ctx context.Context, deviceSerial string) (context.Context, string) {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+
+	id := hex.EncodeToString(suffix)
+	if a.cfg.correlationID != "" {
+		id = a.cfg.correlationID + "-" + id
+	}
+
+	a.correlationIDs.Store(deviceSerial, id)
+	return context.WithValue(ctx, deviceCorrelationIDKey{}, id), id
+}
+
+// endDeviceCorrelation forgets the correlation ID of a finished device run
+func (a *AndroidLockScreenDisabler) endDeviceCorrelation(deviceSerial string) {
+	a.correlationIDs.Delete(deviceSerial)
+}
+
+// correlationKVs returns the correlation IDs of the disabler and of the device run named in kvs, if any, as key/value pairs
+func (a *AndroidLockScreenDisabler) correlationKVs(kvs []interface{}) []interface{} {
+	var ids []interface{}
+	if a.cfg.correlationID != "" {
+		ids = append(ids, "correlation_id", a.cfg.correlationID)
+	}
+
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if key, ok := kvs[i].(string); !ok || key != "device" {
+			continue
+		}
+		if deviceSerial, ok := kvs[i+1].(string); ok {
+			if id, ok := a.correlationIDs.Load(deviceSerial); ok {
+				ids = append(ids, "device_correlation_id", id)
+			}
+		}
+		break
+	}
+	return ids
+}
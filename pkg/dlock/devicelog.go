@@ -0,0 +1,91 @@
+package dlock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deviceLogFile is the per-device log file of a device being processed
+type deviceLogFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// safeFileName maps a serial to a file name, replacing characters such as ':' used by TCP serials
+func safeFileName(deviceSerial string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, deviceSerial)
+}
+
+// openDeviceLog opens the per-device log file of a device, doing nothing when no directory is configured
+func (a *AndroidLockScreenDisabler) openDeviceLog(deviceSerial string) error {
+	if a.cfg.perDeviceLogDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(a.cfg.perDeviceLogDir, 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+
+	path := filepath.Join(a.cfg.perDeviceLogDir, safeFileName(deviceSerial)+".log")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, loaded := a.deviceLogs.LoadOrStore(deviceSerial, &deviceLogFile{file: file}); loaded {
+		// Another goroutine already owns the log of this device
+		file.Close()
+	}
+	return nil
+}
+
+// closeDeviceLog closes the per-device log file of a device, if one is open
+func (a *AndroidLockScreenDisabler) closeDeviceLog(deviceSerial string) {
+	value, ok := a.deviceLogs.LoadAndDelete(deviceSerial)
+	if !ok {
+		return
+	}
+
+	logFile := value.(*deviceLogFile)
+	logFile.mu.Lock()
+	defer logFile.mu.Unlock()
+	logFile.file.Close()
+}
+
+// writeDeviceLog appends a timestamped line to the log file of the device named in kvs, if one is open
+func (a *AndroidLockScreenDisabler) writeDeviceLog(level LogLevel, message, emoji string, kvs []interface{}) {
+	if a.cfg.perDeviceLogDir == "" {
+		return
+	}
+
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if key, ok := kvs[i].(string); !ok || key != "device" {
+			continue
+		}
+		deviceSerial, ok := kvs[i+1].(string)
+		if !ok {
+			return
+		}
+		value, ok := a.deviceLogs.Load(deviceSerial)
+		if !ok {
+			return
+		}
+
+		logFile := value.(*deviceLogFile)
+		logFile.mu.Lock()
+		defer logFile.mu.Unlock()
+		fmt.Fprintf(logFile.file, "%s [%s] %s %s\n", time.Now().Format(time.RFC3339Nano), strings.ToUpper(level.String()), emoji, message)
+		return
+	}
+}
@@ -1,224 +1,853 @@
 package dlock
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // AndroidLockScreenDisabler handles the lock screen disabling process
 type AndroidLockScreenDisabler struct {
-	connectedDevices []string
-	targetDevices    []string // New field for target UDIDs
-	logMutex         sync.Mutex
-	enableLogging    bool // Control whether logging is enabled
+	connectedDevices    []string
+	cfg                 config
+	adbRestartMu        sync.Mutex                      // Serializes ADB server restarts
+	consecutiveFailures atomic.Int32                    // Failed ADB commands in a row, across all devices
+	deviceLogs          sync.Map                        // Open per-device log files by serial
+	auditMu             sync.Mutex                      // Serializes audit log entries
+	batchStats          atomic.Pointer[ProcessingStats] // Stats of the most recently started batch or watch
+	correlationIDs      sync.Map                        // Correlation IDs of the devices being processed by serial
+	methodRegistry      methodRegistry                  // Custom disable methods added with RegisterMethod
 }
 
-// NewAndroidLockScreenDisabler creates a new instance of the disabler
-func NewAndroidLockScreenDisabler(targetDevices []string) *AndroidLockScreenDisabler {
+// New creates a new instance of the disabler configured entirely through options
+func New(opts ...Option) (*AndroidLockScreenDisabler, error) {
+	a := newAndroidLockScreenDisabler()
+
+	for _, opt := range opts {
+		if err := opt(&a.cfg); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	if err := a.cfg.finalize(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	return a, nil
+}
+
+// NewAndroidLockScreenDisabler creates a new instance of the disabler.
+// Invalid options are logged and ignored; use NewAndroidLockScreenDisablerWithError to reject them.
+func NewAndroidLockScreenDisabler(targetDevices []string, opts ...Option) *AndroidLockScreenDisabler {
+	a := newAndroidLockScreenDisabler()
+
+	var optionErrors []error
+	for _, opt := range append([]Option{WithTargetDevices(targetDevices...)}, opts...) {
+		if err := opt(&a.cfg); err != nil {
+			optionErrors = append(optionErrors, err)
+		}
+	}
+
+	// Log only once finalize has set up the logger
+	finalizeErr := a.cfg.finalize()
+	for _, err := range optionErrors {
+		a.log(LogLevelWarn, fmt.Sprintf("Ignoring invalid option: %v", err), "⚠️")
+	}
+	if finalizeErr != nil {
+		a.log(LogLevelWarn, fmt.Sprintf("%v, looking up adb in PATH instead", finalizeErr), "⚠️")
+	}
+	return a
+}
+
+// NewAndroidLockScreenDisablerWithError creates a new instance of the disabler, failing on invalid options
+func NewAndroidLockScreenDisablerWithError(targetDevices []string, opts ...Option) (*AndroidLockScreenDisabler, error) {
+	return New(append([]Option{WithTargetDevices(targetDevices...)}, opts...)...)
+}
+
+// NewAndroidLockScreenDisablerFromConfig creates a new instance of the disabler from a Config,
+// failing on invalid settings
+func NewAndroidLockScreenDisablerFromConfig(cfg *Config) (*AndroidLockScreenDisabler, error) {
+	return NewAndroidLockScreenDisablerWithError(cfg.Devices, cfg.Options()...)
+}
+
+// newAndroidLockScreenDisabler creates a disabler with the default configuration
+func newAndroidLockScreenDisabler() *AndroidLockScreenDisabler {
 	return &AndroidLockScreenDisabler{
 		connectedDevices: make([]string, 0),
-		targetDevices:    targetDevices,
-		enableLogging:    true, // Default to enabled logging
+		cfg:              defaultConfig(),
 	}
 }
 
 // SetLogging enables or disables logging
 func (a *AndroidLockScreenDisabler) SetLogging(enabled bool) {
-	a.enableLogging = enabled
+	a.cfg.logging = enabled
 }
 
-// log prints formatted log messages with emojis (thread-safe)
-func (a *AndroidLockScreenDisabler) log(message, emoji string) {
-	if !a.enableLogging {
+// log sends a message with its emoji prefix and optional key/value pairs to the configured logger,
+// skipping messages below the configured level
+func (a *AndroidLockScreenDisabler) log(level LogLevel, message, emoji string, kvs ...interface{}) {
+	if !a.cfg.logging || level < a.cfg.logLevel {
 		return
 	}
 
-	if emoji == "" {
-		emoji = "ℹ️"
+	a.writeDeviceLog(level, message, emoji, kvs)
+
+	if a.cfg.correlationID != "" {
+		message = fmt.Sprintf("[%s] %s", a.cfg.correlationID, message)
+	}
+	kvs = append(append([]interface{}{emojiKey, emoji}, kvs...), a.correlationKVs(kvs)...)
+	switch level {
+	case LogLevelDebug:
+		a.cfg.logger.Debug(message, kvs...)
+	case LogLevelWarn:
+		a.cfg.logger.Warn(message, kvs...)
+	case LogLevelError:
+		a.cfg.logger.Error(message, kvs...)
+	default:
+		a.cfg.logger.Info(message, kvs...)
 	}
+}
+
+// addWarning logs a non-fatal issue and records it on the device result
+func (a *AndroidLockScreenDisabler) addWarning(result *DeviceResult, deviceTag, warning string) {
+	a.log(LogLevelWarn, fmt.Sprintf("%s %s", deviceTag, warning), "⚠️", "device", result.Serial)
+	result.AddWarning(warning)
+}
 
-	a.logMutex.Lock()
-	defer a.logMutex.Unlock()
-	fmt.Printf("%s %s\n", emoji, message)
+// cancelDevice marks a device as cancelled because ctx was done before it finished
+func (a *AndroidLockScreenDisabler) cancelDevice(ctx context.Context, result *DeviceResult, stats *ProcessingStats, deviceTag string) {
+	a.log(LogLevelWarn, fmt.Sprintf("%s Processing cancelled: %v", deviceTag, ctx.Err()), "⛔", "device", result.Serial)
+	result.Status = StatusCancelled
+	result.Error = fmt.Sprintf("cancelled: %v", ctx.Err())
+	result.Err = ctx.Err()
+	stats.AddFailedDevice(result.Serial)
 }
 
-// DisableLockscreenOnDeviceAsync processes a single device asynchronously
-func (a *AndroidLockScreenDisabler) DisableLockscreenOnDeviceAsync(deviceSerial string, stats *ProcessingStats, wg *sync.WaitGroup) {
+// DisableLockscreenOnDeviceAsync processes a single device asynchronously, stopping early once ctx is done
+func (a *AndroidLockScreenDisabler) DisableLockscreenOnDeviceAsync(ctx context.Context, deviceSerial string, stats *ProcessingStats, wg *sync.WaitGroup) {
 	defer wg.Done()
 
+	stats.inProgress.Add(1)
+	defer stats.inProgress.Add(-1)
+
 	// Add device identifier to logs for better tracking in concurrent execution
-	deviceTag := fmt.Sprintf("[%s]", deviceSerial)
+	deviceTag := fmt.Sprintf("[%s]", a.deviceName(deviceSerial))
 
-	a.log(fmt.Sprintf("%s Starting lock screen disable process", deviceTag), "🚀")
+	logErr := a.openDeviceLog(deviceSerial)
+	defer a.closeDeviceLog(deviceSerial)
 
-	// Get device info
-	deviceInfo := a.GetDeviceInfo(deviceSerial)
-	a.log(fmt.Sprintf("%s Device: %s %s (Android %s, API %s)", deviceTag,
-		deviceInfo.Manufacturer, deviceInfo.Model, deviceInfo.AndroidVersion, deviceInfo.APILevel), "📋")
-
-	// Check permissions
-	if !a.CheckDevicePermissions(deviceSerial) {
-		a.log(fmt.Sprintf("%s Insufficient permissions. "+
-			"Make sure USB debugging is enabled and device is authorized.", deviceTag), "❌")
+	ctx, correlationID := a.startDeviceCorrelation(ctx, deviceSerial)
+	defer a.endDeviceCorrelation(deviceSerial)
+
+	result := DeviceResult{Serial: deviceSerial, Alias: a.cfg.deviceAliases[deviceSerial], StartTime: time.Now(), AttemptCount: stats.currentAttempt(), CorrelationID: correlationID}
+	var settingsBefore map[string]map[string]string
+	defer func() {
+		if settingsBefore != nil {
+			a.recordSettingsDiff(ctx, &result, deviceTag, settingsBefore)
+		}
+		if result.Success {
+			a.runPostUnlockSteps(ctx, deviceSerial, deviceTag, &result)
+			// A dry run leaves the lock screen in place, so the device still needs processing
+			if !a.cfg.dryRun {
+				if err := a.processedStore(stats).MarkProcessed(deviceSerial); err != nil {
+					a.addWarning(&result, deviceTag, fmt.Sprintf("Could not record device as processed: %v", err))
+				}
+			}
+		}
+		if result.Status == "" {
+			result.Status = StatusFailed
+			if result.Success {
+				result.Status = StatusSuccess
+			}
+		}
+		result.Duration = time.Since(result.StartTime)
+		if a.cfg.postProcess != nil {
+			if err := a.cfg.postProcess(ctx, deviceSerial, result); err != nil {
+				a.addWarning(&result, deviceTag, fmt.Sprintf("Post-process hook failed: %v", err))
+			}
+		}
+		if a.cfg.history != nil && !a.cfg.dryRun {
+			if err := a.cfg.history.Record(result); err != nil {
+				a.addWarning(&result, deviceTag, fmt.Sprintf("Could not record device history: %v", err))
+			}
+		}
+		stats.AddResult(result)
+	}()
+
+	if err := a.checkSerial(deviceSerial); err != nil {
+		result.Error = err.Error()
+		result.Err = err
 		stats.AddFailedDevice(deviceSerial)
 		return
 	}
 
+	if logErr != nil {
+		a.addWarning(&result, deviceTag, fmt.Sprintf("Could not open per-device log: %v", logErr))
+	}
+
+	if ctx.Err() != nil {
+		a.cancelDevice(ctx, &result, stats, deviceTag)
+		return
+	}
+
+	if a.cfg.skipEmulators && IsEmulator(deviceSerial) {
+		a.log(LogLevelDebug, fmt.Sprintf("%s Emulator, skipping device", deviceTag), "⏭️", "device", deviceSerial)
+		result.Status = StatusSkipped
+		result.Error = "emulator"
+		return
+	}
+
+	if processed, err := a.processedStore(stats).IsProcessed(deviceSerial); err != nil {
+		a.addWarning(&result, deviceTag, fmt.Sprintf("Could not check whether device was already processed: %v", err))
+	} else if processed {
+		a.log(LogLevelDebug, fmt.Sprintf("%s Already processed, skipping device", deviceTag), "⏭️", "device", deviceSerial)
+		result.Status = StatusSkipped
+		result.Error = "already processed"
+		return
+	}
+
+	if a.cfg.preProcess != nil {
+		if err := a.cfg.preProcess(ctx, deviceSerial); err != nil {
+			a.log(LogLevelError, fmt.Sprintf("%s Pre-process hook failed, skipping device: %v", deviceTag, err), "⏭️", "device", deviceSerial)
+			result.Error = fmt.Sprintf("pre-process hook failed: %v", err)
+			result.Err = fmt.Errorf("pre-process hook failed: %w", err)
+			stats.AddFailedDevice(deviceSerial)
+			return
+		}
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("%s Starting lock screen disable process", deviceTag), "🚀", "device", deviceSerial)
+
+	// Get device info
+	a.markStep(deviceSerial, "Collect device information")
+	deviceInfo, ok := stats.deviceInfo[deviceSerial]
+	if !ok {
+		deviceInfo = a.getDeviceInfo(ctx, deviceSerial)
+	}
+	result.Manufacturer, result.Model, result.APILevel = deviceInfo.Manufacturer, deviceInfo.Model, deviceInfo.APILevel
+	a.log(LogLevelDebug, fmt.Sprintf("%s Device: %s %s (Android %s, API %s)", deviceTag,
+		deviceInfo.Manufacturer, deviceInfo.Model, deviceInfo.AndroidVersion, deviceInfo.APILevel), "📋", "device", deviceSerial)
+
+	// Check that the device can be processed before trying any method
+	a.markStep(deviceSerial, "Preflight checks")
+	preflight, err := a.preflightCheck(ctx, deviceSerial, deviceInfo)
+	if err != nil {
+		a.cancelDevice(ctx, &result, stats, deviceTag)
+		return
+	}
+	if !preflight.CanProceed {
+		a.rejectDevice(&result, stats, deviceTag, preflight)
+		return
+	}
+	for _, issue := range preflight.Issues {
+		a.addWarning(&result, deviceTag, fmt.Sprintf("Preflight: %s", issue.Message))
+	}
+
+	// A SIM PIN prompt looks like a lock screen but only someone at the device can get past it
+	if preflight.SIMStatus.Locked() {
+		a.addWarning(&result, deviceTag, fmt.Sprintf("SIM card is locked (%s); no ADB method can bypass the SIM PIN, enter it on the device", preflight.SIMStatus))
+	}
+
+	// Kiosk mode keeps the system locked into one app, so settings changes have no visible effect
+	if kiosk, err := a.isInKioskMode(ctx, deviceSerial); err == nil && kiosk {
+		a.addWarning(&result, deviceTag, "Device is in kiosk mode (screen pinning), unpinning before disabling the lock screen")
+		a.markStep(deviceSerial, "Unpin task")
+		if !a.unpinTask(ctx, deviceSerial) && ctx.Err() == nil {
+			a.addWarning(&result, deviceTag, "Task could not be unpinned, lock screen changes may have no effect until it is")
+		}
+	}
+
 	// Check if device has existing lock screen configured
-	hasLock, lockType := a.CheckExistingLockScreen(deviceSerial)
-	if !hasLock {
-		a.log(fmt.Sprintf("%s No lock screen detected on device. Skipping lock screen disable process.", deviceTag), "ℹ️")
-		a.log(fmt.Sprintf("%s Device is already unlocked or has no lock configured", deviceTag), "✅")
+	a.markStep(deviceSerial, "Detect existing lock screen")
+	lockInfo, err := a.checkExistingLockScreen(ctx, deviceSerial)
+	if err != nil {
+		a.addWarning(&result, deviceTag, fmt.Sprintf("Could not detect lock screen, attempting to disable anyway: %v", err))
+	}
+	result.LockDetected = lockInfo.HasLock()
+	if lockInfo.HasLock() {
+		result.LockType = lockInfo.Type
+	}
+	if !lockInfo.HasLock() {
+		a.log(LogLevelInfo, fmt.Sprintf("%s No lock screen detected on device. Skipping lock screen disable process.", deviceTag), "ℹ️", "device", deviceSerial)
+		a.log(LogLevelInfo, fmt.Sprintf("%s Device is already unlocked or has no lock configured", deviceTag), "✅", "device", deviceSerial)
+		result.Success = true
 		stats.IncrementSuccess()
 		return
 	}
 
-	a.log(fmt.Sprintf("%s Lock screen detected: %s", deviceTag, lockType), "🔒")
-	a.log(fmt.Sprintf("%s Proceeding with lock screen disable process...", deviceTag), "🚀")
+	a.log(LogLevelDebug, fmt.Sprintf("%s Lock screen detected: %s (%s)", deviceTag, lockInfo.Type, lockInfo.Description), "🔒", "device", deviceSerial)
+	a.log(LogLevelInfo, fmt.Sprintf("%s Proceeding with lock screen disable process...", deviceTag), "🚀", "device", deviceSerial)
 
-	// Try each method until one succeeds
-	methods := []func(string) bool{
-		a.disableLockscreenMethod1,
-		a.disableLockscreenMethod2,
-		a.disableLockscreenMethod3,
-		a.disableLockscreenMethod4,
+	// An admin password policy re-enables the lock after reboot, so remove the admins first
+	if lockInfo.Type == LockTypeAdmin {
+		a.markStep(deviceSerial, "Remove device admins")
+		admins, err := a.getDeviceAdminApps(ctx, deviceSerial)
+		if err != nil {
+			a.addWarning(&result, deviceTag, fmt.Sprintf("Could not list device admins: %v", err))
+		}
+		for _, admin := range admins {
+			if !a.removeDeviceAdmin(ctx, deviceSerial, admin) {
+				a.addWarning(&result, deviceTag, fmt.Sprintf("Device admin %s could not be removed, the lock screen may return after reboot", admin))
+			}
+		}
 	}
 
-	success := false
-	for i, method := range methods {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					a.log(fmt.Sprintf("%s Method %d crashed: %v", deviceTag, i+1, r), "💥")
-				}
-			}()
+	if deviceInfo.IsLowMemory() {
+		a.log(LogLevelDebug, fmt.Sprintf("%s Low-memory device detected (%d kB RAM)", deviceTag, deviceInfo.TotalMemoryKB), "🧠", "device", deviceSerial)
+	}
 
-			if method(deviceSerial) {
-				success = true
-				return
-			}
-			time.Sleep(1 * time.Second) // Brief pause between methods
-		}()
+	a.screenshotStep(ctx, &result, deviceTag, "before")
 
-		if success {
-			break
+	if a.cfg.settingsDiff {
+		a.markStep(deviceSerial, "Dump settings")
+		if settingsBefore, err = a.dumpAllSettings(ctx, deviceSerial); err != nil {
+			a.addWarning(&result, deviceTag, fmt.Sprintf("Could not dump settings before processing: %v", err))
+		}
+	}
+
+	var snapshot *SettingsSnapshot
+	if a.cfg.autoRestore {
+		a.markStep(deviceSerial, "Back up settings")
+		if snapshot, err = a.backupDeviceSettings(ctx, deviceSerial); err != nil {
+			a.addWarning(&result, deviceTag, fmt.Sprintf("Could not back up settings, they will not be restored on failure: %v", err))
 		}
 	}
 
+	// Try each method until one succeeds
+	result.MethodResults = a.disableLockScreen(ctx, deviceSerial, deviceInfo)
+	a.recordTelemetry(deviceInfo, result.MethodResults, correlationID)
+	success := false
+	attempted := 0
+	for _, method := range result.MethodResults {
+		if !method.Attempted {
+			continue
+		}
+		attempted++
+		stats.RecordMethodAttempt(method.MethodIndex, method.Success)
+		// With WithAttemptAllMethods several methods may succeed; the first one is reported
+		if method.Success && !success {
+			result.MethodUsed = method.MethodIndex
+			success = true
+		}
+	}
+
+	if !success && ctx.Err() != nil {
+		a.cancelDevice(ctx, &result, stats, deviceTag)
+		return
+	}
+
 	if !success {
-		a.log(fmt.Sprintf("%s All methods failed", deviceTag), "😞")
+		a.log(LogLevelError, fmt.Sprintf("%s All methods failed", deviceTag), "😞", "device", deviceSerial)
+		if snapshot != nil {
+			a.markStep(deviceSerial, "Restore settings")
+			if err := a.restoreDeviceSettings(ctx, deviceSerial, snapshot); err != nil {
+				a.addWarning(&result, deviceTag, fmt.Sprintf("Could not restore original settings: %v", err))
+			}
+		}
+		result.Error = "all methods failed"
+		result.Err = ErrAllMethodsFailed
+		if attempted == 0 {
+			result.Error = fmt.Sprintf("no method supports API level %d", deviceInfo.SDKInt)
+			result.Err = fmt.Errorf("%w: %s", ErrAllMethodsFailed, result.Error)
+		}
 		stats.AddFailedDevice(deviceSerial)
 		return
 	}
 
+	// A dismissed keyguard comes back on the next reboot, so there is nothing more to apply
+	if !a.cfg.persistentDisable {
+		a.log(LogLevelInfo, fmt.Sprintf("%s Lock screen dismissed until the next reboot", deviceTag), "🔓", "device", deviceSerial)
+		a.screenshotStep(ctx, &result, deviceTag, "after")
+		result.Success = true
+		stats.IncrementSuccess()
+		return
+	}
+
+	if a.cfg.allUsers {
+		// Secondary users include work profiles, so this also covers WithDisableWorkProfile
+		a.markStep(deviceSerial, "Disable lock screen of secondary users")
+		users, err := a.listAndroidUsers(ctx, deviceSerial)
+		if err != nil {
+			a.addWarning(&result, deviceTag, fmt.Sprintf("Could not list users: %v", err))
+		}
+		for _, user := range users {
+			if user.ID != 0 && !a.disableLockScreenForUser(ctx, deviceSerial, user.ID) {
+				a.addWarning(&result, deviceTag, fmt.Sprintf("Lock screen of user %d (%s) could not be disabled", user.ID, user.Name))
+			}
+		}
+	} else if a.cfg.disableWorkProfile && a.hasWorkProfile(ctx, deviceSerial) {
+		a.markStep(deviceSerial, "Disable work profile lock")
+		if !a.disableWorkProfileLock(ctx, deviceSerial) {
+			a.addWarning(&result, deviceTag, "Work profile lock could not be disabled")
+		}
+	}
+
+	// Some methods take effect right away on recent devices, which saves the reboot and the wait for it
+	if a.cfg.skipVerifiedReboot && mayApplyWithoutReboot(result.MethodUsed, deviceInfo.SDKInt) {
+		a.markStep(deviceSerial, "Verify lock screen removal without reboot")
+		if a.verifyWithoutReboot(ctx, deviceSerial) {
+			a.log(LogLevelInfo, fmt.Sprintf("%s Lock screen removed without a reboot! 🎉", deviceTag), "🎊", "device", deviceSerial)
+			a.screenshotStep(ctx, &result, deviceTag, "after")
+			result.Success = true
+			stats.IncrementSuccess()
+			return
+		}
+		if ctx.Err() != nil {
+			a.cancelDevice(ctx, &result, stats, deviceTag)
+			return
+		}
+		a.log(LogLevelDebug, fmt.Sprintf("%s Lock screen still present, rebooting", deviceTag), "🔄", "device", deviceSerial)
+	}
+
 	// Wait a moment for settings to take effect
-	time.Sleep(2 * time.Second)
+	if !sleepContext(ctx, a.cfg.preRebootDelay) {
+		a.cancelDevice(ctx, &result, stats, deviceTag)
+		return
+	}
 
 	// Reboot the device to apply changes
-	a.log(fmt.Sprintf("%s Rebooting device to apply lock screen changes...", deviceTag), "🔄")
+	a.log(LogLevelInfo, fmt.Sprintf("%s Rebooting device to apply lock screen changes...", deviceTag), "🔄", "device", deviceSerial)
+	a.markStep(deviceSerial, "Reboot device")
 
-	if !a.RebootDevice(deviceSerial) {
-		a.log(fmt.Sprintf("%s Failed to reboot device, but lock screen settings were applied", deviceTag), "⚠️")
+	if err := a.rebootDevice(ctx, deviceSerial); err != nil {
+		a.addWarning(&result, deviceTag, "Failed to reboot device, but lock screen settings were applied")
+		result.Success = true
 		stats.IncrementSuccess()
 		return
 	}
 
-	// Wait for device to be ready after reboot (max 5 minutes)
-	a.log(fmt.Sprintf("%s Waiting for device to be ready after reboot (up to 5 minutes)...", deviceTag), "⏳")
-	if !a.WaitForDeviceReady(deviceSerial, 5) {
-		a.log(fmt.Sprintf("%s Device did not become ready within 5 minutes after reboot", deviceTag), "⏰")
+	// Wait for device to be ready after reboot
+	a.log(LogLevelInfo, fmt.Sprintf("%s Waiting for device to be ready after reboot (up to %s)...", deviceTag, a.cfg.rebootWaitTimeout), "⏳", "device", deviceSerial)
+	a.markStep(deviceSerial, "Wait for device after reboot")
+	if err := a.waitForDeviceReady(ctx, deviceSerial, a.cfg.rebootWaitTimeout); err != nil {
+		if ctx.Err() != nil {
+			a.cancelDevice(ctx, &result, stats, deviceTag)
+			return
+		}
+		a.log(LogLevelError, fmt.Sprintf("%s Device did not become ready within %s after reboot", deviceTag, a.cfg.rebootWaitTimeout), "⏰", "device", deviceSerial)
+		result.Error = "device not ready after reboot"
+		result.Err = err
 		stats.AddFailedDevice(deviceSerial)
 		return
 	}
 
 	// Validate that lock screen has been removed
-	if a.ValidateLockScreenRemoval(deviceSerial) {
-		a.log(fmt.Sprintf("%s Successfully disabled and validated lock screen removal! 🎉", deviceTag), "🎊")
+	a.markStep(deviceSerial, "Validate lock screen removal")
+	validationErr := a.validateLockScreenRemoval(ctx, deviceSerial)
+	a.screenshotStep(ctx, &result, deviceTag, "after")
+	if validationErr == nil {
+		a.log(LogLevelInfo, fmt.Sprintf("%s Successfully disabled and validated lock screen removal! 🎉", deviceTag), "🎊", "device", deviceSerial)
+		result.Success = true
 		stats.IncrementSuccess()
 	} else {
-		a.log(fmt.Sprintf("%s Lock screen settings were applied, but validation failed after reboot", deviceTag), "⚠️")
+		a.addWarning(&result, deviceTag, "Lock screen settings were applied, but validation failed after reboot")
 		// Still count as success since we successfully applied the settings
+		result.Success = true
 		stats.IncrementSuccess()
 	}
 }
 
-// ProcessDevices processes multiple devices concurrently and returns processing statistics
-func (a *AndroidLockScreenDisabler) ProcessDevices(devices []string) (int, []string, int) {
+// rejectDevice records why a blocking preflight issue stops a device from being processed
+func (a *AndroidLockScreenDisabler) rejectDevice(result *DeviceResult, stats *ProcessingStats, deviceTag string, preflight *PreflightResult) {
+	issue, _ := preflight.BlockingIssue()
+	result.Error = issue.Message
+	result.Err = issue.Err
+	switch issue.Check {
+	case PreflightAPILevel:
+		a.log(LogLevelWarn, fmt.Sprintf("%s Skipping device: %s", deviceTag, issue.Message), "⏭️", "device", result.Serial)
+		result.Status = StatusSkipped
+		return
+	case PreflightDeviceOwner, PreflightProfileOwner:
+		a.log(LogLevelWarn, fmt.Sprintf("%s Device is %s, skipping. Use WithIgnoreMDM to try anyway.", deviceTag, issue.Message), "🏢", "device", result.Serial)
+		result.Status = StatusSkipped
+		return
+	case PreflightAuthorization:
+		if preflight.AuthStatus == AuthStatusOffline {
+			a.log(LogLevelError, fmt.Sprintf("%s Device is offline. Reconnect the USB cable or restart ADB.", deviceTag), "🔌", "device", result.Serial)
+			stats.AddOfflineDevice(result.Serial)
+			break
+		}
+		a.log(LogLevelError, fmt.Sprintf("%s Device is unauthorized. "+
+			"Check the device screen and accept the \"Allow USB debugging\" dialog.", deviceTag), "🔑", "device", result.Serial)
+		stats.AddUnauthorizedDevice(result.Serial)
+	case PreflightFRP:
+		a.log(LogLevelError, fmt.Sprintf("%s Factory Reset Protection is active. "+
+			"Sign in with the Google account previously synced to the device to finish setup, then run dlock again.", deviceTag), "🛑", "device", result.Serial)
+		stats.AddFRPDevice(result.Serial)
+	default:
+		a.log(LogLevelError, fmt.Sprintf("%s Insufficient permissions. "+
+			"Make sure USB debugging is enabled and device is authorized.", deviceTag), "❌", "device", result.Serial)
+	}
+	stats.AddFailedDevice(result.Serial)
+}
+
+// apiLevelMismatch returns why a device is outside the configured API level range, or "" if it is not.
+// Devices whose API level is unknown are not skipped.
+func (a *AndroidLockScreenDisabler) apiLevelMismatch(deviceInfo DeviceInfo) string {
+	if deviceInfo.SDKInt == 0 {
+		return ""
+	}
+	if a.cfg.minAPILevel > 0 && deviceInfo.SDKInt < a.cfg.minAPILevel {
+		return fmt.Sprintf("API level %d is below the minimum %d", deviceInfo.SDKInt, a.cfg.minAPILevel)
+	}
+	if a.cfg.maxAPILevel > 0 && deviceInfo.SDKInt > a.cfg.maxAPILevel {
+		return fmt.Sprintf("API level %d is above the maximum %d", deviceInfo.SDKInt, a.cfg.maxAPILevel)
+	}
+	return ""
+}
+
+// runPostUnlockSteps performs the optional steps configured to run after a successful unlock.
+// Dry runs skip them all, since post-unlock commands are arbitrary and may change device state.
+func (a *AndroidLockScreenDisabler) runPostUnlockSteps(ctx context.Context, deviceSerial, deviceTag string, result *DeviceResult) {
+	if a.cfg.dryRun {
+		a.log(LogLevelInfo, fmt.Sprintf("%s [DRY-RUN] Skipping post-unlock steps", deviceTag), "📝", "device", deviceSerial)
+		return
+	}
+
+	if a.cfg.syncTime {
+		if err := a.syncDeviceTime(ctx, deviceSerial, time.Now()); err != nil {
+			a.addWarning(result, deviceTag, fmt.Sprintf("Could not synchronise device clock: %v", err))
+		}
+	}
+
+	if a.cfg.disableAnimations && !a.setAnimationScale(ctx, deviceSerial, "0") {
+		a.addWarning(result, deviceTag, "Could not disable animations")
+	}
+
+	for _, command := range a.cfg.postUnlockCommands {
+		a.log(LogLevelDebug, fmt.Sprintf("%s Running post-unlock command: %s", deviceTag, command), "🛠️", "device", deviceSerial)
+		if success, _, errorMsg := a.runADBCommand(ctx, command, deviceSerial); !success {
+			a.addWarning(result, deviceTag, fmt.Sprintf("Post-unlock command %q failed: %s", command, errorMsg))
+		}
+	}
+}
+
+// ProcessDevices processes multiple devices concurrently and returns the result of every device;
+// use NewBatchSummary for aggregate counts. Cancelling ctx stops all in-flight devices and their ADB commands.
+func (a *AndroidLockScreenDisabler) ProcessDevices(ctx context.Context, devices []string) []DeviceResult {
+	var results []DeviceResult
+	stream, _ := a.ProcessDevicesStream(ctx, devices)
+	for result := range stream {
+		results = append(results, result)
+	}
+	return results
+}
+
+// ProcessDevicesStream processes multiple devices concurrently like ProcessDevices but returns immediately.
+// The result of each device is sent on the returned channel as soon as the device finishes, and the channel
+// is closed once every device is done. The channel is buffered for every device, so callers may stop reading early.
+// The returned handle cancels individual devices without cancelling the batch.
+func (a *AndroidLockScreenDisabler) ProcessDevicesStream(ctx context.Context, devices []string) (<-chan DeviceResult, *ProcessHandle) {
+	results := make(chan DeviceResult, len(devices))
+	stats := NewProcessingStats(len(devices))
+	stats.onResult = func(result DeviceResult) { results <- result }
+
+	go func() {
+		defer close(results)
+		if len(devices) == 0 {
+			return
+		}
+		a.processDevicesInto(ctx, devices, stats)
+	}()
+
+	return results, &ProcessHandle{stats: stats}
+}
+
+// ProcessDevicesWithReport processes multiple devices concurrently and returns a per-device report
+func (a *AndroidLockScreenDisabler) ProcessDevicesWithReport(ctx context.Context, devices []string) ProcessingReport {
 	if len(devices) == 0 {
-		return 0, nil, 0
+		return ProcessingReport{}
+	}
+
+	stats, interrupted := a.processDevices(ctx, devices)
+	return ProcessingReport{
+		Results:        stats.Results(),
+		WasInterrupted: interrupted,
 	}
+}
 
-	// Process each device concurrently
+// processDevices processes multiple devices concurrently and returns the collected statistics
+// along with whether cancellation or the processing deadline interrupted the batch
+func (a *AndroidLockScreenDisabler) processDevices(ctx context.Context, devices []string) (*ProcessingStats, bool) {
 	stats := NewProcessingStats(len(devices))
+	return stats, a.processDevicesInto(ctx, devices, stats)
+}
+
+// processDevicesInto processes multiple devices concurrently, recording their outcome in stats,
+// and reports whether cancellation or the processing deadline interrupted the batch
+func (a *AndroidLockScreenDisabler) processDevicesInto(ctx context.Context, devices []string, stats *ProcessingStats) bool {
+	if a.cfg.processingDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.cfg.processingDeadline)
+		defer cancel()
+	}
+
+	a.batchStats.Store(stats)
+	started := time.Now()
+
+	if a.cfg.progressInterval > 0 {
+		progressCtx, stopProgress := context.WithCancel(context.Background())
+		progressDone := make(chan struct{})
+		go func() {
+			defer close(progressDone)
+			a.reportProgress(progressCtx, stats)
+		}()
+		defer func() {
+			stopProgress()
+			<-progressDone
+		}()
+	}
+
+	// Fetch the info of every device upfront instead of one slot at a time
+	stats.deviceInfo = a.BatchGetDeviceInfo(ctx, a.prefetchedDevices(devices))
+
+	// Process the devices, then retry the failed ones in further passes if configured
+	pending := devices
+	for attempt := 1; ; attempt++ {
+		mayRetry := attempt <= a.cfg.autoRetryFailures
+		stats.beginPass(attempt, mayRetry)
+		a.processPass(ctx, pending, stats)
+		// Devices are retried because something about them changed, so fetch their info again
+		stats.deviceInfo = nil
+
+		failed := stats.takeHeldFailures()
+		if len(failed) == 0 || ctx.Err() != nil {
+			a.recordResults(stats, failed)
+			break
+		}
+
+		backoff := autoRetryBackoff << (attempt - 1)
+		a.log(LogLevelInfo, fmt.Sprintf("Retrying %d failed device(s) in %v (retry %d of %d)...", len(failed), backoff, attempt, a.cfg.autoRetryFailures), "🔁")
+		if !a.cfg.sleep(ctx, backoff) {
+			a.recordResults(stats, failed)
+			break
+		}
+
+		pending = make([]string, len(failed))
+		for i, result := range failed {
+			pending[i] = result.Serial
+		}
+		stats.forgetFailures(pending)
+	}
+
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		a.log(LogLevelWarn, fmt.Sprintf("Processing interrupted (%v), results are partial", ctx.Err()), "⏰")
+	}
+
+	a.notifyBatchComplete(stats, time.Since(started))
+	return interrupted
+}
+
+// prefetchedDevices returns the devices whose info is fetched before processing: all of them except the
+// emulators skipped by WithSkipEmulators
+func (a *AndroidLockScreenDisabler) prefetchedDevices(devices []string) []string {
+	if !a.cfg.skipEmulators {
+		return devices
+	}
+	var prefetched []string
+	for _, device := range devices {
+		if !IsEmulator(device) {
+			prefetched = append(prefetched, device)
+		}
+	}
+	return prefetched
+}
+
+// GetLiveStats retrieves a snapshot of the statistics of the most recently started batch or watch.
+// It may be polled from another goroutine while ProcessDevices, Run or Watch is running.
+func (a *AndroidLockScreenDisabler) GetLiveStats() LiveStats {
+	if stats := a.batchStats.Load(); stats != nil {
+		return stats.GetLiveStats()
+	}
+	return LiveStats{}
+}
+
+// processPass processes the devices concurrently, at most a.cfg.concurrency at a time
+func (a *AndroidLockScreenDisabler) processPass(ctx context.Context, devices []string, stats *ProcessingStats) {
+	slots := make(chan struct{}, a.cfg.concurrency)
 	var wg sync.WaitGroup
 
-	a.log(fmt.Sprintf("Processing %d device(s) concurrently...", len(devices)), "🚀")
-	a.log(strings.Repeat("-", 50), "")
+	a.log(LogLevelInfo, fmt.Sprintf("Processing %d device(s) concurrently (up to %d at a time)...", len(devices), a.cfg.concurrency), "🚀")
+	a.log(LogLevelInfo, strings.Repeat("-", 50), "")
 
-	// Start processing all devices in parallel
 	for _, device := range devices {
+		// Each device gets its own context so ProcessHandle.CancelDevice can cancel it alone
+		deviceCtx, cancel := context.WithCancel(ctx)
+		stats.deviceCancels.Store(device, &cancel)
+
 		wg.Add(1)
-		go a.DisableLockscreenOnDeviceAsync(device, stats, &wg)
+		go func(device string) {
+			defer cancel()
+			defer stats.deviceCancels.CompareAndDelete(device, &cancel)
+			a.processDeviceInSlot(deviceCtx, slots, device, stats, &wg)
+		}(device)
 	}
 
 	// Wait for all goroutines to complete
-	a.log("Waiting for all devices to complete processing...", "⏳")
+	a.log(LogLevelInfo, "Waiting for all devices to complete processing...", "⏳")
 	wg.Wait()
+}
 
-	// Get final statistics
-	return stats.GetStats()
+// recordResults records failed results that were held back for a retry that will not happen
+func (a *AndroidLockScreenDisabler) recordResults(stats *ProcessingStats, results []DeviceResult) {
+	for _, result := range results {
+		stats.AddResult(result)
+	}
+}
+
+// processDeviceInSlot waits for a free worker slot and then processes the device.
+// A device still waiting when ctx is done is recorded as cancelled without running any command.
+func (a *AndroidLockScreenDisabler) processDeviceInSlot(ctx context.Context, slots chan struct{}, deviceSerial string, stats *ProcessingStats, wg *sync.WaitGroup) {
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+	}
+
+	a.DisableLockscreenOnDeviceAsync(ctx, deviceSerial, stats, wg)
 }
 
 // Run is the main execution method for CLI usage
 func (a *AndroidLockScreenDisabler) Run() {
-	a.log("Android Lock Screen Disabler Starting...", "🚀")
-	a.log(strings.Repeat("=", 50), "")
+	a.RunContext(context.Background())
+}
+
+// RunContext is Run with a context that cancels processing when done.
+// It returns the result of every device, including those cancelled, so callers can persist partial runs.
+func (a *AndroidLockScreenDisabler) RunContext(ctx context.Context) ProcessingReport {
+	a.log(LogLevelInfo, "Android Lock Screen Disabler Starting...", "🚀")
+	a.log(LogLevelInfo, strings.Repeat("=", 50), "")
 
 	// Check ADB availability
-	if !a.CheckADBAvailability() {
-		a.log("Please install ADB and ensure it's in your PATH.", "💡")
-		return
+	if !a.checkADBAvailability(ctx) {
+		a.log(LogLevelInfo, "Please install ADB and ensure it's in your PATH.", "💡")
+		return ProcessingReport{WasInterrupted: ctx.Err() != nil, Err: ErrADBNotAvailable}
 	}
 
 	// Get connected devices
-	devices := a.GetConnectedDevices()
+	statuses, ok := a.getAllDeviceStatuses(ctx)
+	devices := []string{}
+	if ok {
+		devices = deviceSerials(a.selectConnectedDevices(ctx, statuses))
+	}
 	if len(devices) == 0 {
-		a.log("Please connect at least one Android device with USB debugging enabled.", "💡")
-		return
+		unusable := NewProcessingStats(0)
+		a.recordUnusableDevices(unusable, statuses)
+		a.logUnusableDevices(unusable)
+		a.log(LogLevelInfo, "Please connect at least one Android device with USB debugging enabled.", "💡")
+		return ProcessingReport{WasInterrupted: ctx.Err() != nil, Err: ErrNoDevices}
 	}
 
 	// Process all devices
-	successCount, failedDevices, totalDevices := a.ProcessDevices(devices)
+	stats, interrupted := a.processDevices(ctx, devices)
+	a.recordUnusableDevices(stats, statuses)
+	summary := NewBatchSummary(stats.Results())
 
 	// Summary
-	a.log("\n"+strings.Repeat("=", 50), "")
-	a.log("EXECUTION SUMMARY", "📊")
-	a.log(strings.Repeat("=", 50), "")
-	a.log(fmt.Sprintf("Total devices processed: %d", totalDevices), "📱")
-	a.log(fmt.Sprintf("Successfully disabled: %d", successCount), "✅")
-	a.log(fmt.Sprintf("Failed: %d", len(failedDevices)), "❌")
+	a.log(LogLevelInfo, "\n"+strings.Repeat("=", 50), "")
+	a.log(LogLevelInfo, "EXECUTION SUMMARY", "📊")
+	a.log(LogLevelInfo, strings.Repeat("=", 50), "")
+	a.log(LogLevelInfo, fmt.Sprintf("Total devices processed: %d", summary.TotalDevices), "📱")
+	a.log(LogLevelInfo, fmt.Sprintf("Successfully disabled: %d", summary.SuccessCount), "✅")
+	failedLevel := LogLevelInfo
+	if len(summary.FailedDevices) > 0 {
+		failedLevel = LogLevelWarn
+	}
+	a.log(failedLevel, fmt.Sprintf("Failed: %d", len(summary.FailedDevices)), "❌")
+	a.logUnusableDevices(stats)
+	if frp := stats.FRPDevices(); len(frp) > 0 {
+		a.log(LogLevelWarn, fmt.Sprintf("FRP locked: %d (%s)", len(frp), a.deviceNames(frp)), "🛑")
+	}
+	if summary.SkippedCount > 0 {
+		a.log(LogLevelInfo, fmt.Sprintf("Skipped: %d", summary.SkippedCount), "⏭️")
+	}
+	if interrupted {
+		a.log(LogLevelWarn, "Processing was interrupted before all devices finished", "⏰")
+	}
+
+	attempts := summary.MethodAttempts
+	successes := summary.MethodSuccesses
+	methodNumbers := make([]int, 0, len(attempts))
+	for method := range attempts {
+		methodNumbers = append(methodNumbers, method)
+	}
+	sort.Ints(methodNumbers)
+	for _, method := range methodNumbers {
+		a.log(LogLevelInfo, fmt.Sprintf("Method %d: attempted %d, succeeded %d", method, attempts[method], successes[method]), "🔧")
+	}
+
+	if len(summary.FailedDevices) > 0 {
+		a.log(LogLevelWarn, fmt.Sprintf("Failed devices: %s", a.deviceNames(summary.FailedDevices)), "⚠️")
+		a.log(LogLevelInfo, "\nTroubleshooting tips for failed devices:", "💡")
+		a.log(LogLevelInfo, "• Ensure USB debugging is enabled", "")
+		a.log(LogLevelInfo, "• Check if device requires authorization", "")
+		a.log(LogLevelInfo, "• Try enabling 'Settings > Developer Options > Disable permission monitoring'", "")
+		a.log(LogLevelInfo, "• Some devices may have policy restrictions", "")
+	}
+
+	if a.cfg.reportOutput != "" {
+		if err := GenerateHTMLReport(stats.Results(), a.cfg.reportOutput); err != nil {
+			a.log(LogLevelError, fmt.Sprintf("Failed to write HTML report: %v", err), "❌")
+		} else {
+			a.log(LogLevelInfo, fmt.Sprintf("HTML report written to %s", a.cfg.reportOutput), "📝")
+		}
+	}
 
-	if len(failedDevices) > 0 {
-		a.log(fmt.Sprintf("Failed devices: %s", strings.Join(failedDevices, ", ")), "⚠️")
-		a.log("\nTroubleshooting tips for failed devices:", "💡")
-		a.log("• Ensure USB debugging is enabled", "")
-		a.log("• Check if device requires authorization", "")
-		a.log("• Try enabling 'Settings > Developer Options > Disable permission monitoring'", "")
-		a.log("• Some devices may have policy restrictions", "")
+	if a.cfg.csvOutput != "" {
+		if err := WriteResultsCSVFile(stats.Results(), a.cfg.csvOutput); err != nil {
+			a.log(LogLevelError, fmt.Sprintf("Failed to write CSV results: %v", err), "❌")
+		} else {
+			a.log(LogLevelInfo, fmt.Sprintf("CSV results written to %s", a.cfg.csvOutput), "📝")
+		}
 	}
 
-	if successCount > 0 {
-		a.log(fmt.Sprintf("\n🎉 Successfully processed %d device(s)!", successCount), "🎊")
+	if summary.SuccessCount > 0 {
+		a.log(LogLevelInfo, fmt.Sprintf("\n🎉 Successfully processed %d device(s)!", summary.SuccessCount), "🎊")
 	}
 
-	a.log("\nScript completed!", "🏁")
+	a.log(LogLevelInfo, "\nScript completed!", "🏁")
+	return ProcessingReport{Results: stats.Results(), WasInterrupted: interrupted}
+}
+
+// recordUnusableDevices records the listed devices that are offline or unauthorized in stats,
+// restricted to the target devices if specified
+func (a *AndroidLockScreenDisabler) recordUnusableDevices(stats *ProcessingStats, statuses []DeviceStatus) {
+	targets := make(map[string]bool)
+	for _, device := range a.cfg.targetDevices {
+		targets[device] = true
+	}
+
+	for _, status := range statuses {
+		if len(targets) > 0 && !targets[status.Serial] {
+			continue
+		}
+		switch status.State {
+		case DeviceStateOffline:
+			stats.AddOfflineDevice(status.Serial)
+		case DeviceStateUnauthorized:
+			stats.AddUnauthorizedDevice(status.Serial)
+		}
+	}
+}
+
+// logUnusableDevices prints the offline and unauthorized devices with how to make them usable
+func (a *AndroidLockScreenDisabler) logUnusableDevices(stats *ProcessingStats) {
+	if offline := stats.OfflineDevices(); len(offline) > 0 {
+		a.log(LogLevelWarn, fmt.Sprintf("Offline: %d (%s)", len(offline), a.deviceNames(offline)), "🔌")
+		a.log(LogLevelInfo, "Reconnect the USB cable, or restart the ADB server with 'adb kill-server && adb start-server'", "💡")
+	}
+	if unauthorized := stats.UnauthorizedDevices(); len(unauthorized) > 0 {
+		a.log(LogLevelWarn, fmt.Sprintf("Unauthorized: %d (%s)", len(unauthorized), a.deviceNames(unauthorized)), "🔑")
+		a.log(LogLevelInfo, "Accept the \"Allow USB debugging\" dialog on the device screen, then run dlock again", "💡")
+	}
 }
 
 // ProcessSingleDevice processes a single device and returns success status
 func (a *AndroidLockScreenDisabler) ProcessSingleDevice(deviceSerial string) bool {
-	devices := []string{deviceSerial}
-	successCount, _, _ := a.ProcessDevices(devices)
-	return successCount > 0
+	results := a.ProcessDevices(context.Background(), []string{deviceSerial})
+	return len(results) > 0 && results[0].Success
 }
@@ -0,0 +1,219 @@
+package dlock
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunContextFailedSummaryLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		sdk        string
+		wantFailed string
+		wantLevel  string
+	}{
+		{"no failures", "30", "Failed: 0", "info"},
+		{"failures", "", "Failed: 1", "warn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			// The device is skipped for its API level when it is read, and fails preflight otherwise
+			a, _ := newMockDisabler(t, map[string]MockResponse{
+				"version":                            ok("Android Debug Bridge version 1.0.41"),
+				"devices":                            ok("List of devices attached\n" + testSerial + "\tdevice\n"),
+				"shell getprop ro.build.version.sdk": ok(tt.sdk),
+			}, WithLogging(true), WithLogger(NewJSONLogger(&buf)), WithMinAPILevel(99), WithAutoRestore(false))
+
+			a.RunContext(context.Background())
+
+			var found bool
+			for _, entry := range decodeJSONLines(t, buf.String()) {
+				if entry["message"] != tt.wantFailed {
+					continue
+				}
+				found = true
+				if entry["level"] != tt.wantLevel {
+					t.Errorf("%q logged at %v, want %s", tt.wantFailed, entry["level"], tt.wantLevel)
+				}
+			}
+			if !found {
+				t.Errorf("summary does not log %q:\n%s", tt.wantFailed, buf.String())
+			}
+		})
+	}
+}
+
+func TestProcessDevices(t *testing.T) {
+	a, _ := newMockDisabler(t, map[string]MockResponse{"shell getprop ro.build.version.sdk": ok("30")}, WithMinAPILevel(31))
+
+	devices := []string{"DEV1", "DEV2", "DEV3"}
+	results := a.ProcessDevices(context.Background(), devices)
+	if len(results) != len(devices) {
+		t.Fatalf("ProcessDevices() returned %d results, want %d", len(results), len(devices))
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range results {
+		seen[result.Serial] = true
+		if result.Status != StatusSkipped {
+			t.Errorf("result of %s status = %q, want %q", result.Serial, result.Status, StatusSkipped)
+		}
+	}
+	for _, device := range devices {
+		if !seen[device] {
+			t.Errorf("ProcessDevices() returned no result for %s", device)
+		}
+	}
+
+	if results := a.ProcessDevices(context.Background(), nil); len(results) != 0 {
+		t.Errorf("ProcessDevices(nil) = %v, want no results", results)
+	}
+}
+
+func TestProcessDevicesStream(t *testing.T) {
+	release := make(chan struct{})
+	mock := NewMockADBExecutor(nil)
+	executor := funcExecutor(func(ctx context.Context, args []string) (int, []byte, error) {
+		// Hold the permission check of SLOW, which runs once the device is being processed, until released
+		if strings.Join(args, " ") == "-s SLOW shell echo 'test'" {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return -1, nil, ctx.Err()
+			}
+		}
+		return mock.Execute(ctx, args)
+	})
+	a, _ := newMockDisabler(t, nil, WithADBExecutor(executor))
+
+	stream, handle := a.ProcessDevicesStream(context.Background(), []string{"SLOW", "FAST"})
+	if handle == nil {
+		t.Fatal("ProcessDevicesStream() returned no handle")
+	}
+
+	// FAST is reported while SLOW is still being processed
+	if result := <-stream; result.Serial != "FAST" {
+		t.Fatalf("first result is for %s, want FAST", result.Serial)
+	}
+	select {
+	case result := <-stream:
+		t.Fatalf("received result for %s before SLOW was released", result.Serial)
+	default:
+	}
+
+	close(release)
+	if result := <-stream; result.Serial != "SLOW" || result.Status != StatusFailed {
+		t.Errorf("second result = %s %q, want SLOW failed", result.Serial, result.Status)
+	}
+	if _, open := <-stream; open {
+		t.Error("stream is still open after every device finished")
+	}
+}
+
+func TestProcessDevicesStreamEmpty(t *testing.T) {
+	a, _ := newMockDisabler(t, nil)
+
+	stream, _ := a.ProcessDevicesStream(context.Background(), nil)
+	if _, open := <-stream; open {
+		t.Error("stream of no devices is open, want it closed")
+	}
+}
+
+// testHistory captures every result recorded through HistoryRecorder
+type testHistory struct {
+	mu      sync.Mutex
+	results []DeviceResult
+}
+
+func (h *testHistory) Record(result DeviceResult) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results = append(h.results, result)
+	return nil
+}
+
+func TestProcessDevicesRecordsOutcome(t *testing.T) {
+	// A device with a lock screen that Method 1 removes
+	responses := map[string]MockResponse{
+		"shell":                              ok(""),
+		"reboot":                             ok(""),
+		"wait-for-device":                    ok(""),
+		"get-state":                          ok(DeviceStateDevice),
+		"shell echo":                         ok("test"),
+		"shell settings list secure":         ok("lockscreen.disabled=0"),
+		"shell getprop ro.build.version.sdk": ok("30"),
+		"shell getprop sys.boot_completed":   ok("1"),
+		"shell settings get secure user_setup_complete": ok("1"),
+		"shell locksettings get-disabled":               ok("false"),
+		"shell dumpsys window policy":                   ok("keyguardShowing=false"),
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantHistory int
+		wantMarked  bool
+	}{
+		{"run", false, 1, true},
+		{"dry run", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := InMemoryStore()
+			history := &testHistory{}
+			a, _ := newMockDisabler(t, responses, WithDryRun(tt.dryRun), WithProcessedStore(store), WithHistory(history),
+				WithMethodRetryDelay(0), WithRebootSettleTime(0))
+
+			results := a.ProcessDevices(context.Background(), []string{testSerial})
+			if len(results) != 1 || results[0].Status != StatusSuccess {
+				t.Fatalf("ProcessDevices() = %+v, want one success", results)
+			}
+
+			if len(history.results) != tt.wantHistory {
+				t.Errorf("history recorded %d results, want %d", len(history.results), tt.wantHistory)
+			}
+			if marked, err := store.IsProcessed(testSerial); err != nil || marked != tt.wantMarked {
+				t.Errorf("IsProcessed() = %v, %v, want %v, nil", marked, err, tt.wantMarked)
+			}
+		})
+	}
+}
+
+func TestProcessDevicesAllUsersDryRun(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{
+		"shell":                              ok(""),
+		"wait-for-device":                    ok(""),
+		"get-state":                          ok(DeviceStateDevice),
+		"shell echo":                         ok("test"),
+		"shell settings list secure":         ok("lockscreen.disabled=0"),
+		"shell getprop ro.build.version.sdk": ok("30"),
+		"shell getprop sys.boot_completed":   ok("1"),
+		"shell settings get secure user_setup_complete": ok("1"),
+		"shell pm list users": ok("Users:\n" +
+			"\tUserInfo{0:Owner:c13} running\n" +
+			"\tUserInfo{10:Guest:404} running\n" +
+			"\tUserInfo{11:Work profile:1030} running\n"),
+	}, WithDryRun(true), WithAllUsers(true), WithDisableWorkProfile(true), WithMethodRetryDelay(0), WithRebootSettleTime(0))
+
+	results := a.ProcessDevices(context.Background(), []string{testSerial})
+	if len(results) != 1 || results[0].Status != StatusSuccess {
+		t.Fatalf("ProcessDevices() = %+v, want one success", results)
+	}
+
+	var listed bool
+	for _, call := range mock.Calls() {
+		if strings.Contains(call, "locksettings") && !strings.Contains(call, "locksettings get-") {
+			t.Errorf("dry run ran %q", call)
+		}
+		listed = listed || strings.Contains(call, "pm list users")
+	}
+	if !listed {
+		t.Error("dry run did not list the users, want the all-users path exercised")
+	}
+}
@@ -0,0 +1,100 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// credentialPatterns restrict credentials to characters that survive both the host and device shells
+var credentialPatterns = map[LockType]*regexp.Regexp{
+	LockTypePIN:      regexp.MustCompile(`^[0-9]{4,16}$`),
+	LockTypePassword: regexp.MustCompile(`^[A-Za-z0-9@._+-]{4,16}$`),
+	LockTypePattern:  regexp.MustCompile(`^[1-9]{4,9}$`),
+}
+
+// EnableLockScreen re-enables the lock screen, setting a credential for PIN, password and pattern locks.
+// Pattern credentials are the sequence of grid cells numbered 1-9, e.g. "1235789".
+func (a *AndroidLockScreenDisabler) EnableLockScreen(deviceSerial string, lockType LockType, credential string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Enabling %s lock screen on device %s...", lockType, a.deviceName(deviceSerial)), "🔒", "device", deviceSerial)
+
+	if lockType != LockTypeNone {
+		pattern, ok := credentialPatterns[lockType]
+		if !ok {
+			a.log(LogLevelError, fmt.Sprintf("Unsupported lock type %q", lockType), "❌")
+			return false
+		}
+		if !pattern.MatchString(credential) {
+			a.log(LogLevelError, fmt.Sprintf("Invalid %s credential for device %s", lockType, a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+			return false
+		}
+	}
+
+	// Try each method until one succeeds
+	methods := []func(context.Context, string, LockType, string) bool{
+		a.enableLockscreenMethod1,
+		a.enableLockscreenMethod2,
+		a.enableLockscreenMethod3,
+	}
+
+	for i, method := range methods {
+		if method(context.Background(), deviceSerial, lockType, credential) {
+			a.log(LogLevelInfo, fmt.Sprintf("Lock screen enabled on device %s (method %d)", a.deviceName(deviceSerial), i+1), "✅", "device", deviceSerial)
+			return true
+		}
+	}
+
+	a.log(LogLevelError, fmt.Sprintf("Failed to enable lock screen on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+	return false
+}
+
+// enableLockscreenMethod1 uses locksettings to re-enable the lock screen and set the credential
+func (a *AndroidLockScreenDisabler) enableLockscreenMethod1(ctx context.Context, deviceSerial string, lockType LockType, credential string) bool {
+	success, _, errorMsg := a.runADBCommand(ctx, "shell locksettings set-disabled false", deviceSerial)
+	if !success {
+		a.log(LogLevelDebug, fmt.Sprintf("Enable method 1 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+		return false
+	}
+
+	if lockType == LockTypeNone {
+		return true
+	}
+
+	success, _, errorMsg = a.runADBCommand(ctx, fmt.Sprintf("shell locksettings set-%s %s", lockType, credential), deviceSerial)
+	if !success {
+		a.log(LogLevelDebug, fmt.Sprintf("Enable method 1 could not set %s on device %s: %s", lockType, a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+		return false
+	}
+
+	return true
+}
+
+// enableLockscreenMethod2 reverts the settings secure flag (no credential support)
+func (a *AndroidLockScreenDisabler) enableLockscreenMethod2(ctx context.Context, deviceSerial string, lockType LockType, _ string) bool {
+	if lockType != LockTypeNone {
+		return false
+	}
+
+	success, _, errorMsg := a.runADBCommand(ctx, "shell settings put secure lockscreen.disabled 0", deviceSerial)
+	if !success {
+		a.log(LogLevelDebug, fmt.Sprintf("Enable method 2 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	}
+	return success
+}
+
+// enableLockscreenMethod3 reverts the system settings flag (no credential support)
+func (a *AndroidLockScreenDisabler) enableLockscreenMethod3(ctx context.Context, deviceSerial string, lockType LockType, _ string) bool {
+	if lockType != LockTypeNone {
+		return false
+	}
+
+	success, _, errorMsg := a.runADBCommand(ctx, "shell settings put system lockscreen_disabled 0", deviceSerial)
+	if !success {
+		a.log(LogLevelDebug, fmt.Sprintf("Enable method 3 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	}
+	return success
+}
@@ -0,0 +1,73 @@
+package dlock
+
+import "errors"
+
+// ErrNotSupported is returned when an operation is not supported on the host platform
+var ErrNotSupported = errors.New("operation not supported on this platform")
+
+// ErrRequiresCredential is returned when the lock screen cannot be dismissed without a PIN, pattern or password
+var ErrRequiresCredential = errors.New("lock screen requires a credential")
+
+// ErrInsufficientPermissions reports that the device shell or settings cannot be accessed over ADB
+var ErrInsufficientPermissions = errors.New("insufficient permissions")
+
+// ErrFRPActive reports that Factory Reset Protection blocks the device until the previous Google account signs in
+var ErrFRPActive = errors.New("factory reset protection is active")
+
+// ErrInvalidSerial reports a device serial containing characters outside the ADB serial character set
+var ErrInvalidSerial = errors.New("invalid device serial")
+
+// ErrEmptyFile reports a devices file that does not list any device serial
+var ErrEmptyFile = errors.New("file lists no device serials")
+
+// ErrInvalidProperty reports a system property name containing characters outside the getprop key character set
+var ErrInvalidProperty = errors.New("invalid property name")
+
+// ErrInvalidSetting reports a settings namespace other than secure, system and global, or a setting key
+// containing characters outside the settings key character set
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// ErrPropertyNotFound reports a system property that is not set on the device
+var ErrPropertyNotFound = errors.New("property not found")
+
+// ErrDeviceOffline reports a device that is offline or no longer connected
+var ErrDeviceOffline = errors.New("device offline")
+
+// ErrCommandFailed reports an ADB command that failed on a connected device
+var ErrCommandFailed = errors.New("command failed")
+
+// ErrScreenResolutionUnknown reports that the screen resolution of a device could not be read with `wm size`
+var ErrScreenResolutionUnknown = errors.New("screen resolution unknown")
+
+// ErrNoDevices reports that no usable Android device is connected
+var ErrNoDevices = errors.New("no devices connected")
+
+// ErrADBNotAvailable reports that the adb binary cannot be found or does not run
+var ErrADBNotAvailable = errors.New("adb not available")
+
+// ErrDeviceUnauthorized reports a device that has not accepted the "Allow USB debugging" dialog
+var ErrDeviceUnauthorized = errors.New("device unauthorized")
+
+// ErrPermissionDenied is ErrInsufficientPermissions, so either can be used with errors.Is
+var ErrPermissionDenied = ErrInsufficientPermissions
+
+// ErrCommandTimeout reports an ADB command that did not finish within its timeout
+var ErrCommandTimeout = errors.New("command timed out")
+
+// ErrAllMethodsFailed reports a device on which no disable method succeeded
+var ErrAllMethodsFailed = errors.New("all methods failed")
+
+// ErrRebootFailed reports a device that could not be rebooted
+var ErrRebootFailed = errors.New("reboot failed")
+
+// ErrDeviceNotReady reports a device that did not come back in time after a reboot
+var ErrDeviceNotReady = errors.New("device not ready")
+
+// ErrValidationFailed reports a lock screen that is still present, or whose status is unknown, after processing
+var ErrValidationFailed = errors.New("lock screen validation failed")
+
+// ErrMDMBlocked reports a device whose MDM owner keeps it from being processed
+var ErrMDMBlocked = errors.New("blocked by MDM")
+
+// ErrInvalidConfig reports an invalid option, config file or config value
+var ErrInvalidConfig = errors.New("invalid configuration")
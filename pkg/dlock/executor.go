@@ -0,0 +1,153 @@
+package dlock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ADBExecutor runs ADB invocations on behalf of the disabler
+type ADBExecutor interface {
+	// Execute runs adb with the given arguments and returns its exit code and combined output
+	Execute(ctx context.Context, args []string) (exitCode int, stdout []byte, err error)
+}
+
+// RealADBExecutor executes ADB commands through the host shell
+type RealADBExecutor struct {
+	Path string // adb binary to run, looked up in PATH when empty
+}
+
+// Execute runs adb with the given arguments using the host shell
+func (e RealADBExecutor) Execute(ctx context.Context, args []string) (int, []byte, error) {
+	cmd := e.command(ctx, args)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return exitErr.ExitCode(), output, nil
+	}
+	if err != nil {
+		return -1, output, err
+	}
+
+	return cmd.ProcessState.ExitCode(), output, nil
+}
+
+// ExecuteStdout runs adb with the given arguments using the host shell, returning only its standard output.
+// Diagnostics adb prints on standard error, such as "* daemon not running; starting now", are discarded.
+func (e RealADBExecutor) ExecuteStdout(ctx context.Context, args []string) (int, []byte, error) {
+	cmd := e.command(ctx, args)
+	output, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return exitErr.ExitCode(), output, nil
+	}
+	if err != nil {
+		return -1, output, err
+	}
+
+	return cmd.ProcessState.ExitCode(), output, nil
+}
+
+// ExecuteWithInput runs adb with the given arguments using the host shell, feeding input to its standard input
+func (e RealADBExecutor) ExecuteWithInput(ctx context.Context, args []string, input []byte) (int, []byte, error) {
+	cmd := e.command(ctx, args)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.CombinedOutput()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		return exitErr.ExitCode(), output, nil
+	}
+	if err != nil {
+		return -1, output, err
+	}
+
+	return cmd.ProcessState.ExitCode(), output, nil
+}
+
+// Stream starts a long-running adb command and returns its standard output as it is produced
+func (e RealADBExecutor) Stream(ctx context.Context, args []string) (io.ReadCloser, error) {
+	cmd := e.command(ctx, args)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &commandStream{ReadCloser: stdout, cmd: cmd}, nil
+}
+
+// command builds the host shell invocation of adb with the given arguments
+func (e RealADBExecutor) command(ctx context.Context, args []string) *exec.Cmd {
+	adbCommand := "adb"
+	if e.Path != "" {
+		adbCommand = `"` + e.Path + `"`
+	}
+	fullCommand := adbCommand + " " + strings.Join(args, " ")
+
+	// Use appropriate shell based on operating system
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/c", fullCommand)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", fullCommand)
+}
+
+// commandStream is the output of a streaming command; closing it stops the command
+type commandStream struct {
+	io.ReadCloser
+	cmd       *exec.Cmd
+	closeOnce sync.Once
+}
+
+// Close stops the command and releases its resources; it is safe to call more than once
+func (s *commandStream) Close() error {
+	s.closeOnce.Do(func() {
+		if s.cmd.Process != nil {
+			s.cmd.Process.Kill()
+		}
+		s.cmd.Wait()
+	})
+	return nil
+}
+
+// ADBStreamer is implemented by executors that can run long-lived adb commands such as track-devices
+type ADBStreamer interface {
+	// Stream starts adb with the given arguments and returns its output as it is produced
+	Stream(ctx context.Context, args []string) (io.ReadCloser, error)
+}
+
+// ADBInputExecutor is implemented by executors that can feed standard input to adb, which lets
+// RunShellCommands run many shell commands in a single adb shell session
+type ADBInputExecutor interface {
+	// ExecuteWithInput runs adb with the given arguments, writing input to its standard input
+	ExecuteWithInput(ctx context.Context, args []string, input []byte) (exitCode int, stdout []byte, err error)
+}
+
+// ADBStdoutExecutor is implemented by executors that can return the standard output of adb alone,
+// which keeps binary output such as `exec-out screencap -p` free of adb's diagnostics
+type ADBStdoutExecutor interface {
+	// ExecuteStdout runs adb with the given arguments and returns its exit code and standard output
+	ExecuteStdout(ctx context.Context, args []string) (exitCode int, stdout []byte, err error)
+}
+
+// stepMarker is implemented by executors that annotate the commands of each processing step
+type stepMarker interface {
+	MarkStep(deviceSerial, step string)
+}
+
+// markStep notifies the executor that a new processing step is starting on a device
+func (a *AndroidLockScreenDisabler) markStep(deviceSerial, step string) {
+	if marker, ok := a.cfg.executor.(stepMarker); ok {
+		marker.MarkStep(deviceSerial, step)
+	}
+}
@@ -0,0 +1,141 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DeviceFilter decides whether a device is processed, see FilterDevices and WithDeviceFilters
+type DeviceFilter interface {
+	Filter(ctx context.Context, disabler *AndroidLockScreenDisabler, serial string) (bool, error)
+}
+
+// DeviceFilterFunc adapts a function to a DeviceFilter
+type DeviceFilterFunc func(ctx context.Context, disabler *AndroidLockScreenDisabler, serial string) (bool, error)
+
+// Filter implements DeviceFilter
+func (f DeviceFilterFunc) Filter(ctx context.Context, disabler *AndroidLockScreenDisabler, serial string) (bool, error) {
+	return f(ctx, disabler, serial)
+}
+
+// deviceInfoFilter is implemented by filters that read the device info, which FilterDevices runs last
+type deviceInfoFilter interface {
+	readsDeviceInfo() bool
+}
+
+// infoFilter is a built-in filter on the device info
+type infoFilter func(info DeviceInfo) bool
+
+// Filter implements DeviceFilter
+func (f infoFilter) Filter(ctx context.Context, disabler *AndroidLockScreenDisabler, serial string) (bool, error) {
+	if err := disabler.checkSerial(serial); err != nil {
+		return false, err
+	}
+	return f(disabler.filterDeviceInfo(ctx, serial)), nil
+}
+
+// readsDeviceInfo implements deviceInfoFilter
+func (f infoFilter) readsDeviceInfo() bool {
+	return true
+}
+
+// ManufacturerFilter selects devices made by any of manufacturers, compared case-insensitively
+func ManufacturerFilter(manufacturers ...string) DeviceFilter {
+	return infoFilter(func(info DeviceInfo) bool {
+		return containsFold(manufacturers, info.Manufacturer)
+	})
+}
+
+// ModelFilter selects devices of any of models, compared case-insensitively
+func ModelFilter(models ...string) DeviceFilter {
+	return infoFilter(func(info DeviceInfo) bool {
+		return containsFold(models, info.Model)
+	})
+}
+
+// APILevelFilter selects devices whose API level is between min and max inclusive; 0 leaves a bound open.
+// Devices whose API level cannot be read are not selected.
+func APILevelFilter(min, max int) DeviceFilter {
+	return infoFilter(func(info DeviceInfo) bool {
+		return info.SDKInt > 0 && (min == 0 || info.SDKInt >= min) && (max == 0 || info.SDKInt <= max)
+	})
+}
+
+// SerialPrefixFilter selects devices whose serial starts with any of prefixes
+func SerialPrefixFilter(prefixes ...string) DeviceFilter {
+	return DeviceFilterFunc(func(ctx context.Context, disabler *AndroidLockScreenDisabler, serial string) (bool, error) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(serial, prefix) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// containsFold reports whether values contains value, ignoring case and surrounding spaces
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceInfoCacheKey is the context key of the device info read while filtering devices
+type deviceInfoCacheKey struct{}
+
+// filterDeviceInfo returns the device info, reading it at most once per FilterDevices call
+func (a *AndroidLockScreenDisabler) filterDeviceInfo(ctx context.Context, serial string) DeviceInfo {
+	cache, ok := ctx.Value(deviceInfoCacheKey{}).(*sync.Map)
+	if !ok {
+		return a.getDeviceInfo(ctx, serial)
+	}
+	if info, ok := cache.Load(serial); ok {
+		return info.(DeviceInfo)
+	}
+	info := a.getDeviceInfo(ctx, serial)
+	cache.Store(serial, info)
+	return info
+}
+
+// FilterDevices returns the devices every filter selects, in their original order. Filters that do not need
+// the device info run first, and the device info read by the built-in filters is read once per device.
+// It stops at the first filter error.
+func (a *AndroidLockScreenDisabler) FilterDevices(ctx context.Context, devices []string, filters ...DeviceFilter) ([]string, error) {
+	ordered := make([]DeviceFilter, len(filters))
+	copy(ordered, filters)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return !readsDeviceInfo(ordered[i]) && readsDeviceInfo(ordered[j])
+	})
+
+	ctx = context.WithValue(ctx, deviceInfoCacheKey{}, &sync.Map{})
+	selected := make([]string, 0, len(devices))
+	for _, serial := range devices {
+		keep := true
+		for _, filter := range ordered {
+			ok, err := filter.Filter(ctx, a, serial)
+			if err != nil {
+				return nil, fmt.Errorf("failed to filter device %s: %w", serial, err)
+			}
+			if !ok {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, serial)
+		}
+	}
+	return selected, nil
+}
+
+// readsDeviceInfo reports whether filter reads the device info
+func readsDeviceInfo(filter DeviceFilter) bool {
+	f, ok := filter.(deviceInfoFilter)
+	return ok && f.readsDeviceInfo()
+}
@@ -0,0 +1,143 @@
+// Package history keeps a queryable SQLite history of processed devices for fleet analytics
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gifflet/dlock/pkg/dlock"
+
+	_ "modernc.org/sqlite" // Registers the "sqlite" database/sql driver
+)
+
+// migrations creates and upgrades the schema, one entry per schema version.
+// Entries are never changed once released; new schema changes are appended.
+var migrations = []string{
+	`CREATE TABLE device_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		serial TEXT NOT NULL,
+		manufacturer TEXT NOT NULL,
+		model TEXT NOT NULL,
+		api_level TEXT NOT NULL,
+		success INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		method_used INTEGER NOT NULL,
+		start_time INTEGER NOT NULL,
+		duration INTEGER NOT NULL,
+		error TEXT NOT NULL,
+		correlation_id TEXT NOT NULL,
+		result TEXT NOT NULL
+	);
+	CREATE INDEX device_history_serial ON device_history (serial, start_time);
+	CREATE INDEX device_history_start_time ON device_history (start_time);`,
+}
+
+// History records every device result in a SQLite database. It is safe for concurrent use.
+type History struct {
+	db *sql.DB
+}
+
+// NewHistory opens the SQLite database at dbPath, creating it if needed, and migrates it to the latest schema
+func NewHistory(dbPath string) (*History, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	// SQLite allows a single writer, so concurrent devices share one connection instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate history database %s: %w", dbPath, err)
+	}
+	return &History{db: db}, nil
+}
+
+// migrate applies the migrations newer than the schema version stored in the database
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (h *History) Close() error {
+	return h.db.Close()
+}
+
+// Record inserts a row for result. It implements dlock.HistoryRecorder, see dlock.WithHistory.
+func (h *History) Record(result dlock.DeviceResult) error {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.db.Exec(`INSERT INTO device_history
+		(serial, manufacturer, model, api_level, success, status, method_used, start_time, duration, error, correlation_id, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.Serial, result.Manufacturer, result.Model, result.APILevel, result.Success, string(result.Status),
+		result.MethodUsed, result.StartTime.UnixNano(), int64(result.Duration), result.Error, result.CorrelationID, string(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to record history of %s: %w", result.Serial, err)
+	}
+	return nil
+}
+
+// QueryBySerial returns every result recorded for a device, oldest first
+func (h *History) QueryBySerial(serial string) ([]dlock.DeviceResult, error) {
+	return h.query("SELECT result FROM device_history WHERE serial = ? ORDER BY start_time, id", serial)
+}
+
+// QueryByTimeRange returns every result of devices whose processing started between start and end inclusive, oldest first
+func (h *History) QueryByTimeRange(start, end time.Time) ([]dlock.DeviceResult, error) {
+	return h.query("SELECT result FROM device_history WHERE start_time BETWEEN ? AND ? ORDER BY start_time, id",
+		start.UnixNano(), end.UnixNano())
+}
+
+// query decodes the results selected by a query returning the result column
+func (h *History) query(query string, args ...interface{}) ([]dlock.DeviceResult, error) {
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var results []dlock.DeviceResult
+	for rows.Next() {
+		var encoded string
+		if err := rows.Scan(&encoded); err != nil {
+			return nil, fmt.Errorf("failed to query history: %w", err)
+		}
+		var result dlock.DeviceResult
+		if err := json.Unmarshal([]byte(encoded), &result); err != nil {
+			return nil, fmt.Errorf("failed to decode history: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	return results, nil
+}
@@ -0,0 +1,310 @@
+// Package httpserver exposes an AndroidLockScreenDisabler as a JSON HTTP API
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gifflet/dlock/pkg/dlock"
+)
+
+// JobStatus describes the progress of an asynchronous job
+type JobStatus string
+
+// Job statuses
+const (
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+)
+
+// Job tracks a disable request started with POST /devices/{serial}/disable
+type Job struct {
+	ID         string              `json:"id"`
+	Serial     string              `json:"serial"`
+	Status     JobStatus           `json:"status"`
+	CreatedAt  time.Time           `json:"created_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Result     *dlock.DeviceResult `json:"result,omitempty"` // Set once the job is done
+}
+
+// DeviceInfo is the JSON form of dlock.DeviceInfo
+type DeviceInfo struct {
+	Serial         string `json:"serial"`
+	Model          string `json:"model"`
+	Manufacturer   string `json:"manufacturer"`
+	AndroidVersion string `json:"android_version"`
+	APILevel       string `json:"api_level"`
+	USBPath        string `json:"usb_path,omitempty"`
+	TotalMemoryKB  int64  `json:"total_memory_kb,omitempty"`
+}
+
+// LockStatus reports the lock screen state of a device
+type LockStatus struct {
+	Serial          string         `json:"serial"`
+	KeyguardShowing bool           `json:"keyguard_showing"`
+	LockType        dlock.LockType `json:"lock_type"`
+	Description     string         `json:"description,omitempty"`
+}
+
+// finishedJobTTL is how long a finished job can still be polled before it is forgotten
+const finishedJobTTL = time.Hour
+
+// Server serves the dlock JSON API and runs the jobs it starts in the background
+type Server struct {
+	disabler *dlock.AndroidLockScreenDisabler
+	mux      *http.ServeMux
+
+	// Jobs run under ctx, which Shutdown cancels before waiting for them
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	running map[string]string // Serial to the ID of its running job
+	jobTTL  time.Duration
+}
+
+// NewServer creates a Server serving the dlock JSON API:
+//
+//	GET  /health                   ADB availability
+//	GET  /devices                  connected devices
+//	POST /devices/{serial}/disable start disabling the lock screen, returns 202 and a job, or 409 while
+//	                               a job for the device is still running
+//	GET  /devices/{serial}/status  lock screen status
+//	GET  /devices/{serial}/info    device information
+//	GET  /jobs/{id}                job progress and result, kept for an hour after the job finishes
+//
+// Call Shutdown once the HTTP server has stopped to cancel the running jobs and wait for them.
+func NewServer(disabler *dlock.AndroidLockScreenDisabler) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		disabler: disabler,
+		mux:      http.NewServeMux(),
+		ctx:      ctx,
+		cancel:   cancel,
+		jobs:     make(map[string]*Job),
+		running:  make(map[string]string),
+		jobTTL:   finishedJobTTL,
+	}
+
+	s.mux.HandleFunc("GET /health", s.health)
+	s.mux.HandleFunc("GET /devices", s.listDevices)
+	s.mux.HandleFunc("POST /devices/{serial}/disable", s.disable)
+	s.mux.HandleFunc("GET /devices/{serial}/status", s.status)
+	s.mux.HandleFunc("GET /devices/{serial}/info", s.info)
+	s.mux.HandleFunc("GET /jobs/{id}", s.job)
+	return s
+}
+
+// Handler returns an http.Handler serving the dlock JSON API; see NewServer for the routes.
+// Its jobs cannot be cancelled, so servers that shut down gracefully should use NewServer.
+func Handler(disabler *dlock.AndroidLockScreenDisabler) http.Handler {
+	return NewServer(disabler)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Shutdown cancels the running jobs, which stop their devices as cancelled, and waits for them to finish
+// or for ctx to be done. Disable requests are rejected with 503 from then on.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// health reports whether ADB is available
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	available := s.disabler.CheckADBAvailability()
+
+	code := http.StatusOK
+	if !available {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]bool{"adb_available": available})
+}
+
+// listDevices lists the serials of the connected devices
+func (s *Server) listDevices(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]string{"devices": s.disabler.GetConnectedDevices()})
+}
+
+// disable starts disabling the lock screen of a device in the background and returns the job tracking it
+func (s *Server) disable(w http.ResponseWriter, r *http.Request) {
+	serial, ok := s.connectedSerial(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create job: "+err.Error())
+		return
+	}
+
+	job := &Job{ID: id, Serial: serial, Status: JobRunning, CreatedAt: time.Now()}
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+	if runningID, ok := s.running[serial]; ok {
+		s.mu.Unlock()
+		w.Header().Set("Location", "/jobs/"+runningID)
+		writeError(w, http.StatusConflict, "a job is already running for device "+serial)
+		return
+	}
+	s.pruneJobs()
+	s.jobs[id] = job
+	s.running[serial] = id
+	snapshot := *job
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go s.runDisable(job)
+
+	w.Header().Set("Location", "/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, snapshot)
+}
+
+// runDisable processes the job's device and records the result
+func (s *Server) runDisable(job *Job) {
+	defer s.wg.Done()
+	results := s.disabler.ProcessDevices(s.ctx, []string{job.Serial})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	finishedAt := time.Now()
+	job.Status = JobDone
+	job.FinishedAt = &finishedAt
+	if len(results) > 0 {
+		job.Result = &results[0]
+	}
+	delete(s.running, job.Serial)
+}
+
+// pruneJobs forgets the jobs that finished more than jobTTL ago; the caller must hold mu
+func (s *Server) pruneJobs() {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) >= s.jobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// status reports whether the keyguard is showing and which lock is configured
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	serial, ok := s.connectedSerial(w, r)
+	if !ok {
+		return
+	}
+
+	showing, err := s.disabler.CheckLockScreenStatus(serial)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	lockInfo, err := s.disabler.CheckExistingLockScreen(serial)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, LockStatus{
+		Serial:          serial,
+		KeyguardShowing: showing,
+		LockType:        lockInfo.Type,
+		Description:     lockInfo.Description,
+	})
+}
+
+// info returns the model, manufacturer and Android version of a device
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	serial, ok := s.connectedSerial(w, r)
+	if !ok {
+		return
+	}
+
+	info := s.disabler.GetDeviceInfo(serial)
+	writeJSON(w, http.StatusOK, DeviceInfo{
+		Serial:         serial,
+		Model:          info.Model,
+		Manufacturer:   info.Manufacturer,
+		AndroidVersion: info.AndroidVersion,
+		APILevel:       info.APILevel,
+		USBPath:        info.USBPath,
+		TotalMemoryKB:  info.TotalMemoryKB,
+	})
+}
+
+// job returns the progress of a job and, once done, its result
+func (s *Server) job(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.pruneJobs()
+	job, ok := s.jobs[r.PathValue("id")]
+	var snapshot Job
+	if ok {
+		snapshot = *job
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// connectedSerial returns the {serial} path value, writing a 404 response if the device is not connected
+func (s *Server) connectedSerial(w http.ResponseWriter, r *http.Request) (string, bool) {
+	serial := r.PathValue("serial")
+	if !slices.Contains(s.disabler.GetConnectedDevices(), serial) {
+		writeError(w, http.StatusNotFound, "device not connected: "+serial)
+		return "", false
+	}
+	return serial, true
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSON writes v as the JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"error": message})
+}
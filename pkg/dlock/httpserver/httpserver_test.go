@@ -0,0 +1,170 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gifflet/dlock/pkg/dlock"
+)
+
+// testSerial is the only device connected in these tests
+const testSerial = "DEV1"
+
+// devicesResponse lists testSerial as connected
+var devicesResponse = dlock.MockResponse{Stdout: "List of devices attached\n" + testSerial + "\tdevice\n"}
+
+// funcExecutor adapts a function to dlock.ADBExecutor
+type funcExecutor func(ctx context.Context, args []string) (int, []byte, error)
+
+func (f funcExecutor) Execute(ctx context.Context, args []string) (int, []byte, error) {
+	return f(ctx, args)
+}
+
+// newTestServer creates a Server whose disabler runs its ADB commands with executor
+func newTestServer(t *testing.T, executor dlock.ADBExecutor, opts ...dlock.Option) *Server {
+	t.Helper()
+
+	disabler, err := dlock.New(append([]dlock.Option{dlock.WithADBExecutor(executor), dlock.WithLogging(false)}, opts...)...)
+	if err != nil {
+		t.Fatalf("dlock.New() error = %v", err)
+	}
+	s := NewServer(disabler)
+	t.Cleanup(func() { s.Shutdown(context.Background()) })
+	return s
+}
+
+// serve sends a request to s and returns the recorded response
+func serve(s *Server, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+// decodeJob decodes the job in the body of a response
+func decodeJob(t *testing.T, rec *httptest.ResponseRecorder) Job {
+	t.Helper()
+
+	var job Job
+	if err := json.NewDecoder(rec.Body).Decode(&job); err != nil {
+		t.Fatalf("invalid job %q: %v", rec.Body.String(), err)
+	}
+	return job
+}
+
+// pollJob polls a job at path until it is done
+func pollJob(t *testing.T, s *Server, path string) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		rec := serve(s, http.MethodGet, path)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+		if job := decodeJob(t, rec); job.Status == JobDone {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s is still running", path)
+	return Job{}
+}
+
+func TestDisableJob(t *testing.T) {
+	// The device is below the minimum API level, so the job finishes right away with the device skipped
+	s := newTestServer(t, dlock.NewMockADBExecutor(map[string]dlock.MockResponse{
+		"devices":                            devicesResponse,
+		"shell getprop ro.build.version.sdk": {Stdout: "30"},
+	}), dlock.WithMinAPILevel(99))
+
+	rec := serve(s, http.MethodPost, "/devices/"+testSerial+"/disable")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST disable = %d, want %d: %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	job := decodeJob(t, rec)
+	if job.ID == "" || job.Serial != testSerial || job.Status != JobRunning {
+		t.Errorf("POST disable job = %+v, want a running job for %s", job, testSerial)
+	}
+	location := rec.Header().Get("Location")
+	if location != "/jobs/"+job.ID {
+		t.Fatalf("POST disable Location = %q, want /jobs/%s", location, job.ID)
+	}
+
+	done := pollJob(t, s, location)
+	if done.FinishedAt == nil || done.Result == nil || done.Result.Status != dlock.StatusSkipped {
+		t.Errorf("finished job = %+v, want a skipped result", done)
+	}
+
+	// Finished jobs are forgotten once their TTL expires
+	s.mu.Lock()
+	s.jobTTL = 0
+	s.mu.Unlock()
+	if rec := serve(s, http.MethodGet, location); rec.Code != http.StatusNotFound {
+		t.Errorf("GET expired job = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	s := newTestServer(t, dlock.NewMockADBExecutor(map[string]dlock.MockResponse{"devices": devicesResponse}))
+
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodPost, "/devices/UNKNOWN/disable"},
+		{http.MethodGet, "/devices/UNKNOWN/info"},
+		{http.MethodGet, "/jobs/0123456789abcdef"},
+	} {
+		if rec := serve(s, tt.method, tt.path); rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestDisableConflictAndShutdown(t *testing.T) {
+	// Every command on the device blocks until the job is cancelled
+	mock := dlock.NewMockADBExecutor(map[string]dlock.MockResponse{"devices": devicesResponse})
+	started := make(chan struct{}, 1)
+	s := newTestServer(t, funcExecutor(func(ctx context.Context, args []string) (int, []byte, error) {
+		if len(args) > 1 && args[0] == "-s" {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			return -1, nil, ctx.Err()
+		}
+		return mock.Execute(ctx, args)
+	}))
+
+	first := serve(s, http.MethodPost, "/devices/"+testSerial+"/disable")
+	if first.Code != http.StatusAccepted {
+		t.Fatalf("first POST disable = %d, want %d", first.Code, http.StatusAccepted)
+	}
+	<-started
+
+	second := serve(s, http.MethodPost, "/devices/"+testSerial+"/disable")
+	if second.Code != http.StatusConflict {
+		t.Errorf("second POST disable = %d, want %d", second.Code, http.StatusConflict)
+	}
+	if location := second.Header().Get("Location"); location != first.Header().Get("Location") {
+		t.Errorf("second POST disable Location = %q, want the running job %q", location, first.Header().Get("Location"))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// Shutdown waited for the job, which recorded the device as cancelled
+	rec := serve(s, http.MethodGet, first.Header().Get("Location"))
+	if job := decodeJob(t, rec); job.Status != JobDone || job.Result == nil || job.Result.Status != dlock.StatusCancelled {
+		t.Errorf("job after Shutdown = %+v, want done with a cancelled result", job)
+	}
+
+	if rec := serve(s, http.MethodPost, "/devices/"+testSerial+"/disable"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("POST disable after Shutdown = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
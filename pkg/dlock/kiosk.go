@@ -0,0 +1,54 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IsInKioskMode reports whether the device is locked into a single app through screen pinning or lock task mode
+func (a *AndroidLockScreenDisabler) IsInKioskMode(serial string) (bool, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return false, err
+	}
+	return a.isInKioskMode(context.Background(), serial)
+}
+
+// isInKioskMode implements IsInKioskMode, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) isInKioskMode(ctx context.Context, serial string) (bool, error) {
+	activitiesOK, activities, errorMsg := a.runADBCommand(ctx, "shell dumpsys activity activities", serial)
+	if activitiesOK {
+		for _, line := range strings.Split(activities, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if !strings.HasPrefix(trimmed, "mLockTaskModeState=") {
+				continue
+			}
+			if state := strings.TrimPrefix(trimmed, "mLockTaskModeState="); state != "NONE" {
+				return true, nil
+			}
+		}
+	}
+
+	packagesOK, packages, _ := a.runADBCommand(ctx, "shell settings get secure lock_task_packages", serial)
+	if packagesOK && packages != "" && packages != "null" {
+		return true, nil
+	}
+
+	if !activitiesOK && !packagesOK {
+		return false, fmt.Errorf("failed to read lock task state on device %s: %s", serial, errorMsg)
+	}
+	return false, nil
+}
+
+// unpinTask ends screen pinning with `am task lock stop`; some devices only allow this as root
+func (a *AndroidLockScreenDisabler) unpinTask(ctx context.Context, serial string) bool {
+	success, _, errorMsg := a.runADBCommand(ctx, "shell am task lock stop", serial)
+	if !success {
+		a.log(LogLevelWarn, fmt.Sprintf("Failed to unpin task on device %s: %s", a.deviceName(serial), errorMsg), "❌", "device", serial)
+		return false
+	}
+
+	// Give the launcher time to come back before touching settings
+	return sleepContext(ctx, 2*time.Second)
+}
@@ -0,0 +1,292 @@
+package dlock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota // Progress detail such as device detection and method attempts
+	LogLevelInfo                  // Milestones and summaries
+	LogLevelWarn                  // Partial failures the run recovers from
+	LogLevelError                 // Terminal errors for a device or the run
+)
+
+// String returns the lowercase level name
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// ParseLogLevel converts a level name such as "debug" or "warn" into a LogLevel
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "warn", "warning":
+		return LogLevelWarn, nil
+	case "error":
+		return LogLevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", name)
+	}
+}
+
+// Logger receives the disabler's log messages.
+// kvs are alternating key/value pairs such as "device", "ABC123".
+type Logger interface {
+	Debug(msg string, kvs ...interface{})
+	Info(msg string, kvs ...interface{})
+	Warn(msg string, kvs ...interface{})
+	Error(msg string, kvs ...interface{})
+}
+
+// emojiKey is the key carrying the emoji prefix of a message; structured loggers drop it
+const emojiKey = "emoji"
+
+// emojiLogger prints messages prefixed with their emoji, the default human-readable output
+type emojiLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	color bool // Color errors red, warnings yellow and successes green
+}
+
+// DefaultLogger creates the default human-readable logger, printing each message prefixed with its emoji to w
+func DefaultLogger(w io.Writer) Logger {
+	return &emojiLogger{w: w}
+}
+
+// Debug implements Logger
+func (l *emojiLogger) Debug(msg string, kvs ...interface{}) { l.write(LogLevelDebug, msg, kvs) }
+
+// Info implements Logger
+func (l *emojiLogger) Info(msg string, kvs ...interface{}) { l.write(LogLevelInfo, msg, kvs) }
+
+// Warn implements Logger
+func (l *emojiLogger) Warn(msg string, kvs ...interface{}) { l.write(LogLevelWarn, msg, kvs) }
+
+// Error implements Logger
+func (l *emojiLogger) Error(msg string, kvs ...interface{}) { l.write(LogLevelError, msg, kvs) }
+
+// write prints a single message (thread-safe)
+func (l *emojiLogger) write(level LogLevel, msg string, kvs []interface{}) {
+	emoji := "ℹ️"
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if key, ok := kvs[i].(string); ok && key == emojiKey {
+			if value, ok := kvs[i+1].(string); ok && value != "" {
+				emoji = value
+			}
+		}
+	}
+
+	if l.color {
+		if code := ansiColor(level, emoji); code != "" {
+			msg = code + msg + ansiReset
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, "%s %s\n", emoji, msg)
+}
+
+// ANSI escape sequences used by the colored emoji output
+const (
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiReset  = "\x1b[0m"
+)
+
+// successEmojis mark the info messages reporting a success
+var successEmojis = map[string]bool{"✅": true, "🎉": true, "🎊": true}
+
+// ansiColor returns the color of a message, or "" to leave it uncolored
+func ansiColor(level LogLevel, emoji string) string {
+	switch {
+	case level == LogLevelError:
+		return ansiRed
+	case level == LogLevelWarn:
+		return ansiYellow
+	case successEmojis[emoji]:
+		return ansiGreen
+	default:
+		return ""
+	}
+}
+
+// colorSupported reports whether w is a terminal that renders ANSI colors, honouring NO_COLOR (https://no-color.org/).
+// Windows consoles only render them when the terminal says so, as Windows Terminal, ConEmu and terminals setting TERM do.
+func colorSupported(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		return os.Getenv("WT_SESSION") != "" || os.Getenv("ConEmuANSI") == "ON" || os.Getenv("TERM") != ""
+	}
+	return os.Getenv("TERM") != "dumb"
+}
+
+// JSONLogger writes one JSON object per message for machine-readable pipelines
+type JSONLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLogger creates a JSONLogger writing to w (os.Stdout when nil)
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &JSONLogger{w: w}
+}
+
+// Debug implements Logger
+func (l *JSONLogger) Debug(msg string, kvs ...interface{}) { l.write("debug", msg, kvs) }
+
+// Info implements Logger
+func (l *JSONLogger) Info(msg string, kvs ...interface{}) { l.write("info", msg, kvs) }
+
+// Warn implements Logger
+func (l *JSONLogger) Warn(msg string, kvs ...interface{}) { l.write("warn", msg, kvs) }
+
+// Error implements Logger
+func (l *JSONLogger) Error(msg string, kvs ...interface{}) { l.write("error", msg, kvs) }
+
+// write encodes a single message as a JSON line (thread-safe)
+func (l *JSONLogger) write(level, msg string, kvs []interface{}) {
+	entry := map[string]interface{}{
+		"level":   level,
+		"message": msg,
+		"ts":      time.Now().UTC().Format(time.RFC3339Nano),
+	}
+
+	for i := 0; i+1 < len(kvs); i += 2 {
+		key, ok := kvs[i].(string)
+		if !ok || key == emojiKey {
+			continue
+		}
+		if err, ok := kvs[i+1].(error); ok {
+			entry[key] = err.Error()
+			continue
+		}
+		entry[key] = kvs[i+1]
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]string{"level": "error", "message": fmt.Sprintf("failed to encode log entry: %v", err)})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w.Write(append(line, '\n'))
+}
+
+// withoutEmoji returns the key/value pairs without the emoji prefix, which structured loggers have no use for
+func withoutEmoji(kvs []interface{}) []interface{} {
+	filtered := make([]interface{}, 0, len(kvs))
+	for i := 0; i < len(kvs); i += 2 {
+		if key, ok := kvs[i].(string); ok && key == emojiKey {
+			continue
+		}
+		filtered = append(filtered, kvs[i:min(i+2, len(kvs))]...)
+	}
+	return filtered
+}
+
+// slogAdapter forwards log messages to a *slog.Logger
+type slogAdapter struct {
+	l *slog.Logger
+}
+
+// SlogAdapter sends the disabler's log messages to l, with the key/value pairs as attributes
+func SlogAdapter(l *slog.Logger) Logger {
+	return slogAdapter{l: l}
+}
+
+// Debug implements Logger
+func (a slogAdapter) Debug(msg string, kvs ...interface{}) { a.write(slog.LevelDebug, msg, kvs) }
+
+// Info implements Logger
+func (a slogAdapter) Info(msg string, kvs ...interface{}) { a.write(slog.LevelInfo, msg, kvs) }
+
+// Warn implements Logger
+func (a slogAdapter) Warn(msg string, kvs ...interface{}) { a.write(slog.LevelWarn, msg, kvs) }
+
+// Error implements Logger
+func (a slogAdapter) Error(msg string, kvs ...interface{}) { a.write(slog.LevelError, msg, kvs) }
+
+// write logs a single message at level
+func (a slogAdapter) write(level slog.Level, msg string, kvs []interface{}) {
+	a.l.Log(context.Background(), level, msg, withoutEmoji(kvs)...)
+}
+
+// logrusAdapter forwards log messages to a *logrus.Logger
+type logrusAdapter struct {
+	l *logrus.Logger
+}
+
+// LogrusAdapter sends the disabler's log messages to l, with the key/value pairs as fields
+func LogrusAdapter(l *logrus.Logger) Logger {
+	return logrusAdapter{l: l}
+}
+
+// Debug implements Logger
+func (a logrusAdapter) Debug(msg string, kvs ...interface{}) { a.entry(kvs).Debug(msg) }
+
+// Info implements Logger
+func (a logrusAdapter) Info(msg string, kvs ...interface{}) { a.entry(kvs).Info(msg) }
+
+// Warn implements Logger
+func (a logrusAdapter) Warn(msg string, kvs ...interface{}) { a.entry(kvs).Warn(msg) }
+
+// Error implements Logger
+func (a logrusAdapter) Error(msg string, kvs ...interface{}) { a.entry(kvs).Error(msg) }
+
+// entry converts the key/value pairs into logrus fields
+func (a logrusAdapter) entry(kvs []interface{}) *logrus.Entry {
+	fields := make(logrus.Fields)
+	kvs = withoutEmoji(kvs)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if key, ok := kvs[i].(string); ok {
+			fields[key] = kvs[i+1]
+		}
+	}
+	return a.l.WithFields(fields)
+}
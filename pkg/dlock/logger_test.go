@@ -0,0 +1,230 @@
+package dlock
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// testEntry is a message captured by testLogger
+type testEntry struct {
+	level string
+	msg   string
+	kvs   []interface{}
+}
+
+// testLogger captures every message it receives
+type testLogger struct {
+	mu      sync.Mutex
+	entries []testEntry
+}
+
+func (l *testLogger) Debug(msg string, kvs ...interface{}) { l.add("debug", msg, kvs) }
+func (l *testLogger) Info(msg string, kvs ...interface{})  { l.add("info", msg, kvs) }
+func (l *testLogger) Warn(msg string, kvs ...interface{})  { l.add("warn", msg, kvs) }
+func (l *testLogger) Error(msg string, kvs ...interface{}) { l.add("error", msg, kvs) }
+
+func (l *testLogger) add(level, msg string, kvs []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, testEntry{level: level, msg: msg, kvs: kvs})
+}
+
+// value returns the value of key in the key/value pairs of an entry
+func (e testEntry) value(key string) interface{} {
+	for i := 0; i+1 < len(e.kvs); i += 2 {
+		if e.kvs[i] == key {
+			return e.kvs[i+1]
+		}
+	}
+	return nil
+}
+
+// decodeJSONLines decodes every line written by a JSONLogger, failing the test on invalid JSON
+func decodeJSONLines(t *testing.T, output string) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf)
+
+	logger.Debug("checking", emojiKey, "🔍", "device", testSerial)
+	logger.Info("done", "device", testSerial, "count", 3)
+	logger.Warn("retrying", "error", errors.New("exit status 1"))
+	logger.Error("failed \"quoted\"\nnewline")
+
+	entries := decodeJSONLines(t, buf.String())
+	if len(entries) != 4 {
+		t.Fatalf("JSONLogger wrote %d lines, want 4", len(entries))
+	}
+
+	wantLevels := []string{"debug", "info", "warn", "error"}
+	for i, entry := range entries {
+		if entry["level"] != wantLevels[i] {
+			t.Errorf("entry %d level = %v, want %s", i, entry["level"], wantLevels[i])
+		}
+		ts, ok := entry["ts"].(string)
+		if !ok {
+			t.Errorf("entry %d has no ts", i)
+		} else if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+			t.Errorf("entry %d ts = %q, want RFC 3339: %v", i, ts, err)
+		}
+		if _, ok := entry[emojiKey]; ok {
+			t.Errorf("entry %d carries the emoji key", i)
+		}
+	}
+
+	if entries[0]["message"] != "checking" || entries[0]["device"] != testSerial {
+		t.Errorf("entry 0 = %v, want message and device", entries[0])
+	}
+	if entries[1]["count"] != float64(3) {
+		t.Errorf("entry 1 count = %v, want 3", entries[1]["count"])
+	}
+	if entries[2]["error"] != "exit status 1" {
+		t.Errorf("entry 2 error = %v, want the error message", entries[2]["error"])
+	}
+	if entries[3]["message"] != "failed \"quoted\"\nnewline" {
+		t.Errorf("entry 3 message = %v, want it escaped intact", entries[3]["message"])
+	}
+}
+
+func TestJSONLoggerCallSites(t *testing.T) {
+	var buf bytes.Buffer
+	a, _ := newMockDisabler(t, map[string]MockResponse{
+		"shell echo":                 ok("test"),
+		"shell settings list secure": ok("lockscreen.disabled=0"),
+	}, WithLogging(true), WithLogLevel(LogLevelDebug), WithLogger(NewJSONLogger(&buf)))
+
+	if err := a.CheckDevicePermissions(testSerial); err != nil {
+		t.Fatalf("CheckDevicePermissions() error = %v", err)
+	}
+	a.CheckDevicePermissions("bad serial")
+
+	entries := decodeJSONLines(t, buf.String())
+	want := []struct {
+		level   string
+		message string
+		device  string
+	}{
+		{"debug", "Checking permissions for device " + testSerial, testSerial},
+		{"debug", "Device " + testSerial + " has necessary permissions", testSerial},
+		{"warn", "Rejected device serial", ""},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("logged %d lines, want %d:\n%s", len(entries), len(want), buf.String())
+	}
+	for i, w := range want {
+		entry := entries[i]
+		if entry["level"] != w.level || !strings.HasPrefix(entry["message"].(string), w.message) {
+			t.Errorf("entry %d = %v, want %s %q", i, entry, w.level, w.message)
+		}
+		if device, _ := entry["device"].(string); device != w.device {
+			t.Errorf("entry %d device = %q, want %q", i, device, w.device)
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &testLogger{}
+	a, _ := newMockDisabler(t, nil,
+		WithLogging(true), WithLogLevel(LogLevelInfo), WithLogger(logger), WithCorrelationID("run-1"))
+
+	a.log(LogLevelDebug, "filtered out", "🔍")
+	a.log(LogLevelInfo, "processing", "🚀", "device", testSerial)
+	a.log(LogLevelWarn, "retrying", "⚠️", "device", testSerial)
+	a.log(LogLevelError, "failed", "❌")
+
+	if len(logger.entries) != 3 {
+		t.Fatalf("logger received %d messages, want 3: %+v", len(logger.entries), logger.entries)
+	}
+	for i, want := range []struct{ level, msg string }{{"info", "processing"}, {"warn", "retrying"}, {"error", "failed"}} {
+		entry := logger.entries[i]
+		if entry.level != want.level || entry.msg != "[run-1] "+want.msg {
+			t.Errorf("entry %d = %s %q, want %s %q", i, entry.level, entry.msg, want.level, "[run-1] "+want.msg)
+		}
+	}
+	if device := logger.entries[0].value("device"); device != testSerial {
+		t.Errorf("entry 0 device = %v, want %s", device, testSerial)
+	}
+	if emoji := logger.entries[0].value(emojiKey); emoji != "🚀" {
+		t.Errorf("entry 0 emoji = %v, want 🚀", emoji)
+	}
+}
+
+func TestDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := DefaultLogger(&buf)
+
+	logger.Info("done", emojiKey, "✅", "device", testSerial)
+	logger.Warn("no emoji")
+
+	if want := "✅ done\nℹ️ no emoji\n"; buf.String() != want {
+		t.Errorf("DefaultLogger wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSlogAdapter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := SlogAdapter(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	logger.Debug("checking", emojiKey, "🔍", "device", testSerial)
+	logger.Warn("retrying", "attempt", 2)
+
+	entries := decodeJSONLines(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("slog received %d records, want 2", len(entries))
+	}
+	if entries[0]["level"] != "DEBUG" || entries[0]["msg"] != "checking" || entries[0]["device"] != testSerial {
+		t.Errorf("record 0 = %v, want a debug record with the device", entries[0])
+	}
+	if _, ok := entries[0][emojiKey]; ok {
+		t.Errorf("record 0 carries the emoji key")
+	}
+	if entries[1]["level"] != "WARN" || entries[1]["attempt"] != float64(2) {
+		t.Errorf("record 1 = %v, want a warn record with the attempt", entries[1])
+	}
+}
+
+func TestLogrusAdapter(t *testing.T) {
+	var buf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&buf)
+	l.SetFormatter(&logrus.JSONFormatter{})
+	l.SetLevel(logrus.DebugLevel)
+	logger := LogrusAdapter(l)
+
+	logger.Info("processing", emojiKey, "🚀", "device", testSerial)
+	logger.Error("failed")
+
+	entries := decodeJSONLines(t, buf.String())
+	if len(entries) != 2 {
+		t.Fatalf("logrus received %d entries, want 2", len(entries))
+	}
+	if entries[0]["level"] != "info" || entries[0]["msg"] != "processing" || entries[0]["device"] != testSerial {
+		t.Errorf("entry 0 = %v, want an info entry with the device", entries[0])
+	}
+	if _, ok := entries[0][emojiKey]; ok {
+		t.Errorf("entry 0 carries the emoji key")
+	}
+	if entries[1]["level"] != "error" {
+		t.Errorf("entry 1 = %v, want an error entry", entries[1])
+	}
+}
@@ -1,66 +1,252 @@
 package dlock
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
+// disableMethodCount is the number of disable methods that persist across reboots
+const disableMethodCount = 5
+
+// dismissKeyguardMethod is the number of the transient method used when persistent disable is off
+const dismissKeyguardMethod = 6
+
+// dismissKeyguardMinSDK is the first API level (Android 8.0) whose wm supports dismiss-keyguard
+const dismissKeyguardMinSDK = 26
+
+// ManufacturerMethodMap maps a manufacturer name (case-insensitive) to the disable method numbers to try, in order
+type ManufacturerMethodMap map[string][]int
+
+// MethodDescriptor describes a disable method and the devices it can work on
+type MethodDescriptor struct {
+	Index        int    // Number of the method in logs, statistics and options such as WithForceMethod
+	Name         string // Name reported in MethodResult
+	MinAPILevel  int    // First API level the method works on, 0 if unbounded
+	MaxAPILevel  int    // Last API level the method works on, 0 if unbounded
+	RequiresRoot bool
+	DependsOn    []string // Names of methods that must be tried before this one, see RegisterMethod and WithMethods
+}
+
+// methodDescriptors describes the disable methods, indexed by method number minus one
+var methodDescriptors = []MethodDescriptor{
+	{Index: 1, Name: "locksettings", MinAPILevel: 24}, // locksettings set-disabled was added in Android 7.0
+	{Index: 2, Name: "settings secure", MinAPILevel: 17},
+	{Index: 3, Name: "system settings", MinAPILevel: 17},
+	{Index: 4, Name: "global settings", MinAPILevel: 17},
+	{Index: 5, Name: "root", MinAPILevel: 17, RequiresRoot: true},
+	{Index: dismissKeyguardMethod, Name: "dismiss keyguard", MinAPILevel: dismissKeyguardMinSDK},
+}
+
+// MethodDescriptors returns the descriptors of every built-in disable method, in method number order
+func MethodDescriptors() []MethodDescriptor {
+	descriptors := make([]MethodDescriptor, len(methodDescriptors))
+	copy(descriptors, methodDescriptors)
+	return descriptors
+}
+
+// SupportsAPILevel reports whether the method works on a device with the given API level.
+// An unknown API level (0) is assumed to be supported.
+func (d MethodDescriptor) SupportsAPILevel(apiLevel int) bool {
+	if apiLevel <= 0 {
+		return true
+	}
+	return (d.MinAPILevel == 0 || apiLevel >= d.MinAPILevel) && (d.MaxAPILevel == 0 || apiLevel <= d.MaxAPILevel)
+}
+
+// builtinMethodDescriptor returns the descriptor of the built-in method with the given name
+func builtinMethodDescriptor(name string) (MethodDescriptor, bool) {
+	for _, descriptor := range methodDescriptors {
+		if descriptor.Name == name {
+			return descriptor, true
+		}
+	}
+	return MethodDescriptor{}, false
+}
+
+// disableMethod pairs a disable method with its descriptor
+type disableMethod struct {
+	descriptor MethodDescriptor
+	run        func(context.Context, string) MethodResult
+}
+
+// disableMethods returns the methods to try on a device, in order
+func (a *AndroidLockScreenDisabler) disableMethods(deviceSerial string, deviceInfo DeviceInfo) []disableMethod {
+	// Only dismiss the keyguard when the lock screen should come back on the next reboot
+	if !a.cfg.persistentDisable {
+		return []disableMethod{a.builtinMethod(dismissKeyguardMethod, deviceInfo)}
+	}
+
+	// A configured method list replaces the built-in chain and the registered methods
+	if a.cfg.methodList != nil {
+		return a.configuredMethods(deviceInfo)
+	}
+
+	methods := make([]disableMethod, 0, disableMethodCount)
+	for index := 1; index <= disableMethodCount; index++ {
+		methods = append(methods, a.builtinMethod(index, deviceInfo))
+	}
+
+	// A forced method replaces the fallback chain
+	if a.cfg.forceMethod > 0 {
+		return methods[a.cfg.forceMethod-1 : a.cfg.forceMethod]
+	}
+
+	// The emulator does not enforce security policies, so locksettings always fails there
+	if IsEmulator(deviceSerial) {
+		return append(methods[1:], a.registeredMethods()...)
+	}
+
+	// Some manufacturers block the default order, so follow the configured one instead
+	if order, ok := a.cfg.manufacturerMethods[strings.ToLower(strings.TrimSpace(deviceInfo.Manufacturer))]; ok {
+		ordered := make([]disableMethod, 0, len(order))
+		for _, index := range order {
+			ordered = append(ordered, methods[index-1])
+		}
+		methods = ordered
+	}
+	return append(methods, a.registeredMethods()...)
+}
+
+// builtinMethod returns the built-in method with the given number
+func (a *AndroidLockScreenDisabler) builtinMethod(index int, deviceInfo DeviceInfo) disableMethod {
+	method := disableMethod{descriptor: methodDescriptors[index-1]}
+	switch index {
+	case 1:
+		method.run = a.disableLockscreenMethod1
+	case 2:
+		method.run = a.disableLockscreenMethod2
+		// Low-memory devices may lose settings writes, so use the pinned variant of Method 2
+		if deviceInfo.IsLowMemory() {
+			method.run = a.disableLockscreenMethodLowMemory
+		}
+	case 3:
+		method.run = a.disableLockscreenMethod3
+	case 4:
+		method.run = a.disableLockscreenMethod4
+	case 5:
+		method.run = a.disableLockscreenMethod5
+	default:
+		method.run = a.disableLockscreenMethod6
+	}
+	return method
+}
+
+// runDisableMethod runs a disable method, timing it and turning a panic into a failed result
+func (a *AndroidLockScreenDisabler) runDisableMethod(ctx context.Context, method disableMethod, deviceSerial string) (result MethodResult) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			a.log(LogLevelWarn, fmt.Sprintf("[%s] Method %d crashed: %v", a.deviceName(deviceSerial), method.descriptor.Index, r), "💥", "device", deviceSerial)
+			result.Success = false
+			result.ErrorMessage = fmt.Sprintf("panic: %v", r)
+		}
+		result.MethodIndex = method.descriptor.Index
+		result.Attempted = true
+		result.Duration = time.Since(start)
+	}()
+
+	return method.run(ctx, deviceSerial)
+}
+
 // disableLockscreenMethod1 uses locksettings command (Most compatible)
-func (a *AndroidLockScreenDisabler) disableLockscreenMethod1(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Trying Method 1 (locksettings) on device %s...", deviceSerial), "🔑")
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod1(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 1 (locksettings) on device %s...", a.deviceName(deviceSerial)), "🔑", "device", deviceSerial)
 
 	// First try to clear any existing lock
-	if success, _, _ := a.runADBCommand("shell locksettings clear", deviceSerial); success {
-		a.log(fmt.Sprintf("Cleared existing lock settings on %s", deviceSerial), "🧹")
+	if success, _, _ := a.runADBCommand(ctx, "shell locksettings clear", deviceSerial); success {
+		a.log(LogLevelDebug, fmt.Sprintf("Cleared existing lock settings on %s", a.deviceName(deviceSerial)), "🧹", "device", deviceSerial)
 	}
 
 	// Set lockscreen as disabled
-	success, _, errorMsg := a.runADBCommand("shell locksettings set-disabled true", deviceSerial)
+	result := MethodResult{MethodName: "locksettings", Command: "shell locksettings set-disabled true"}
+	success, _, errorMsg := a.runADBCommand(ctx, result.Command, deviceSerial)
 
 	if success {
-		a.log(fmt.Sprintf("Method 1 succeeded on device %s!", deviceSerial), "✅")
-		return true
+		a.log(LogLevelDebug, fmt.Sprintf("Method 1 succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
 	}
 
-	a.log(fmt.Sprintf("Method 1 failed on device %s: %s", deviceSerial, errorMsg), "❌")
-	return false
+	a.log(LogLevelDebug, fmt.Sprintf("Method 1 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	result.ErrorMessage = errorMsg
+	return result
 }
 
 // disableLockscreenMethod2 uses settings secure (Alternative approach)
-func (a *AndroidLockScreenDisabler) disableLockscreenMethod2(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Trying Method 2 (settings secure) on device %s...", deviceSerial), "⚙️")
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod2(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 2 (settings secure) on device %s...", a.deviceName(deviceSerial)), "⚙️", "device", deviceSerial)
 
 	// Set lockscreen.disabled to 1
-	success, _, errorMsg := a.runADBCommand("shell settings put secure lockscreen.disabled 1", deviceSerial)
+	result := MethodResult{MethodName: "settings secure", Command: "shell settings put secure lockscreen.disabled 1"}
+	success, _, errorMsg := a.runADBCommand(ctx, result.Command, deviceSerial)
 
 	if success {
-		a.log(fmt.Sprintf("Method 2 succeeded on device %s!", deviceSerial), "✅")
-		return true
+		a.log(LogLevelDebug, fmt.Sprintf("Method 2 succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("Method 2 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	result.ErrorMessage = errorMsg
+	return result
+}
+
+// disableLockscreenMethodLowMemory pins the settings provider before applying Method 2 (low-memory devices)
+func (a *AndroidLockScreenDisabler) disableLockscreenMethodLowMemory(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Low-Memory Method (pinned settings provider) on device %s...", a.deviceName(deviceSerial)), "🧠", "device", deviceSerial)
+
+	// Keep the settings provider in the foreground cpuset so it isn't OOM-killed mid-operation
+	a.runADBCommand(ctx, "shell 'echo com.android.providers.settings > /dev/cpuset/foreground/tasks 2>/dev/null'", deviceSerial)
+
+	result := a.disableLockscreenMethod2(ctx, deviceSerial)
+	result.MethodName = "low-memory settings secure"
+	if !result.Success {
+		a.log(LogLevelDebug, fmt.Sprintf("Low-Memory Method failed on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		return result
+	}
+
+	// A dry run did not write the setting, so there is nothing to read back
+	if a.cfg.dryRun {
+		return result
+	}
+
+	// Read the value back immediately to make sure it persisted
+	success, output, _ := a.runADBCommand(ctx, "shell settings get secure lockscreen.disabled", deviceSerial)
+	if success && output == "1" {
+		a.log(LogLevelDebug, fmt.Sprintf("Low-Memory Method succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		return result
 	}
 
-	a.log(fmt.Sprintf("Method 2 failed on device %s: %s", deviceSerial, errorMsg), "❌")
-	return false
+	a.log(LogLevelDebug, fmt.Sprintf("Low-Memory Method failed on device %s: setting did not persist", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+	result.Success = false
+	result.ErrorMessage = "setting did not persist"
+	return result
 }
 
 // disableLockscreenMethod3 uses system settings (Legacy compatibility)
-func (a *AndroidLockScreenDisabler) disableLockscreenMethod3(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Trying Method 3 (system settings) on device %s...", deviceSerial), "🔧")
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod3(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 3 (system settings) on device %s...", a.deviceName(deviceSerial)), "🔧", "device", deviceSerial)
 
 	// Set lockscreen_disabled in system settings
-	success, _, errorMsg := a.runADBCommand("shell settings put system lockscreen_disabled 1", deviceSerial)
+	result := MethodResult{MethodName: "system settings", Command: "shell settings put system lockscreen_disabled 1"}
+	success, _, errorMsg := a.runADBCommand(ctx, result.Command, deviceSerial)
 
 	if success {
-		a.log(fmt.Sprintf("Method 3 succeeded on device %s!", deviceSerial), "✅")
-		return true
+		a.log(LogLevelDebug, fmt.Sprintf("Method 3 succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
 	}
 
-	a.log(fmt.Sprintf("Method 3 failed on device %s: %s", deviceSerial, errorMsg), "❌")
-	return false
+	a.log(LogLevelDebug, fmt.Sprintf("Method 3 failed on device %s: %s", a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+	result.ErrorMessage = errorMsg
+	return result
 }
 
 // disableLockscreenMethod4 uses global settings approach
-func (a *AndroidLockScreenDisabler) disableLockscreenMethod4(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Trying Method 4 (global settings) on device %s...", deviceSerial), "🌐")
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod4(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 4 (global settings) on device %s...", a.deviceName(deviceSerial)), "🌐", "device", deviceSerial)
 
 	// Set device_provisioned and user_setup_complete
 	commands := []string{
@@ -68,46 +254,147 @@ func (a *AndroidLockScreenDisabler) disableLockscreenMethod4(deviceSerial string
 		"shell settings put secure user_setup_complete 1",
 	}
 
+	result := MethodResult{MethodName: "global settings", Command: strings.Join(commands, "; ")}
 	successCount := 0
 	for _, cmd := range commands {
-		if success, _, _ := a.runADBCommand(cmd, deviceSerial); success {
+		success, _, errorMsg := a.runADBCommand(ctx, cmd, deviceSerial)
+		if success {
 			successCount++
+			continue
 		}
+		result.ErrorMessage = fmt.Sprintf("%s: %s", cmd, errorMsg)
 	}
 
 	if successCount > 0 {
-		a.log(fmt.Sprintf("Method 4 partially succeeded on device %s!", deviceSerial), "✅")
-		return true
+		a.log(LogLevelDebug, fmt.Sprintf("Method 4 partially succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
 	}
 
-	a.log(fmt.Sprintf("Method 4 failed on device %s", deviceSerial), "❌")
-	return false
+	a.log(LogLevelDebug, fmt.Sprintf("Method 4 failed on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+	return result
 }
 
-// DisableLockScreen attempts to disable lock screen using all available methods
-func (a *AndroidLockScreenDisabler) DisableLockScreen(deviceSerial string) bool {
-	// Try each method until one succeeds
-	methods := []func(string) bool{
-		a.disableLockscreenMethod1,
-		a.disableLockscreenMethod2,
-		a.disableLockscreenMethod3,
-		a.disableLockscreenMethod4,
+// disableLockscreenMethod5 runs Method 2 and Method 1 as root through su (requires a rooted device)
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod5(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 5 (root) on device %s...", a.deviceName(deviceSerial)), "🦸", "device", deviceSerial)
+
+	// Double quotes keep the su argument together on the host, single quotes on the device
+	commands := []string{
+		`shell "su -c 'settings put secure lockscreen.disabled 1'"`,
+		`shell "su -c 'locksettings set-disabled true'"`,
+	}
+
+	result := MethodResult{MethodName: "root", Command: strings.Join(commands, "; ")}
+	if !a.getRootStatus(ctx, deviceSerial) {
+		a.log(LogLevelDebug, fmt.Sprintf("Method 5 skipped on device %s: root is not available", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		result.ErrorMessage = "root is not available"
+		return result
 	}
 
-	for i, method := range methods {
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					a.log(fmt.Sprintf("Method %d crashed: %v", i+1, r), "💥")
-				}
-			}()
+	successCount := 0
+	for _, cmd := range commands {
+		success, _, errorMsg := a.runADBCommand(ctx, cmd, deviceSerial)
+		if success {
+			successCount++
+			continue
+		}
+		result.ErrorMessage = fmt.Sprintf("%s: %s", cmd, errorMsg)
+	}
+
+	if successCount > 0 {
+		a.log(LogLevelDebug, fmt.Sprintf("Method 5 succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
+	}
 
-			if method(deviceSerial) {
-				return
-			}
-			time.Sleep(1 * time.Second) // Brief pause between methods
-		}()
+	a.log(LogLevelDebug, fmt.Sprintf("Method 5 failed on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+	return result
+}
+
+// disableLockscreenMethod6 dismisses the keyguard with `wm dismiss-keyguard` without changing any setting.
+// The lock screen returns on the next reboot. Requires Android 8.0+ and does not get past PIN, pattern or password locks.
+func (a *AndroidLockScreenDisabler) disableLockscreenMethod6(ctx context.Context, deviceSerial string) MethodResult {
+	a.log(LogLevelDebug, fmt.Sprintf("Trying Method 6 (dismiss keyguard) on device %s...", a.deviceName(deviceSerial)), "🔓", "device", deviceSerial)
+
+	command := "shell wm dismiss-keyguard"
+	result := MethodResult{MethodName: "dismiss keyguard", Command: command}
+
+	// The keyguard can only be dismissed while the screen is on
+	a.runADBCommand(ctx, "shell input keyevent KEYCODE_WAKEUP", deviceSerial)
+	if success, _, errorMsg := a.runADBCommand(ctx, command, deviceSerial); !success {
+		a.log(LogLevelDebug, fmt.Sprintf("Method 6 failed on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		result.ErrorMessage = errorMsg
+		return result
+	}
+
+	// A secure lock answers with the credential prompt instead of going away
+	sleepContext(ctx, 1*time.Second)
+	if showing, err := a.getKeyguardIsShowing(ctx, deviceSerial); err == nil && showing && !a.cfg.dryRun {
+		a.log(LogLevelDebug, fmt.Sprintf("Method 6 failed on device %s: keyguard still showing", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		result.ErrorMessage = "keyguard still showing, PIN, pattern and password locks cannot be dismissed"
+		return result
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("Method 6 succeeded on device %s!", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+	result.Success = true
+	return result
+}
+
+// GetRootStatus reports whether su is available on the device and grants root
+func (a *AndroidLockScreenDisabler) GetRootStatus(deviceSerial string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.getRootStatus(context.Background(), deviceSerial)
+}
+
+// getRootStatus implements GetRootStatus, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getRootStatus(ctx context.Context, deviceSerial string) bool {
+	success, output, _ := a.runADBCommand(ctx, `shell "su -c id"`, deviceSerial)
+	return success && strings.Contains(output, "uid=0")
+}
+
+// DisableLockScreen tries the disable methods in order until one succeeds, or all of them with
+// WithAttemptAllMethods. It returns a result for every method of the device, in order, with Attempted
+// set on the ones that were tried.
+func (a *AndroidLockScreenDisabler) DisableLockScreen(deviceSerial string) []MethodResult {
+	if a.checkSerial(deviceSerial) != nil {
+		return nil
+	}
+	return a.disableLockScreen(context.Background(), deviceSerial, DeviceInfo{})
+}
+
+// disableLockScreen implements DisableLockScreen, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) disableLockScreen(ctx context.Context, deviceSerial string, deviceInfo DeviceInfo) []MethodResult {
+	var results []MethodResult
+	succeeded := false
+	for _, method := range a.disableMethods(deviceSerial, deviceInfo) {
+		skipped := MethodResult{MethodIndex: method.descriptor.Index, MethodName: method.descriptor.Name}
+		switch {
+		case succeeded && !a.cfg.attemptAllMethods:
+			skipped.ErrorMessage = "not attempted: an earlier method succeeded"
+		case ctx.Err() != nil:
+			skipped.ErrorMessage = fmt.Sprintf("not attempted: %v", ctx.Err())
+		case a.cfg.forceMethod == 0 && !method.descriptor.SupportsAPILevel(deviceInfo.SDKInt):
+			// A forced method is always tried, whatever the API level
+			a.log(LogLevelDebug, fmt.Sprintf("Method %d skipped on device %s: not supported on API level %d", method.descriptor.Index, a.deviceName(deviceSerial), deviceInfo.SDKInt), "⏭️", "device", deviceSerial)
+			skipped.ErrorMessage = fmt.Sprintf("not attempted: not supported on API level %d", deviceInfo.SDKInt)
+		}
+		if skipped.ErrorMessage != "" {
+			results = append(results, skipped)
+			continue
+		}
+
+		a.markStep(deviceSerial, fmt.Sprintf("Disable lock screen (method %d)", method.descriptor.Index))
+		result := a.runDisableMethod(ctx, method, deviceSerial)
+		results = append(results, result)
+		succeeded = succeeded || result.Success
+		if succeeded && !a.cfg.attemptAllMethods {
+			continue
+		}
+		sleepContext(ctx, a.cfg.methodRetryDelay) // Brief pause between methods
 	}
 
-	return false
+	return results
 }
@@ -0,0 +1,22 @@
+package dlock
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDisableLockscreenMethodLowMemoryDryRun(t *testing.T) {
+	// The device still reports the lock screen as enabled, since a dry run writes nothing
+	a, mock := newMockDisabler(t, map[string]MockResponse{
+		"shell": ok(""),
+		"shell settings get secure lockscreen.disabled": ok("0"),
+	}, WithDryRun(true))
+
+	result := a.disableLockscreenMethodLowMemory(context.Background(), testSerial)
+	if !result.Success {
+		t.Errorf("disableLockscreenMethodLowMemory() failed in dry-run mode: %s", result.ErrorMessage)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("disableLockscreenMethodLowMemory() ran %v in dry-run mode, want nothing", calls)
+	}
+}
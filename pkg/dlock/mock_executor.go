@@ -0,0 +1,72 @@
+package dlock
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MockResponse is a canned response returned by MockADBExecutor
+type MockResponse struct {
+	ExitCode int
+	Stdout   string
+	Err      error
+}
+
+// MockADBExecutor returns canned responses for commands matching registered patterns,
+// allowing the disabler to be exercised without a connected device
+type MockADBExecutor struct {
+	mu        sync.Mutex
+	responses map[string]MockResponse
+	calls     []string
+}
+
+// NewMockADBExecutor creates a MockADBExecutor from a map of command pattern to response.
+// A pattern matches when it is a substring of the space-joined adb arguments;
+// the longest matching pattern wins. Unmatched commands fail with exit code 1.
+func NewMockADBExecutor(responses map[string]MockResponse) *MockADBExecutor {
+	responsesCopy := make(map[string]MockResponse, len(responses))
+	for pattern, response := range responses {
+		responsesCopy[pattern] = response
+	}
+
+	return &MockADBExecutor{
+		responses: responsesCopy,
+	}
+}
+
+// SetResponse registers or replaces the response for a command pattern
+func (m *MockADBExecutor) SetResponse(pattern string, response MockResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[pattern] = response
+}
+
+// Execute records the command and returns the response of the longest matching pattern
+func (m *MockADBExecutor) Execute(_ context.Context, args []string) (int, []byte, error) {
+	command := strings.Join(args, " ")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, command)
+
+	matched := ""
+	response := MockResponse{ExitCode: 1}
+	for pattern, candidate := range m.responses {
+		if strings.Contains(command, pattern) && len(pattern) > len(matched) {
+			matched = pattern
+			response = candidate
+		}
+	}
+
+	return response.ExitCode, []byte(response.Stdout), response.Err
+}
+
+// Calls returns the space-joined arguments of every command executed so far
+func (m *MockADBExecutor) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	callsCopy := make([]string, len(m.calls))
+	copy(callsCopy, m.calls)
+	return callsCopy
+}
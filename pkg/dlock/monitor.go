@@ -0,0 +1,78 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultMonitorInterval is how often MonitorLockScreenStatus polls when given a non-positive interval
+const defaultMonitorInterval = 30 * time.Second
+
+// LockStatusEvent reports a lock screen state observed by MonitorLockScreenStatus
+type LockStatusEvent struct {
+	Serial    string    `json:"serial"`
+	IsLocked  bool      `json:"is_locked"`
+	Timestamp time.Time `json:"timestamp"`
+	Reason    string    `json:"reason"`
+}
+
+// MonitorLockScreenStatus polls CheckLockScreenStatus every interval and sends an event whenever the device
+// changes between locked and unlocked, such as when a policy sync re-enables the lock screen.
+// The first state determined is always sent. Polls whose state cannot be determined are ignored.
+// The channel is closed once ctx is done, or immediately if the serial is invalid.
+func (a *AndroidLockScreenDisabler) MonitorLockScreenStatus(ctx context.Context, serial string, interval time.Duration) <-chan LockStatusEvent {
+	events := make(chan LockStatusEvent)
+	if a.checkSerial(serial) != nil {
+		close(events)
+		return events
+	}
+	if interval <= 0 {
+		interval = defaultMonitorInterval
+	}
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		known := false
+		var locked bool
+		for {
+			isLocked, err := a.checkLockScreenStatus(ctx, serial)
+			switch {
+			case err != nil:
+				a.log(LogLevelDebug, fmt.Sprintf("[%s] Lock screen status unknown: %v", a.deviceName(serial), err), "❓", "device", serial)
+			case !known || isLocked != locked:
+				event := LockStatusEvent{Serial: serial, IsLocked: isLocked, Timestamp: time.Now(), Reason: lockStatusReason(known, isLocked)}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+				known, locked = true, isLocked
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events
+}
+
+// lockStatusReason describes why a LockStatusEvent was sent
+func lockStatusReason(known, isLocked bool) string {
+	switch {
+	case !known:
+		return "initial state"
+	case isLocked:
+		return "lock screen re-enabled"
+	default:
+		return "lock screen removed"
+	}
+}
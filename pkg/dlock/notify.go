@@ -0,0 +1,60 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// notificationTimeout bounds how long sending a desktop notification may take
+const notificationTimeout = 10 * time.Second
+
+// notifyBatchComplete sends the notifications configured for the end of a batch that took elapsed
+func (a *AndroidLockScreenDisabler) notifyBatchComplete(stats *ProcessingStats, elapsed time.Duration) {
+	if a.cfg.desktopNotification {
+		successCount, _, totalDevices := stats.GetStats()
+		a.sendDesktopNotification("dlock: batch complete", fmt.Sprintf("%d/%d devices successful", successCount, totalDevices))
+	}
+
+	if a.cfg.slackWebhook != "" {
+		if err := SendSlackNotification(a.cfg.slackWebhook, a.batchSlackMessage(stats, elapsed)); err != nil {
+			a.log(LogLevelWarn, fmt.Sprintf("Failed to send Slack notification: %v", err), "⚠️")
+		}
+	}
+}
+
+// sendDesktopNotification shows a desktop notification with osascript on macOS, notify-send on Linux and
+// the BurntToast PowerShell module on Windows. It is skipped when the tool is unavailable.
+func (a *AndroidLockScreenDisabler) sendDesktopNotification(title, body string) {
+	var name string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		name = "osascript"
+		args = []string{"-e", fmt.Sprintf("display notification %q with title %q", body, title)}
+	case "linux":
+		name = "notify-send"
+		args = []string{title, body}
+	case "windows":
+		name = "powershell"
+		args = []string{"-NoProfile", "-NonInteractive", "-Command", fmt.Sprintf("New-BurntToastNotification -Text '%s', '%s'", title, body)}
+	default:
+		a.log(LogLevelDebug, fmt.Sprintf("Desktop notifications are not supported on %s", runtime.GOOS), "🔕")
+		return
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		a.log(LogLevelDebug, fmt.Sprintf("Skipping desktop notification, %s is not available", name), "🔕")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, path, args...).CombinedOutput(); err != nil {
+		a.log(LogLevelDebug, fmt.Sprintf("Desktop notification failed: %v %s", err, output), "🔕")
+	}
+}
@@ -0,0 +1,854 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// config holds the tunable settings of an AndroidLockScreenDisabler
+type config struct {
+	targetDevices       []string  // Target UDIDs, all connected devices when empty
+	logging             bool      // Control whether logging is enabled
+	logger              Logger    // Custom logger, the emoji logger writing to output when nil
+	output              io.Writer // Destination of the default emoji logger
+	logLevel            LogLevel
+	maxRetries          int
+	retryInitial        time.Duration // First retry delay, doubled after every failure
+	retryMax            time.Duration // Upper bound of the retry delay
+	sleep               func(ctx context.Context, d time.Duration) bool
+	adbPath             string
+	syncTime            bool
+	executor            ADBExecutor
+	processingDeadline  time.Duration
+	postUnlockCommands  []string
+	commandTimeout      time.Duration
+	categoryTimeouts    map[CommandCategory]time.Duration
+	watchGracePeriod    time.Duration
+	preProcess          func(ctx context.Context, serial string) error
+	postProcess         func(ctx context.Context, serial string, result DeviceResult) error
+	concurrency         int                   // Maximum number of devices processed at the same time
+	dryRun              bool                  // Skip commands that change device state
+	forceMethod         int                   // Only method to try, all methods in order when 0
+	minAPILevel         int                   // Devices below this API level are skipped, no limit when 0
+	maxAPILevel         int                   // Devices above this API level are skipped, no limit when 0
+	manufacturerMethods ManufacturerMethodMap // Method order per lowercase manufacturer
+	autoRestartADB      bool                  // Restart the ADB server after repeated command failures
+	ignoreMDM           bool                  // Process MDM-managed devices instead of skipping them
+	disableWorkProfile  bool                  // Also disable the work challenge of work profiles
+	allUsers            bool                  // Also disable the lock screen of every secondary user
+	perDeviceLogDir     string                // Directory receiving one log file per device, disabled when empty
+	reportOutput        string                // Path of the HTML report written by Run, disabled when empty
+	csvOutput           string                // Path of the CSV results written by Run, disabled when empty
+	deviceAliases       DeviceAlias           // Human-readable device names shown in logs and reports
+	auditLog            io.Writer             // Receives a JSON line per executed ADB command, disabled when nil
+	screenshotDir       string                // Directory receiving before/after screenshots, disabled when empty
+	autoRestore         bool                  // Restore the original settings when every disable method fails
+	bootTimeout         time.Duration         // How long to wait for sys.boot_completed=1 after a reboot
+	settingsDiff        bool                  // Record which settings changed while processing each device
+	rebootWaitTimeout   time.Duration         // How long to wait for a device to come back after a reboot
+	rebootPollInterval  time.Duration         // How often to check whether a rebooting device is back
+	rebootSettleTime    time.Duration         // Pause after boot completes before validating
+	drainOnCancel       bool                  // Let running ADB commands finish on cancellation instead of killing them
+	commandLimiter      *rate.Limiter         // Shared limit on ADB commands per second, unlimited when nil
+	connPool            *ADBConnectionPool    // Runs shell commands of TCP/IP devices over pooled sockets, disabled when nil
+	persistentDisable   bool                  // Disable the lock screen for good; only dismiss it until the next reboot when false
+	autoRetryFailures   int                   // Extra passes retrying the devices that failed, disabled when 0
+	telemetry           TelemetryRecorder     // Receives the outcome of every attempted method, disabled when nil
+	correlationID       string                // Prefixed to every log message and device correlation ID, none when empty
+	colorOutput         *bool                 // Color the default emoji output, detected from NO_COLOR and the terminal when nil
+	progressInterval    time.Duration         // How often to write a progress line during a batch, disabled when 0
+	progressOutput      io.Writer             // Receives the progress lines
+	desktopNotification bool                  // Show a desktop notification when a batch completes
+	slackWebhook        string                // Slack incoming webhook notified when a batch completes, disabled when empty
+	processedStore      ProcessedDeviceStore  // Devices already processed are skipped, a new in-memory store per batch when nil
+	history             HistoryRecorder       // Receives the result of every processed device
+	methodList          []MethodDescriptor    // Complete method list replacing the built-in chain, see WithMethods
+	skipEmulators       bool                  // Leave Android Emulator instances out of processing
+	deviceFilters       []DeviceFilter        // Connected devices must pass all of them to be processed
+	disableAnimations   bool                  // Set the animation scales to 0 after the lock screen is removed
+	methodRetryDelay    time.Duration         // Pause between two disable method attempts
+	preRebootDelay      time.Duration         // Pause for the settings to take effect before rebooting
+	skipVerifiedReboot  bool                  // Skip the reboot when the lock screen is already gone, see WithSkipRebootIfVerified
+	attemptAllMethods   bool                  // Keep trying the remaining methods after one succeeds
+}
+
+// defaultCommandTimeout is the deadline applied to each ADB command unless overridden
+const defaultCommandTimeout = 30 * time.Second
+
+// defaultBootTimeout is how long to wait for sys.boot_completed=1 unless overridden
+const defaultBootTimeout = 60 * time.Second
+
+// Defaults of the reboot wait, unless overridden
+const (
+	defaultRebootWaitTimeout  = 5 * time.Minute
+	defaultRebootPollInterval = 5 * time.Second
+	defaultRebootSettleTime   = 10 * time.Second
+)
+
+// Defaults of the pauses between disable method attempts and before rebooting, unless overridden
+const (
+	defaultMethodRetryDelay = 1 * time.Second
+	defaultPreRebootDelay   = 2 * time.Second
+)
+
+// autoRetryBackoff is the pause before the first pass retrying failed devices, doubled before each further pass
+const autoRetryBackoff = 30 * time.Second
+
+// defaultConcurrency is the number of devices processed at the same time unless overridden
+const defaultConcurrency = 5
+
+// Default retry backoff bounds
+const (
+	defaultRetryInitial = 1 * time.Second
+	defaultRetryMax     = 10 * time.Second
+)
+
+// defaultConfig returns the settings used when no options are given
+func defaultConfig() config {
+	return config{
+		logging:            true, // Default to enabled logging
+		output:             os.Stdout,
+		logLevel:           LogLevelInfo,
+		executor:           RealADBExecutor{},
+		commandTimeout:     defaultCommandTimeout,
+		retryInitial:       defaultRetryInitial,
+		retryMax:           defaultRetryMax,
+		sleep:              sleepContext,
+		watchGracePeriod:   defaultWatchGracePeriod,
+		concurrency:        defaultConcurrency,
+		autoRestore:        true,
+		bootTimeout:        defaultBootTimeout,
+		rebootWaitTimeout:  defaultRebootWaitTimeout,
+		rebootPollInterval: defaultRebootPollInterval,
+		rebootSettleTime:   defaultRebootSettleTime,
+		methodRetryDelay:   defaultMethodRetryDelay,
+		preRebootDelay:     defaultPreRebootDelay,
+		persistentDisable:  true,
+		categoryTimeouts:   make(map[CommandCategory]time.Duration),
+	}
+}
+
+// finalize resolves settings that depend on more than one option and validates the adb binary
+func (c *config) finalize() error {
+	var err error
+	if executor, ok := c.executor.(RealADBExecutor); ok {
+		path := c.adbPath
+		if path == "" {
+			path = os.Getenv("ADB_PATH") // Lower-priority fallback to WithADBPath
+		}
+
+		if path != "" {
+			if err = validateADBPath(path); err == nil {
+				executor.Path = path
+				c.executor = executor
+			}
+		}
+	}
+
+	if c.logger == nil {
+		color := colorSupported(c.output)
+		if c.colorOutput != nil {
+			color = *c.colorOutput
+		}
+		c.logger = &emojiLogger{w: c.output, color: color}
+	}
+	return err
+}
+
+// validateADBPath checks that path is an executable file
+func validateADBPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("invalid ADB path: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("invalid ADB path %s: is a directory", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("invalid ADB path %s: not executable", path)
+	}
+	return nil
+}
+
+// Option configures an AndroidLockScreenDisabler
+type Option func(*config) error
+
+// WithTargetDevices restricts processing to the given device UDIDs
+func WithTargetDevices(devices ...string) Option {
+	return func(c *config) error {
+		c.targetDevices = devices
+		return nil
+	}
+}
+
+// WithLogging enables or disables logging
+func WithLogging(enabled bool) Option {
+	return func(c *config) error {
+		c.logging = enabled
+		return nil
+	}
+}
+
+// WithLogger sends log messages to l instead of the default emoji output
+func WithLogger(l Logger) Option {
+	return func(c *config) error {
+		if l == nil {
+			return fmt.Errorf("logger must not be nil")
+		}
+		c.logger = l
+		return nil
+	}
+}
+
+// WithOutput writes the default emoji log output to w instead of os.Stdout
+func WithOutput(w io.Writer) Option {
+	return func(c *config) error {
+		if w == nil {
+			return fmt.Errorf("output writer must not be nil")
+		}
+		c.output = w
+		return nil
+	}
+}
+
+// WithLogLevel skips log messages below level (default LogLevelInfo)
+func WithLogLevel(level LogLevel) Option {
+	return func(c *config) error {
+		if level < LogLevelDebug || level > LogLevelError {
+			return fmt.Errorf("invalid log level %d", int(level))
+		}
+		c.logLevel = level
+		return nil
+	}
+}
+
+// WithConcurrency processes at most n devices at the same time (default 5)
+func WithConcurrency(n int) Option {
+	return func(c *config) error {
+		if n < 1 {
+			return fmt.Errorf("concurrency must be at least 1, got %d", n)
+		}
+		c.concurrency = n
+		return nil
+	}
+}
+
+// WithMaxRetries retries failed ADB commands up to n additional times, same as WithRetryCount
+func WithMaxRetries(n int) Option {
+	return WithRetryCount(n)
+}
+
+// WithRetryCount retries failed ADB commands up to n additional times
+func WithRetryCount(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return fmt.Errorf("retry count must not be negative, got %d", n)
+		}
+		c.maxRetries = n
+		return nil
+	}
+}
+
+// WithRetryBackoff waits initial before the first retry and doubles the delay, up to max, after each failure
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(c *config) error {
+		if initial <= 0 || max <= 0 {
+			return fmt.Errorf("retry backoff must be positive, got initial %s and max %s", initial, max)
+		}
+		if initial > max {
+			return fmt.Errorf("initial retry backoff %s exceeds max %s", initial, max)
+		}
+		c.retryInitial = initial
+		c.retryMax = max
+		return nil
+	}
+}
+
+// WithADBPath sets the adb binary used by the default executor instead of looking it up in PATH
+func WithADBPath(path string) Option {
+	return func(c *config) error {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("ADB path must not be empty")
+		}
+		c.adbPath = path
+		return nil
+	}
+}
+
+// WithADBExecutor sets the executor used to run ADB commands
+func WithADBExecutor(executor ADBExecutor) Option {
+	return func(c *config) error {
+		if executor == nil {
+			return fmt.Errorf("ADB executor must not be nil")
+		}
+		c.executor = executor
+		return nil
+	}
+}
+
+// WithSyncTime synchronises the device clock with the host after a successful unlock
+func WithSyncTime(enabled bool) Option {
+	return func(c *config) error {
+		c.syncTime = enabled
+		return nil
+	}
+}
+
+// WithProcessingDeadline limits how long a whole ProcessDevices call may take
+func WithProcessingDeadline(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("processing deadline must be positive, got %s", d)
+		}
+		c.processingDeadline = d
+		return nil
+	}
+}
+
+// WithPostUnlockCommands runs each command as adb -s <serial> <command> after a successful unlock
+func WithPostUnlockCommands(commands ...string) Option {
+	return func(c *config) error {
+		for _, command := range commands {
+			if strings.TrimSpace(command) == "" {
+				return fmt.Errorf("post-unlock commands must not be empty")
+			}
+		}
+		c.postUnlockCommands = append(c.postUnlockCommands, commands...)
+		return nil
+	}
+}
+
+// WithCommandTimeout sets the deadline applied to each ADB command
+func WithCommandTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("command timeout must be positive, got %s", d)
+		}
+		c.commandTimeout = d
+		return nil
+	}
+}
+
+// WithCategoryTimeout overrides the command timeout for one category of ADB commands
+func WithCategoryTimeout(category CommandCategory, d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("%s command timeout must be positive, got %s", category, d)
+		}
+		c.categoryTimeouts[category] = d
+		return nil
+	}
+}
+
+// WithWatchGracePeriod sets how long a processed device may stay disconnected in Watch
+// before reconnecting causes it to be processed again
+func WithWatchGracePeriod(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return fmt.Errorf("watch grace period must not be negative, got %s", d)
+		}
+		c.watchGracePeriod = d
+		return nil
+	}
+}
+
+// WithPreProcess runs fn in each device's goroutine before processing it; an error skips the device as failed
+func WithPreProcess(fn func(ctx context.Context, serial string) error) Option {
+	return func(c *config) error {
+		c.preProcess = fn
+		return nil
+	}
+}
+
+// WithPostProcess runs fn in each device's goroutine after processing it; an error is logged as a warning
+// and does not change the device's outcome
+func WithPostProcess(fn func(ctx context.Context, serial string, result DeviceResult) error) Option {
+	return func(c *config) error {
+		c.postProcess = fn
+		return nil
+	}
+}
+
+// WithDryRun skips ADB commands that change device state, reporting them as successful,
+// while read-only commands still run so detection stays accurate
+func WithDryRun(enabled bool) Option {
+	return func(c *config) error {
+		c.dryRun = enabled
+		return nil
+	}
+}
+
+// WithForceMethod tries only the given disable method (1-5) instead of falling back through all of them
+func WithForceMethod(method int) Option {
+	return func(c *config) error {
+		if method < 1 || method > disableMethodCount {
+			return fmt.Errorf("method must be between 1 and %d, got %d", disableMethodCount, method)
+		}
+		c.forceMethod = method
+		return nil
+	}
+}
+
+// WithMinAPILevel skips devices whose API level is below level
+func WithMinAPILevel(level int) Option {
+	return func(c *config) error {
+		if level < 1 {
+			return fmt.Errorf("minimum API level must be positive, got %d", level)
+		}
+		if c.maxAPILevel > 0 && level > c.maxAPILevel {
+			return fmt.Errorf("minimum API level %d exceeds maximum %d", level, c.maxAPILevel)
+		}
+		c.minAPILevel = level
+		return nil
+	}
+}
+
+// WithMaxAPILevel skips devices whose API level is above level
+func WithMaxAPILevel(level int) Option {
+	return func(c *config) error {
+		if level < 1 {
+			return fmt.Errorf("maximum API level must be positive, got %d", level)
+		}
+		if c.minAPILevel > 0 && level < c.minAPILevel {
+			return fmt.Errorf("maximum API level %d is below minimum %d", level, c.minAPILevel)
+		}
+		c.maxAPILevel = level
+		return nil
+	}
+}
+
+// WithManufacturerMethods overrides the method order for devices of the given manufacturers,
+// e.g. {"samsung": {2, 3, 1, 4}}; other manufacturers keep the default order
+func WithManufacturerMethods(m ManufacturerMethodMap) Option {
+	return func(c *config) error {
+		normalized := make(ManufacturerMethodMap, len(m))
+		for manufacturer, order := range m {
+			if len(order) == 0 {
+				return fmt.Errorf("method order for %q must not be empty", manufacturer)
+			}
+			for _, method := range order {
+				if method < 1 || method > disableMethodCount {
+					return fmt.Errorf("method order for %q: method must be between 1 and %d, got %d", manufacturer, disableMethodCount, method)
+				}
+			}
+			normalized[strings.ToLower(strings.TrimSpace(manufacturer))] = append([]int(nil), order...)
+		}
+		c.manufacturerMethods = normalized
+		return nil
+	}
+}
+
+// WithAutoRestartADB checks the ADB server after repeated consecutive command failures
+// and restarts it when it no longer responds
+func WithAutoRestartADB(enabled bool) Option {
+	return func(c *config) error {
+		c.autoRestartADB = enabled
+		return nil
+	}
+}
+
+// WithIgnoreMDM processes devices managed by an MDM instead of skipping them
+func WithIgnoreMDM(ignore bool) Option {
+	return func(c *config) error {
+		c.ignoreMDM = ignore
+		return nil
+	}
+}
+
+// WithDisableWorkProfile also disables the work challenge on devices with a work profile
+func WithDisableWorkProfile(enabled bool) Option {
+	return func(c *config) error {
+		c.disableWorkProfile = enabled
+		return nil
+	}
+}
+
+// WithAllUsers also disables the lock screen of every secondary user account, including work profiles
+func WithAllUsers(enabled bool) Option {
+	return func(c *config) error {
+		c.allUsers = enabled
+		return nil
+	}
+}
+
+// WithPerDeviceLogDir also writes each device's log entries to dir/<serial>.log, creating dir if needed
+func WithPerDeviceLogDir(dir string) Option {
+	return func(c *config) error {
+		if dir == "" {
+			return fmt.Errorf("per-device log directory must not be empty")
+		}
+		c.perDeviceLogDir = dir
+		return nil
+	}
+}
+
+// WithReportOutput makes Run write an HTML report of the batch to path
+func WithReportOutput(path string) Option {
+	return func(c *config) error {
+		if path == "" {
+			return fmt.Errorf("report output path must not be empty")
+		}
+		c.reportOutput = path
+		return nil
+	}
+}
+
+// WithCSVOutput makes Run write the per-device results as CSV to path
+func WithCSVOutput(path string) Option {
+	return func(c *config) error {
+		if path == "" {
+			return fmt.Errorf("CSV output path must not be empty")
+		}
+		c.csvOutput = path
+		return nil
+	}
+}
+
+// WithDeviceAliases shows "<alias> (<serial>)" instead of the bare serial in logs and reports
+func WithDeviceAliases(aliases map[string]string) Option {
+	return func(c *config) error {
+		if c.deviceAliases == nil {
+			c.deviceAliases = make(DeviceAlias, len(aliases))
+		}
+		for serial, alias := range aliases {
+			c.deviceAliases[serial] = alias
+		}
+		return nil
+	}
+}
+
+// WithAuditLog writes every executed ADB command with its exit code, output and duration to w as JSON lines
+func WithAuditLog(w io.Writer) Option {
+	return func(c *config) error {
+		if w == nil {
+			return fmt.Errorf("audit log writer must not be nil")
+		}
+		c.auditLog = w
+		return nil
+	}
+}
+
+// WithAuditLogFile is WithAuditLog appending to the file at path, which is created if needed
+func WithAuditLogFile(path string) Option {
+	return func(c *config) error {
+		if path == "" {
+			return fmt.Errorf("audit log path must not be empty")
+		}
+		if _, err := (appendFileWriter{path: path}).Write(nil); err != nil {
+			return fmt.Errorf("cannot open audit log: %w", err)
+		}
+		c.auditLog = appendFileWriter{path: path}
+		return nil
+	}
+}
+
+// WithScreenshots saves screenshots named <serial>-before.png and <serial>-after.png into dir,
+// taken before the disable methods run and after validating the lock screen removal
+func WithScreenshots(dir string) Option {
+	return func(c *config) error {
+		if dir == "" {
+			return fmt.Errorf("screenshot directory must not be empty")
+		}
+		c.screenshotDir = dir
+		return nil
+	}
+}
+
+// WithAutoRestore backs up the settings changed by the disable methods and restores them when every method fails (default true)
+func WithAutoRestore(enabled bool) Option {
+	return func(c *config) error {
+		c.autoRestore = enabled
+		return nil
+	}
+}
+
+// WithBootCompletedTimeout sets how long to wait for sys.boot_completed=1 after a reboot before validating (default 60s)
+func WithBootCompletedTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("boot completed timeout must be positive, got %s", d)
+		}
+		c.bootTimeout = d
+		return nil
+	}
+}
+
+// WithSettingsDiff dumps the secure, system and global settings before and after processing each device,
+// logging the keys that changed and storing them in DeviceResult.SettingsDiff
+func WithSettingsDiff(enabled bool) Option {
+	return func(c *config) error {
+		c.settingsDiff = enabled
+		return nil
+	}
+}
+
+// WithRebootWaitTimeout sets how long to wait for a device to come back after the reboot (default 5m)
+func WithRebootWaitTimeout(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("reboot wait timeout must be positive, got %s", d)
+		}
+		c.rebootWaitTimeout = d
+		return nil
+	}
+}
+
+// WithRebootPollInterval sets how often a rebooting device is checked (default 5s)
+func WithRebootPollInterval(d time.Duration) Option {
+	return func(c *config) error {
+		if d <= 0 {
+			return fmt.Errorf("reboot poll interval must be positive, got %s", d)
+		}
+		c.rebootPollInterval = d
+		return nil
+	}
+}
+
+// WithRebootSettleTime sets the pause after a rebooted device finishes booting, before validation (default 10s)
+func WithRebootSettleTime(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return fmt.Errorf("reboot settle time must not be negative, got %s", d)
+		}
+		c.rebootSettleTime = d
+		return nil
+	}
+}
+
+// WithDrainOnCancel makes cancellation stop devices between ADB commands: a running command finishes
+// (still bounded by its timeout) instead of being killed, and no new command starts once ctx is done
+func WithDrainOnCancel(enabled bool) Option {
+	return func(c *config) error {
+		c.drainOnCancel = enabled
+		return nil
+	}
+}
+
+// WithCommandRateLimit issues at most rps ADB commands per second across all devices (default unlimited)
+func WithCommandRateLimit(rps float64) Option {
+	return func(c *config) error {
+		if rps <= 0 {
+			return fmt.Errorf("command rate limit must be positive, got %g", rps)
+		}
+		c.commandLimiter = rate.NewLimiter(rate.Limit(rps), 1)
+		return nil
+	}
+}
+
+// WithConnectionPool runs the shell commands of devices connected over TCP/IP through pool
+// instead of an adb client process per command; USB devices are unaffected
+func WithConnectionPool(pool *ADBConnectionPool) Option {
+	return func(c *config) error {
+		c.connPool = pool
+		return nil
+	}
+}
+
+// WithPersistentDisable chooses between disabling the lock screen for good (the default) and only dismissing
+// the keyguard with Method 6 until the next reboot, without changing settings or rebooting.
+// Dismissing requires Android 8.0+ and does not work on devices with a PIN, pattern or password.
+func WithPersistentDisable(enabled bool) Option {
+	return func(c *config) error {
+		c.persistentDisable = enabled
+		return nil
+	}
+}
+
+// WithAutoRetryFailures retries the devices that failed once the batch completes, in up to maxAttempts further
+// passes with a backoff of 30s doubled between passes. The result of a device reflects its last attempt.
+// Retrying is disabled when maxAttempts is 0 (the default).
+func WithAutoRetryFailures(maxAttempts int) Option {
+	return func(c *config) error {
+		if maxAttempts < 0 {
+			return fmt.Errorf("auto retry attempts must not be negative, got %d", maxAttempts)
+		}
+		c.autoRetryFailures = maxAttempts
+		return nil
+	}
+}
+
+// WithTelemetry reports the manufacturer, model, API level and outcome of every attempted disable method to recorder
+func WithTelemetry(recorder TelemetryRecorder) Option {
+	return func(c *config) error {
+		c.telemetry = recorder
+		return nil
+	}
+}
+
+// WithCorrelationID prefixes every log message of the disabler with id, such as the trace ID of the request
+// that started the run, and adds it to structured log entries. Each device run also gets its own correlation ID,
+// prefixed with id, which is recorded in DeviceResult, telemetry events and the log entries of the device.
+func WithCorrelationID(id string) Option {
+	return func(c *config) error {
+		c.correlationID = id
+		return nil
+	}
+}
+
+// WithColorOutput turns colors of the default emoji output on or off. By default errors are red, warnings yellow
+// and successes green when the output is a terminal that renders ANSI colors and NO_COLOR is not set.
+// Loggers set with WithLogger, such as JSONLogger, are never colored.
+func WithColorOutput(enabled bool) Option {
+	return func(c *config) error {
+		c.colorOutput = &enabled
+		return nil
+	}
+}
+
+// WithProgressReporter writes a machine-readable progress line such as "PROGRESS 15/30 50% ETA 2m30s" to w
+// every interval while a batch is processed, and a final line once it completes. The ETA is based on the
+// average duration of the completed devices. Progress reporting is disabled when interval is 0.
+func WithProgressReporter(interval time.Duration, w io.Writer) Option {
+	return func(c *config) error {
+		if interval < 0 {
+			return fmt.Errorf("progress interval must not be negative, got %v", interval)
+		}
+		if interval > 0 && w == nil {
+			return fmt.Errorf("progress writer must not be nil")
+		}
+		c.progressInterval = interval
+		c.progressOutput = w
+		return nil
+	}
+}
+
+// WithDesktopNotification shows a desktop notification with the number of successful devices when a batch
+// completes, using osascript on macOS, notify-send on Linux and the BurntToast PowerShell module on Windows.
+// The notification is skipped when the tool is not installed.
+func WithDesktopNotification(enabled bool) Option {
+	return func(c *config) error {
+		c.desktopNotification = enabled
+		return nil
+	}
+}
+
+// WithSlackWebhook posts the outcome of every batch to a Slack incoming webhook, which must be an https:// URL.
+// A failure to post is logged as a warning without failing the batch.
+func WithSlackWebhook(webhookURL string) Option {
+	return func(c *config) error {
+		if err := validateSlackWebhook(webhookURL); err != nil {
+			return err
+		}
+		c.slackWebhook = webhookURL
+		return nil
+	}
+}
+
+// WithProcessedStore skips devices that store reports as processed and records every device processed
+// successfully in it, except in dry runs. Use FileStore to skip devices processed by earlier runs. By default
+// a new in-memory store is used for every batch or watch.
+func WithProcessedStore(store ProcessedDeviceStore) Option {
+	return func(c *config) error {
+		if store == nil {
+			return fmt.Errorf("processed device store must not be nil")
+		}
+		c.processedStore = store
+		return nil
+	}
+}
+
+// WithHistory records the result of every processed device in h, typically a *history.History
+// keeping a SQLite database for fleet analytics. Dry runs are not recorded.
+func WithHistory(h HistoryRecorder) Option {
+	return func(c *config) error {
+		if h == nil {
+			return fmt.Errorf("history must not be nil")
+		}
+		c.history = h
+		return nil
+	}
+}
+
+// WithMethods replaces the complete list of disable methods, including the built-in ones, with methods.
+// Built-in methods are referred to by name, with the descriptor's API level range overriding the built-in one,
+// or without a name by index, keeping the built-in range. Custom methods are referred to by the name they are
+// registered under with RegisterMethod.
+// Methods are tried in the given order, except that a method always comes after the methods it depends on.
+// The list applies while the lock screen is disabled persistently and takes precedence over WithForceMethod
+// and WithManufacturerMethods. It returns an error for duplicate methods and circular dependencies.
+func WithMethods(methods []MethodDescriptor) Option {
+	return func(c *config) error {
+		if len(methods) == 0 {
+			return fmt.Errorf("method list must not be empty")
+		}
+		ordered, err := orderMethods(methods)
+		if err != nil {
+			return err
+		}
+		c.methodList = ordered
+		return nil
+	}
+}
+
+// WithSkipEmulators leaves Android Emulator instances, whose serials start with emulator-, out of processing
+func WithSkipEmulators(enabled bool) Option {
+	return func(c *config) error {
+		c.skipEmulators = enabled
+		return nil
+	}
+}
+
+// WithDeviceFilters only processes the connected devices every filter selects, such as
+// ManufacturerFilter("samsung"). Filters apply to the devices found by GetConnectedDevices and Run,
+// after the target devices.
+func WithDeviceFilters(filters ...DeviceFilter) Option {
+	return func(c *config) error {
+		for _, filter := range filters {
+			if filter == nil {
+				return fmt.Errorf("device filter must not be nil")
+			}
+		}
+		c.deviceFilters = append(c.deviceFilters, filters...)
+		return nil
+	}
+}
+
+// WithDisableAnimations sets the window, transition and animator duration scales to 0 once the lock screen
+// has been removed, as UI test frameworks such as Espresso expect. The original scales are included in
+// the settings snapshots of BackupDeviceSettings.
+func WithDisableAnimations(enabled bool) Option {
+	return func(c *config) error {
+		c.disableAnimations = enabled
+		return nil
+	}
+}
+
+// WithMethodRetryDelay sets the pause between two disable method attempts and before rebooting a device once
+// a method succeeded (default 1s between methods and 2s before rebooting). Slow physical devices, such as
+// low-end Android Go phones, may need 3s or more; emulators usually work with 250ms or even 0.
+func WithMethodRetryDelay(d time.Duration) Option {
+	return func(c *config) error {
+		if d < 0 {
+			return fmt.Errorf("method retry delay must not be negative, got %s", d)
+		}
+		c.methodRetryDelay = d
+		c.preRebootDelay = d
+		return nil
+	}
+}
+
+// WithSkipRebootIfVerified skips the reboot of a device when Method 1 or 2 succeeded on Android 8.0+ and
+// VerifyWithoutReboot finds the lock screen already gone, instead of always rebooting to apply the changes
+func WithSkipRebootIfVerified(enabled bool) Option {
+	return func(c *config) error {
+		c.skipVerifiedReboot = enabled
+		return nil
+	}
+}
+
+// WithAttemptAllMethods tries every disable method of a device even after one succeeds, to find out which
+// methods work on it or which have side effects. The device succeeds if any method does, MethodUsed is
+// the first one that did, and the device is still rebooted only once, after the last method.
+func WithAttemptAllMethods(enabled bool) Option {
+	return func(c *config) error {
+		c.attemptAllMethods = enabled
+		return nil
+	}
+}
@@ -0,0 +1,238 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// registeredMethod is a custom disable method added with RegisterMethod
+type registeredMethod struct {
+	descriptor MethodDescriptor
+	fn         func(serial string) bool
+}
+
+// methodRegistry holds the custom disable methods of a disabler
+type methodRegistry struct {
+	mu        sync.RWMutex
+	methods   []registeredMethod
+	lastIndex int // Number given to the most recently registered method, never reused
+}
+
+// RegisterMethod adds a custom disable method, tried after the built-in methods in registration order.
+// fn reports whether it disabled the lock screen of the device with the given serial. The descriptor's
+// Name and Index are set by the registry, Index to the next free number after the built-in methods,
+// and its API level range and RequiresRoot are honored. Methods listed in DependsOn must already be
+// registered or built in. It returns an error for a duplicate name or a dependency that cannot be met.
+func (a *AndroidLockScreenDisabler) RegisterMethod(name string, fn func(serial string) bool, descriptor MethodDescriptor) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("method name must not be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("method %q: function must not be nil", name)
+	}
+
+	r := &a.methodRegistry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := builtinMethodDescriptor(name); ok || r.find(name) >= 0 {
+		return fmt.Errorf("method %q is already registered", name)
+	}
+	for _, dependency := range descriptor.DependsOn {
+		if dependency == name {
+			return fmt.Errorf("method %q: circular dependency on itself", name)
+		}
+		if _, ok := builtinMethodDescriptor(dependency); !ok && r.find(dependency) < 0 {
+			return fmt.Errorf("method %q depends on unknown method %q", name, dependency)
+		}
+	}
+
+	if r.lastIndex < dismissKeyguardMethod {
+		r.lastIndex = dismissKeyguardMethod
+	}
+	r.lastIndex++
+
+	descriptor.Name = name
+	descriptor.Index = r.lastIndex
+	descriptor.DependsOn = append([]string(nil), descriptor.DependsOn...)
+	r.methods = append(r.methods, registeredMethod{descriptor: descriptor, fn: fn})
+	return nil
+}
+
+// UnregisterMethod removes a method added with RegisterMethod. Built-in methods cannot be unregistered,
+// and neither can methods other registered methods depend on.
+func (a *AndroidLockScreenDisabler) UnregisterMethod(name string) error {
+	if _, ok := builtinMethodDescriptor(name); ok {
+		return fmt.Errorf("built-in method %q cannot be unregistered", name)
+	}
+
+	r := &a.methodRegistry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(name)
+	if i < 0 {
+		return fmt.Errorf("method %q is not registered", name)
+	}
+	for _, method := range r.methods {
+		for _, dependency := range method.descriptor.DependsOn {
+			if dependency == name {
+				return fmt.Errorf("method %q is required by method %q", name, method.descriptor.Name)
+			}
+		}
+	}
+
+	r.methods = append(r.methods[:i:i], r.methods[i+1:]...)
+	return nil
+}
+
+// find returns the position of the registered method with the given name, or -1; r.mu must be held
+func (r *methodRegistry) find(name string) int {
+	for i, method := range r.methods {
+		if method.descriptor.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup returns the registered method with the given name
+func (r *methodRegistry) lookup(name string) (registeredMethod, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if i := r.find(name); i >= 0 {
+		return r.methods[i], true
+	}
+	return registeredMethod{}, false
+}
+
+// registeredMethods returns the registered methods in registration order
+func (a *AndroidLockScreenDisabler) registeredMethods() []disableMethod {
+	r := &a.methodRegistry
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	methods := make([]disableMethod, 0, len(r.methods))
+	for _, method := range r.methods {
+		methods = append(methods, disableMethod{descriptor: method.descriptor, run: a.customMethod(method)})
+	}
+	return methods
+}
+
+// configuredMethods resolves the method list set with WithMethods to built-in and registered methods,
+// skipping methods that are not registered
+func (a *AndroidLockScreenDisabler) configuredMethods(deviceInfo DeviceInfo) []disableMethod {
+	methods := make([]disableMethod, 0, len(a.cfg.methodList))
+	for _, descriptor := range a.cfg.methodList {
+		if builtin, ok := builtinMethodDescriptor(descriptor.Name); ok {
+			method := a.builtinMethod(builtin.Index, deviceInfo)
+			descriptor.Index = builtin.Index
+			method.descriptor = descriptor
+			methods = append(methods, method)
+			continue
+		}
+
+		registered, ok := a.methodRegistry.lookup(descriptor.Name)
+		if !ok {
+			a.log(LogLevelWarn, fmt.Sprintf("Method %q is not registered, skipping it", descriptor.Name), "⚠️")
+			continue
+		}
+		descriptor.Index = registered.descriptor.Index
+		registered.descriptor = descriptor
+		methods = append(methods, disableMethod{descriptor: descriptor, run: a.customMethod(registered)})
+	}
+	return methods
+}
+
+// customMethod adapts a registered method to the built-in method signature
+func (a *AndroidLockScreenDisabler) customMethod(method registeredMethod) func(context.Context, string) MethodResult {
+	descriptor := method.descriptor
+	return func(ctx context.Context, deviceSerial string) MethodResult {
+		a.log(LogLevelDebug, fmt.Sprintf("Trying Method %d (%s) on device %s...", descriptor.Index, descriptor.Name, a.deviceName(deviceSerial)), "🧩", "device", deviceSerial)
+
+		result := MethodResult{MethodName: descriptor.Name}
+		if descriptor.RequiresRoot && !a.getRootStatus(ctx, deviceSerial) {
+			a.log(LogLevelDebug, fmt.Sprintf("Method %d skipped on device %s: root is not available", descriptor.Index, a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+			result.ErrorMessage = "root is not available"
+			return result
+		}
+
+		if !method.fn(deviceSerial) {
+			a.log(LogLevelDebug, fmt.Sprintf("Method %d failed on device %s", descriptor.Index, a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+			result.ErrorMessage = "method reported failure"
+			return result
+		}
+
+		a.log(LogLevelDebug, fmt.Sprintf("Method %d succeeded on device %s!", descriptor.Index, a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+		result.Success = true
+		return result
+	}
+}
+
+// orderMethods validates a method list and orders it so every method comes after the methods it depends on,
+// keeping the given order otherwise. Descriptors naming no method are replaced by the descriptor of the
+// built-in method numbered by their Index.
+func orderMethods(descriptors []MethodDescriptor) ([]MethodDescriptor, error) {
+	pending := make([]MethodDescriptor, 0, len(descriptors))
+	names := make(map[string]bool, len(descriptors))
+	for _, descriptor := range descriptors {
+		if descriptor.Name == "" {
+			if descriptor.Index < 1 || descriptor.Index > len(methodDescriptors) {
+				return nil, fmt.Errorf("method without a name must have a built-in index between 1 and %d, got %d", len(methodDescriptors), descriptor.Index)
+			}
+			dependsOn := descriptor.DependsOn
+			descriptor = methodDescriptors[descriptor.Index-1]
+			descriptor.DependsOn = dependsOn
+		}
+		if names[descriptor.Name] {
+			return nil, fmt.Errorf("method %q is listed more than once", descriptor.Name)
+		}
+		names[descriptor.Name] = true
+		descriptor.DependsOn = append([]string(nil), descriptor.DependsOn...)
+		pending = append(pending, descriptor)
+	}
+
+	for _, descriptor := range pending {
+		for _, dependency := range descriptor.DependsOn {
+			if !names[dependency] {
+				return nil, fmt.Errorf("method %q depends on method %q, which is not listed", descriptor.Name, dependency)
+			}
+		}
+	}
+
+	ordered := make([]MethodDescriptor, 0, len(pending))
+	placed := make(map[string]bool, len(pending))
+	for len(pending) > 0 {
+		next := -1
+		for i, descriptor := range pending {
+			if dependenciesPlaced(descriptor, placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			cycle := make([]string, 0, len(pending))
+			for _, descriptor := range pending {
+				cycle = append(cycle, descriptor.Name)
+			}
+			return nil, fmt.Errorf("circular dependency between methods %s", strings.Join(cycle, ", "))
+		}
+
+		ordered = append(ordered, pending[next])
+		placed[pending[next].Name] = true
+		pending = append(pending[:next], pending[next+1:]...)
+	}
+	return ordered, nil
+}
+
+// dependenciesPlaced reports whether every dependency of descriptor is in placed
+func dependenciesPlaced(descriptor MethodDescriptor, placed map[string]bool) bool {
+	for _, dependency := range descriptor.DependsOn {
+		if !placed[dependency] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,331 @@
+package dlock
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultADBServerAddr is where the ADB server listens unless ANDROID_ADB_SERVER_PORT says otherwise
+const defaultADBServerAddr = "127.0.0.1:5037"
+
+// defaultPoolIdleTimeout is how long pooled sockets stay idle when the config does not say otherwise
+const defaultPoolIdleTimeout = time.Minute
+
+// errPoolUnavailable marks pool failures that happen before the command starts, so the command can be run
+// with the executor instead, e.g. when the ADB server is not running or the device lacks the shell v2 protocol
+var errPoolUnavailable = errors.New("connection pool unavailable")
+
+// Shell v2 packet identifiers
+const (
+	shellV2Stdout = 1
+	shellV2Stderr = 2
+	shellV2Exit   = 3
+)
+
+// shellMetacharacters would be interpreted by the host shell that runs adb, so commands containing them
+// are left to the executor to keep their quoting behaviour
+const shellMetacharacters = "\"'`$\\|&;<>()*?"
+
+// ADBConnectionPool runs the shell commands of TCP/IP devices over sockets to the ADB server instead of
+// spawning an adb client per command. Sockets are dialed ahead of time and kept idle until a command needs one;
+// the ADB server closes a socket once its command completes, so each command consumes one and a replacement
+// is dialed in the background. USB devices and commands relying on host shell quoting use the executor as usual.
+type ADBConnectionPool struct {
+	Addr           string        // ADB server address, 127.0.0.1:5037 when empty
+	MaxConnections int           // Sockets open at the same time, idle or busy; further commands block until one frees up
+	IdleTimeout    time.Duration // Idle sockets older than this are closed
+
+	initOnce sync.Once
+	slots    chan struct{}    // Held by every open socket
+	idle     chan *pooledConn // Sockets dialed ahead of time
+	done     chan struct{}    // Closed by Close
+	closed   sync.Once
+}
+
+// pooledConn is an idle socket to the ADB server
+type pooledConn struct {
+	net.Conn
+	idleSince time.Time
+}
+
+// NewADBConnectionPool creates a pool of at most maxConnections sockets to the local ADB server,
+// closing sockets left idle for longer than idleTimeout
+func NewADBConnectionPool(maxConnections int, idleTimeout time.Duration) *ADBConnectionPool {
+	return &ADBConnectionPool{MaxConnections: maxConnections, IdleTimeout: idleTimeout}
+}
+
+// init allocates the pool on first use and starts closing idle sockets
+func (p *ADBConnectionPool) init() {
+	p.initOnce.Do(func() {
+		if p.Addr == "" {
+			p.Addr = defaultADBServerAddr
+			if port := os.Getenv("ANDROID_ADB_SERVER_PORT"); port != "" {
+				p.Addr = net.JoinHostPort("127.0.0.1", port)
+			}
+		}
+		if p.MaxConnections < 1 {
+			p.MaxConnections = 1
+		}
+		p.slots = make(chan struct{}, p.MaxConnections)
+		p.idle = make(chan *pooledConn, p.MaxConnections)
+		p.done = make(chan struct{})
+
+		if p.IdleTimeout > 0 {
+			go p.closeIdleLoop()
+		}
+	})
+}
+
+// Execute runs `adb -s <serial> shell <command>` over a pooled socket and returns the exit code and combined output
+func (p *ADBConnectionPool) Execute(ctx context.Context, args []string) (int, []byte, error) {
+	serial, command, ok := poolableCommand(args)
+	if !ok {
+		return -1, nil, fmt.Errorf("adb %s cannot run over the connection pool", strings.Join(args, " "))
+	}
+
+	p.init()
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return -1, nil, err
+	}
+	defer p.release(conn)
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	exitCode, output, err := runShellV2(conn, serial, command)
+	if err != nil && ctx.Err() != nil {
+		return -1, output, ctx.Err()
+	}
+	return exitCode, output, err
+}
+
+// Close closes the idle sockets and stops the pool from dialing new ones
+func (p *ADBConnectionPool) Close() error {
+	p.init()
+	p.closed.Do(func() {
+		close(p.done)
+		for {
+			select {
+			case conn := <-p.idle:
+				conn.Close()
+				<-p.slots
+			default:
+				return
+			}
+		}
+	})
+	return nil
+}
+
+// acquire returns an idle socket, or dials a new one once a slot is free, blocking until ctx is done
+func (p *ADBConnectionPool) acquire(ctx context.Context) (net.Conn, error) {
+	for {
+		var conn *pooledConn
+		select {
+		case conn = <-p.idle:
+		default:
+			select {
+			case conn = <-p.idle:
+			case p.slots <- struct{}{}:
+				dialed, err := p.dial(ctx)
+				if err != nil {
+					<-p.slots
+					return nil, err
+				}
+				return dialed, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		if p.expired(conn) {
+			p.discard(conn)
+			continue
+		}
+		return conn.Conn, nil
+	}
+}
+
+// release closes a socket whose command completed and dials a replacement in the background
+func (p *ADBConnectionPool) release(conn net.Conn) {
+	conn.Close()
+	<-p.slots
+	go p.replenish()
+}
+
+// replenish dials an idle socket if a slot is free and the pool is open
+func (p *ADBConnectionPool) replenish() {
+	select {
+	case <-p.done:
+		return
+	case p.slots <- struct{}{}:
+	default:
+		return
+	}
+
+	conn, err := p.dial(context.Background())
+	if err != nil {
+		<-p.slots
+		return
+	}
+
+	select {
+	case p.idle <- &pooledConn{Conn: conn, idleSince: time.Now()}:
+	default:
+		p.discard(&pooledConn{Conn: conn})
+	}
+}
+
+// closeIdleLoop periodically closes sockets that stayed idle for longer than IdleTimeout
+func (p *ADBConnectionPool) closeIdleLoop() {
+	ticker := time.NewTicker(p.IdleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		for n := len(p.idle); n > 0; n-- {
+			select {
+			case conn := <-p.idle:
+				if p.expired(conn) {
+					p.discard(conn)
+				} else {
+					p.idle <- conn
+				}
+			default:
+			}
+		}
+	}
+}
+
+// expired reports whether an idle socket exceeded IdleTimeout
+func (p *ADBConnectionPool) expired(conn *pooledConn) bool {
+	return p.IdleTimeout > 0 && time.Since(conn.idleSince) > p.IdleTimeout
+}
+
+// discard closes a socket and frees its slot
+func (p *ADBConnectionPool) discard(conn *pooledConn) {
+	conn.Close()
+	<-p.slots
+}
+
+// dial opens a socket to the ADB server
+func (p *ADBConnectionPool) dial(ctx context.Context) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect to ADB server at %s: %w", errPoolUnavailable, p.Addr, err)
+	}
+	return conn, nil
+}
+
+// poolableCommand extracts the serial and shell command from `-s <serial> shell <command>` arguments
+// when the device is connected over TCP/IP and the command does not depend on host shell quoting
+func poolableCommand(args []string) (serial, command string, ok bool) {
+	if len(args) != 3 || args[0] != "-s" || !isTCPSerial(args[1]) {
+		return "", "", false
+	}
+
+	command, ok = strings.CutPrefix(args[2], "shell ")
+	if !ok || strings.ContainsAny(command, shellMetacharacters) {
+		return "", "", false
+	}
+	return args[1], command, true
+}
+
+// isTCPSerial reports whether a serial names a device connected over TCP/IP, such as 192.168.1.20:5555
+func isTCPSerial(serial string) bool {
+	_, port, err := net.SplitHostPort(serial)
+	if err != nil {
+		return false
+	}
+	_, err = strconv.Atoi(port)
+	return err == nil
+}
+
+// runShellV2 switches the socket to the device's transport and runs command with the shell v2 protocol,
+// which reports the exit code that the legacy shell protocol loses
+func runShellV2(conn net.Conn, serial, command string) (int, []byte, error) {
+	reader := bufio.NewReader(conn)
+
+	if err := sendADBRequest(conn, reader, "host:transport:"+serial); err != nil {
+		return -1, nil, fmt.Errorf("%w: %w", errPoolUnavailable, err)
+	}
+	if err := sendADBRequest(conn, reader, "shell,v2,raw:"+command); err != nil {
+		return -1, nil, fmt.Errorf("%w: %w", errPoolUnavailable, err)
+	}
+
+	var output []byte
+	header := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return -1, output, fmt.Errorf("shell closed without an exit code: %w", err)
+		}
+
+		payload := make([]byte, binary.LittleEndian.Uint32(header[1:]))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return -1, output, err
+		}
+
+		switch header[0] {
+		case shellV2Stdout, shellV2Stderr:
+			output = append(output, payload...)
+		case shellV2Exit:
+			if len(payload) == 0 {
+				return -1, output, errors.New("empty shell exit packet")
+			}
+			return int(payload[0]), output, nil
+		}
+	}
+}
+
+// sendADBRequest sends a length-prefixed request to the ADB server and waits for OKAY
+func sendADBRequest(conn net.Conn, reader *bufio.Reader, request string) error {
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(request), request); err != nil {
+		return err
+	}
+
+	status := make([]byte, 4)
+	if _, err := io.ReadFull(reader, status); err != nil {
+		return err
+	}
+	if string(status) == "OKAY" {
+		return nil
+	}
+
+	message := readADBMessage(reader)
+	return fmt.Errorf("adb server rejected %q: %s", request, message)
+}
+
+// readADBMessage reads the length-prefixed message that follows a FAIL status
+func readADBMessage(reader *bufio.Reader) string {
+	length := make([]byte, 4)
+	if _, err := io.ReadFull(reader, length); err != nil {
+		return "unknown error"
+	}
+	n, err := strconv.ParseUint(string(length), 16, 32)
+	if err != nil {
+		return "unknown error"
+	}
+
+	message := make([]byte, n)
+	if _, err := io.ReadFull(reader, message); err != nil {
+		return "unknown error"
+	}
+	return string(message)
+}
@@ -0,0 +1,205 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// PreflightCheckName identifies one of the checks run by PreflightCheck
+type PreflightCheckName string
+
+// Preflight checks
+const (
+	PreflightAPILevel      PreflightCheckName = "api_level"
+	PreflightAuthorization PreflightCheckName = "authorization"
+	PreflightShell         PreflightCheckName = "shell"
+	PreflightFRP           PreflightCheckName = "frp"
+	PreflightDeviceOwner   PreflightCheckName = "device_owner"
+	PreflightProfileOwner  PreflightCheckName = "profile_owner"
+	PreflightAdminApps     PreflightCheckName = "admin_apps"
+)
+
+// PreflightIssue is a problem found by a preflight check
+type PreflightIssue struct {
+	Check    PreflightCheckName
+	Message  string
+	Blocking bool  // The device cannot be processed
+	Err      error // Sentinel-wrapping error of the issue, such as ErrFRPActive; nil when there is none
+}
+
+// PreflightResult holds the outcome of the checks run before any disable attempt
+type PreflightResult struct {
+	Serial     string
+	DeviceInfo DeviceInfo
+	AuthStatus AuthStatus
+	MDM        MDMInfo
+	AdminApps  []string
+	SIMStatus  SIMStatus
+	CanProceed bool
+	Issues     []PreflightIssue
+}
+
+// addIssue records an issue, clearing CanProceed when it is blocking
+func (r *PreflightResult) addIssue(check PreflightCheckName, message string, err error, blocking bool) {
+	r.Issues = append(r.Issues, PreflightIssue{Check: check, Message: message, Blocking: blocking, Err: err})
+	if blocking {
+		r.CanProceed = false
+	}
+}
+
+// BlockingIssue returns the issue that prevents the device from being processed, if any
+func (r *PreflightResult) BlockingIssue() (PreflightIssue, bool) {
+	for _, issue := range r.Issues {
+		if issue.Blocking {
+			return issue, true
+		}
+	}
+	return PreflightIssue{}, false
+}
+
+// PreflightCheck checks whether a device can be processed: API level, authorization, shell access,
+// Factory Reset Protection, device and profile owners, and active device admins.
+// Checks stop at the first blocking issue.
+func (a *AndroidLockScreenDisabler) PreflightCheck(serial string) (*PreflightResult, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	return a.preflightCheck(ctx, serial, a.getDeviceInfo(ctx, serial))
+}
+
+// preflightCheck implements PreflightCheck for a device whose info was already collected.
+// It only returns an error when ctx is done before the checks complete.
+func (a *AndroidLockScreenDisabler) preflightCheck(ctx context.Context, serial string, deviceInfo DeviceInfo) (*PreflightResult, error) {
+	result := &PreflightResult{Serial: serial, DeviceInfo: deviceInfo, AuthStatus: AuthStatusAuthorized, SIMStatus: deviceInfo.SIMStatus, CanProceed: true}
+
+	if reason := a.apiLevelMismatch(deviceInfo); reason != "" {
+		result.addIssue(PreflightAPILevel, reason, nil, true)
+		return result, nil
+	}
+
+	// Only look up the authorization state when the shell is unreachable, to explain why
+	if permissionErr := a.checkDevicePermissions(ctx, serial); permissionErr != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.AuthStatus = a.getDeviceAuthorizationStatus(ctx, serial)
+		switch result.AuthStatus {
+		case AuthStatusUnauthorized:
+			result.addIssue(PreflightAuthorization, "device unauthorized", ErrDeviceUnauthorized, true)
+		case AuthStatusOffline:
+			result.addIssue(PreflightAuthorization, "device offline", ErrDeviceOffline, true)
+		default:
+			result.addIssue(PreflightShell, ErrInsufficientPermissions.Error(), permissionErr, true)
+		}
+		return result, nil
+	}
+
+	if a.detectFRP(ctx, serial) {
+		result.addIssue(PreflightFRP, ErrFRPActive.Error(), ErrFRPActive, true)
+		return result, nil
+	}
+
+	// MDM owners restore their lock policy, so changes are usually ineffective
+	if managed, mdm, err := a.detectMDM(ctx, serial); err == nil && managed {
+		result.MDM = mdm
+		if mdm.DeviceOwnerPackage != "" {
+			message := fmt.Sprintf("managed by MDM %s (device owner)", mdm.DeviceOwnerPackage)
+			result.addIssue(PreflightDeviceOwner, message, fmt.Errorf("%w: %s", ErrMDMBlocked, message), !a.cfg.ignoreMDM)
+		}
+		if mdm.ProfileOwnerPackage != "" {
+			// A profile owner of a work profile only enforces the work challenge, not the device lock screen
+			message := fmt.Sprintf("managed by MDM %s (profile owner)", mdm.ProfileOwnerPackage)
+			result.addIssue(PreflightProfileOwner, message, fmt.Errorf("%w: %s", ErrMDMBlocked, message),
+				!a.cfg.ignoreMDM && !mdm.IsWorkProfilePresent)
+		}
+		if !result.CanProceed {
+			return result, nil
+		}
+	}
+
+	if admins, err := a.getDeviceAdminApps(ctx, serial); err == nil && len(admins) > 0 {
+		result.AdminApps = admins
+		result.addIssue(PreflightAdminApps, fmt.Sprintf("device admin apps active: %s", strings.Join(admins, ", ")), nil, false)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParallelPreflight runs device info and permission checks for all devices concurrently, at most as many
+// at a time as the configured concurrency. It returns the devices that passed and a failed or cancelled
+// DeviceResult for each device that didn't.
+func (a *AndroidLockScreenDisabler) ParallelPreflight(ctx context.Context, devices []string) ([]string, []DeviceResult) {
+	a.log(LogLevelInfo, fmt.Sprintf("Running preflight checks on %d device(s)...", len(devices)), "🛫")
+
+	passed := make([]bool, len(devices))
+	failures := make([]*DeviceResult, len(devices))
+	slots := make(chan struct{}, a.cfg.concurrency)
+	var wg sync.WaitGroup
+
+	for i, device := range devices {
+		wg.Add(1)
+		go func(i int, deviceSerial string) {
+			defer wg.Done()
+			deviceTag := fmt.Sprintf("[%s]", a.deviceName(deviceSerial))
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+			}
+
+			if err := ctx.Err(); err != nil {
+				failures[i] = preflightCancelled(deviceSerial, err)
+				return
+			}
+
+			deviceInfo := a.getDeviceInfo(ctx, deviceSerial)
+			a.log(LogLevelDebug, fmt.Sprintf("%s Device: %s %s (Android %s, API %s)", deviceTag,
+				deviceInfo.Manufacturer, deviceInfo.Model, deviceInfo.AndroidVersion, deviceInfo.APILevel), "📋", "device", deviceSerial)
+
+			if err := ctx.Err(); err != nil {
+				failures[i] = preflightCancelled(deviceSerial, err)
+				return
+			}
+
+			preflight, err := a.preflightCheck(ctx, deviceSerial, deviceInfo)
+			if err != nil {
+				failures[i] = preflightCancelled(deviceSerial, err)
+				return
+			}
+			if issue, blocked := preflight.BlockingIssue(); blocked {
+				failures[i] = &DeviceResult{Serial: deviceSerial, Status: StatusFailed, Error: issue.Message, Err: issue.Err}
+				return
+			}
+
+			passed[i] = true
+		}(i, device)
+	}
+
+	wg.Wait()
+
+	// Preserve the input order in both lists
+	ready := make([]string, 0, len(devices))
+	var failed []DeviceResult
+	for i, device := range devices {
+		if passed[i] {
+			ready = append(ready, device)
+		} else if failures[i] != nil {
+			failed = append(failed, *failures[i])
+		}
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Preflight complete: %d passed, %d failed", len(ready), len(failed)), "🛬")
+	return ready, failed
+}
+
+// preflightCancelled returns the result of a device whose preflight was cut short by cancellation
+func preflightCancelled(deviceSerial string, err error) *DeviceResult {
+	return &DeviceResult{Serial: deviceSerial, Status: StatusCancelled, Error: fmt.Sprintf("preflight cancelled: %v", err), Err: err}
+}
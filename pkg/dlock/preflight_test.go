@@ -0,0 +1,88 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyExecutor wraps an ADBExecutor, holding every command briefly and recording
+// the largest number of commands running at the same time
+type concurrencyExecutor struct {
+	ADBExecutor
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (e *concurrencyExecutor) Execute(ctx context.Context, args []string) (int, []byte, error) {
+	running := e.running.Add(1)
+	defer e.running.Add(-1)
+	for peak := e.peak.Load(); running > peak && !e.peak.CompareAndSwap(peak, running); peak = e.peak.Load() {
+	}
+
+	time.Sleep(time.Millisecond)
+	return e.ADBExecutor.Execute(ctx, args)
+}
+
+func TestParallelPreflight(t *testing.T) {
+	a, _ := newMockDisabler(t, map[string]MockResponse{
+		"-s GOOD shell echo":                 ok("test"),
+		"-s GOOD shell settings list secure": ok("lockscreen.disabled=0"),
+		"devices":                            ok("List of devices attached\nGOOD\tdevice\nBAD\tunauthorized\n"),
+	})
+
+	ready, failed := a.ParallelPreflight(context.Background(), []string{"GOOD", "BAD"})
+	if len(ready) != 1 || ready[0] != "GOOD" {
+		t.Errorf("ParallelPreflight() ready = %v, want [GOOD]", ready)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("ParallelPreflight() failed = %v, want one result", failed)
+	}
+	if failed[0].Serial != "BAD" || failed[0].Status != StatusFailed || !errors.Is(failed[0].Err, ErrDeviceUnauthorized) {
+		t.Errorf("ParallelPreflight() failed[0] = %+v, want BAD failed with ErrDeviceUnauthorized", failed[0])
+	}
+}
+
+func TestParallelPreflightCancelled(t *testing.T) {
+	a, _ := newMockDisabler(t, map[string]MockResponse{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ready, failed := a.ParallelPreflight(ctx, []string{"DEV1", "DEV2"})
+	if len(ready) != 0 {
+		t.Errorf("ParallelPreflight() ready = %v, want none", ready)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("ParallelPreflight() failed = %v, want two results", failed)
+	}
+	for _, result := range failed {
+		if result.Status != StatusCancelled || !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("ParallelPreflight() result = %+v, want cancelled", result)
+		}
+	}
+}
+
+func TestParallelPreflightConcurrency(t *testing.T) {
+	executor := &concurrencyExecutor{ADBExecutor: NewMockADBExecutor(map[string]MockResponse{
+		"shell echo":                 ok("test"),
+		"shell settings list secure": ok("lockscreen.disabled=0"),
+	})}
+	a, _ := newMockDisabler(t, nil, WithADBExecutor(executor), WithConcurrency(2))
+
+	devices := make([]string, 8)
+	for i := range devices {
+		devices[i] = fmt.Sprintf("DEV%d", i)
+	}
+
+	ready, _ := a.ParallelPreflight(context.Background(), devices)
+	if len(ready) != len(devices) {
+		t.Errorf("ParallelPreflight() ready = %v, want all devices", ready)
+	}
+	if peak := executor.peak.Load(); peak > 2 {
+		t.Errorf("ParallelPreflight() ran %d commands at once, want at most 2", peak)
+	}
+}
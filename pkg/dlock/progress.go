@@ -0,0 +1,45 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// reportProgress writes a progress line to the progress writer every progress interval until ctx is done,
+// then a final line. Lines look like "PROGRESS 15/30 50% ETA 2m30s", with "ETA unknown" until a device completes.
+func (a *AndroidLockScreenDisabler) reportProgress(ctx context.Context, stats *ProcessingStats) {
+	ticker := time.NewTicker(a.cfg.progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.writeProgress(stats)
+			return
+		case <-ticker.C:
+			a.writeProgress(stats)
+		}
+	}
+}
+
+// writeProgress writes a single progress line. The ETA assumes the remaining devices take the average
+// duration of the completed ones and are processed up to the configured concurrency at a time.
+func (a *AndroidLockScreenDisabler) writeProgress(stats *ProcessingStats) {
+	completed, total, average := stats.progress()
+
+	percent := 100
+	if total > 0 {
+		percent = completed * 100 / total
+	}
+
+	eta := "unknown"
+	if remaining := total - completed; remaining <= 0 {
+		eta = "0s"
+	} else if completed > 0 {
+		batches := (remaining + a.cfg.concurrency - 1) / a.cfg.concurrency
+		eta = (average * time.Duration(batches)).Round(time.Second).String()
+	}
+
+	fmt.Fprintf(a.cfg.progressOutput, "PROGRESS %d/%d %d%% ETA %s\n", completed, total, percent, eta)
+}
@@ -0,0 +1,105 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// propertyNamePattern matches the characters used in system property names, such as ro.product.model
+var propertyNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._\-]+$`)
+
+// validatePropertyName returns ErrInvalidProperty if property cannot be a system property name.
+// Property names end up in device shell commands, so anything else could be used to inject commands.
+func validatePropertyName(property string) error {
+	if !propertyNamePattern.MatchString(property) {
+		return fmt.Errorf("%w: %q", ErrInvalidProperty, property)
+	}
+	return nil
+}
+
+// GetDeviceProperty reads a system property with getprop, such as an OEM specific one. It returns
+// ErrInvalidProperty for a malformed name, ErrPropertyNotFound if the property is not set,
+// ErrDeviceOffline if the device cannot be reached and ErrCommandFailed if getprop fails.
+func (a *AndroidLockScreenDisabler) GetDeviceProperty(serial, property string) (string, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return "", err
+	}
+	return a.getDeviceProperty(context.Background(), serial, property)
+}
+
+// getDeviceProperty implements GetDeviceProperty, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getDeviceProperty(ctx context.Context, serial, property string) (string, error) {
+	if err := validatePropertyName(property); err != nil {
+		return "", err
+	}
+
+	success, output, errorMsg := a.runADBCommand(ctx, "shell getprop "+property, serial)
+	if !success {
+		return "", commandError(serial, errorMsg)
+	}
+	if output == "" {
+		return "", fmt.Errorf("%w: %s", ErrPropertyNotFound, property)
+	}
+	return output, nil
+}
+
+// GetDeviceProperties reads several system properties in a single shell session. Properties that are not set
+// are left out of the map and reported by an ErrPropertyNotFound error returned along with the other values.
+// Otherwise it fails like GetDeviceProperty.
+func (a *AndroidLockScreenDisabler) GetDeviceProperties(serial string, properties []string) (map[string]string, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return nil, err
+	}
+	return a.getDeviceProperties(context.Background(), serial, properties)
+}
+
+// getDeviceProperties implements GetDeviceProperties, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getDeviceProperties(ctx context.Context, serial string, properties []string) (map[string]string, error) {
+	commands := make([]string, 0, len(properties))
+	for _, property := range properties {
+		if err := validatePropertyName(property); err != nil {
+			return nil, err
+		}
+		commands = append(commands, "getprop "+property)
+	}
+
+	results, err := a.runShellCommands(ctx, serial, commands)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(properties))
+	var missing []string
+	for i, result := range results {
+		switch {
+		case result.ExitCode == -1:
+			return nil, commandError(serial, result.Output)
+		case result.ExitCode != 0:
+			return nil, commandError(serial, fmt.Sprintf("getprop %s: exit status %d: %s", properties[i], result.ExitCode, result.Output))
+		case result.Output == "":
+			missing = append(missing, properties[i])
+		default:
+			values[properties[i]] = result.Output
+		}
+	}
+
+	if len(missing) > 0 {
+		return values, fmt.Errorf("%w: %s", ErrPropertyNotFound, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// commandError wraps the error of a failed ADB command in ErrCommandTimeout when it timed out,
+// in ErrDeviceOffline when the device could not be reached and in ErrCommandFailed otherwise
+func commandError(serial, errorMsg string) error {
+	lower := strings.ToLower(errorMsg)
+	if strings.Contains(lower, "timed out") {
+		return fmt.Errorf("%w: %s: %s", ErrCommandTimeout, serial, errorMsg)
+	}
+	if strings.Contains(lower, "offline") || strings.Contains(lower, "not found") || strings.Contains(lower, "no devices") {
+		return fmt.Errorf("%w: %s: %s", ErrDeviceOffline, serial, errorMsg)
+	}
+	return fmt.Errorf("%w: %s", ErrCommandFailed, errorMsg)
+}
@@ -0,0 +1,142 @@
+package dlock
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// htmlReportTemplate renders a self-contained report; all styling is inline so the file works offline
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8"/>
+<title>dlock report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.5em; }
+.summary { display: flex; gap: 1em; margin-bottom: 1.5em; }
+.summary div { border: 1px solid #ddd; border-radius: 6px; padding: 0.75em 1.25em; }
+.summary strong { display: block; font-size: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; font-size: 0.9em; }
+th { background: #f4f4f4; }
+tr.success td.status { color: #1a7f37; font-weight: bold; }
+tr.failed td.status, tr.cancelled td.status { color: #cf222e; font-weight: bold; }
+tr.skipped td.status { color: #9a6700; font-weight: bold; }
+td.error { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Android Lock Screen Disabler Report</h1>
+<p>Generated {{.Generated}}</p>
+<div class="summary">
+<div><strong>{{.Summary.TotalDevices}}</strong>Total</div>
+<div><strong>{{.Summary.SuccessCount}}</strong>Succeeded</div>
+<div><strong>{{len .Summary.FailedDevices}}</strong>Failed</div>
+<div><strong>{{.Summary.CancelledCount}}</strong>Cancelled</div>
+<div><strong>{{.Summary.SkippedCount}}</strong>Skipped</div>
+</div>
+<table>
+<thead>
+<tr><th>Serial</th><th>Alias</th><th>Manufacturer</th><th>Model</th><th>API level</th><th>Lock type</th><th>Status</th><th>Method</th><th>Duration</th><th>Error</th></tr>
+</thead>
+<tbody>
+{{- range .Results}}
+<tr class="{{.Status}}"><td>{{.Serial}}</td><td>{{.Alias}}</td><td>{{.Manufacturer}}</td><td>{{.Model}}</td><td>{{.APILevel}}</td><td>{{.LockType}}</td><td class="status">{{.Status}}</td><td>{{if .MethodUsed}}{{.MethodUsed}}{{end}}</td><td>{{.Duration}}</td><td class="error">{{.Error}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// GenerateHTMLReport writes a self-contained HTML report of a batch run to path
+func GenerateHTMLReport(results []DeviceResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	rounded := make([]DeviceResult, len(results))
+	for i, result := range results {
+		result.Duration = result.Duration.Round(time.Millisecond)
+		rounded[i] = result
+	}
+
+	data := struct {
+		Generated string
+		Summary   BatchSummary
+		Results   []DeviceResult
+	}{
+		Generated: time.Now().Format(time.RFC1123),
+		Summary:   NewBatchSummary(results),
+		Results:   rounded,
+	}
+
+	if err := htmlReportTemplate.Execute(file, data); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return file.Close()
+}
+
+// csvHeader names the DeviceResult fields written by ExportResultsCSV, in column order
+var csvHeader = []string{
+	"Serial", "Alias", "Manufacturer", "Model", "APILevel", "Success", "Status", "MethodUsed",
+	"LockDetected", "LockType", "StartTime", "Duration", "Error", "Warnings",
+}
+
+// ExportResultsCSV writes the results as RFC 4180 CSV with a header row; warnings are joined with "; "
+func ExportResultsCSV(results []DeviceResult, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		record := []string{
+			result.Serial,
+			result.Alias,
+			result.Manufacturer,
+			result.Model,
+			result.APILevel,
+			strconv.FormatBool(result.Success),
+			string(result.Status),
+			strconv.Itoa(result.MethodUsed),
+			strconv.FormatBool(result.LockDetected),
+			string(result.LockType),
+			result.StartTime.Format(time.RFC3339),
+			result.Duration.String(),
+			result.Error,
+			strings.Join(result.Warnings, "; "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteResultsCSVFile writes the results as CSV to path, replacing any existing file
+func WriteResultsCSVFile(results []DeviceResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if err := ExportResultsCSV(results, file); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return file.Close()
+}
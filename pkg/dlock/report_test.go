@@ -0,0 +1,72 @@
+package dlock
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateHTMLReport(t *testing.T) {
+	results := []DeviceResult{
+		{Serial: "DEV1", Manufacturer: "Google", Model: "Pixel 8", APILevel: "34", LockType: LockTypePIN,
+			Success: true, Status: StatusSuccess, MethodUsed: 1, Duration: 42 * time.Second},
+		{Serial: "DEV2", Alias: "rack <2> & shelf", Manufacturer: "samsung", Model: "SM-G991B", APILevel: "33",
+			Status: StatusFailed, Error: `exit status 1: <script>alert("x")</script> & 'quoted'`, Duration: 1500 * time.Millisecond},
+		{Serial: "DEV3", Status: StatusCancelled, Error: "cancelled: context canceled"},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := GenerateHTMLReport(results, path); err != nil {
+		t.Fatalf("GenerateHTMLReport() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading report: %v", err)
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(string(data)))
+	decoder.Strict = true
+	rows := 0
+	var cells []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("report is not valid XML: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local == "tr" {
+				rows++
+			}
+		case xml.CharData:
+			cells = append(cells, string(token))
+		}
+	}
+
+	// One header row and one row per device
+	if rows != len(results)+1 {
+		t.Errorf("report has %d table rows, want %d", rows, len(results)+1)
+	}
+	text := strings.Join(cells, "|")
+	for _, want := range []string{"DEV1", "Pixel 8", "pin", "42s", "rack <2> & shelf", `<script>alert("x")</script>`, "cancelled"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report text does not contain %q", want)
+		}
+	}
+}
+
+func TestGenerateHTMLReportInvalidPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "report.html")
+	if err := GenerateHTMLReport(nil, path); err == nil {
+		t.Error("GenerateHTMLReport() error = nil, want an error for a missing directory")
+	}
+}
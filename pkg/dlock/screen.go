@@ -0,0 +1,60 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// screenStatePattern extracts the display state from `dumpsys display`, e.g. mScreenState=ON
+var screenStatePattern = regexp.MustCompile(`mScreenState=(\w+)`)
+
+// wakefulnessPattern extracts the power state from `dumpsys power`, e.g. mWakefulness=Awake
+var wakefulnessPattern = regexp.MustCompile(`mWakefulness=(\w+)`)
+
+// IsScreenOn reports whether the screen of the device is on, according to `dumpsys display`
+// or, when that does not tell, the wakefulness in `dumpsys power`
+func (a *AndroidLockScreenDisabler) IsScreenOn(serial string) (bool, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return false, err
+	}
+	return a.isScreenOn(context.Background(), serial)
+}
+
+// isScreenOn implements IsScreenOn, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) isScreenOn(ctx context.Context, serial string) (bool, error) {
+	if success, output, _ := a.runADBCommand(ctx, "shell dumpsys display", serial); success {
+		if match := screenStatePattern.FindStringSubmatch(output); match != nil {
+			return match[1] == "ON", nil
+		}
+	}
+
+	success, output, errorMsg := a.runADBCommand(ctx, "shell dumpsys power", serial)
+	if !success {
+		return false, fmt.Errorf("failed to read power state on device %s: %s", serial, errorMsg)
+	}
+	if match := wakefulnessPattern.FindStringSubmatch(output); match != nil {
+		return match[1] == "Awake", nil
+	}
+	return false, fmt.Errorf("screen state not found on device %s", serial)
+}
+
+// WakeScreen turns the screen of the device on with KEYCODE_WAKEUP and waits a second for it to come up
+func (a *AndroidLockScreenDisabler) WakeScreen(serial string) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+	return a.wakeScreen(context.Background(), serial)
+}
+
+// wakeScreen implements WakeScreen, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) wakeScreen(ctx context.Context, serial string) error {
+	if success, _, errorMsg := a.runADBCommand(ctx, "shell input keyevent KEYCODE_WAKEUP", serial); !success {
+		return fmt.Errorf("failed to wake device %s: %s", serial, errorMsg)
+	}
+	if !a.cfg.sleep(ctx, time.Second) {
+		return ctx.Err()
+	}
+	return nil
+}
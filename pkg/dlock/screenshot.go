@@ -0,0 +1,76 @@
+package dlock
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pngSignature is the header every PNG file starts with
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// CaptureScreenshot saves a PNG screenshot of the device screen to localPath
+func (a *AndroidLockScreenDisabler) CaptureScreenshot(serial, localPath string) error {
+	return a.captureScreenshot(context.Background(), serial, localPath)
+}
+
+// captureScreenshot implements CaptureScreenshot, bounding the ADB command by ctx.
+// The PNG is binary, so adb is run through the executor directly rather than runADBCommand, which trims output,
+// reading standard output alone when the executor supports it so adb's diagnostics do not corrupt the image.
+func (a *AndroidLockScreenDisabler) captureScreenshot(parent context.Context, serial, localPath string) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+
+	command := "exec-out screencap -p"
+	args := []string{"-s", serial, command}
+
+	ctx, cancel := context.WithTimeout(parent, a.commandTimeout(command))
+	defer cancel()
+
+	execute := a.cfg.executor.Execute
+	if executor, ok := a.cfg.executor.(ADBStdoutExecutor); ok {
+		execute = executor.ExecuteStdout
+	}
+
+	start := time.Now()
+	exitCode, output, err := execute(ctx, args)
+	a.audit(args, exitCode, output, err, time.Since(start))
+
+	if err != nil {
+		return fmt.Errorf("screencap failed: %w", err)
+	}
+	if exitCode != 0 {
+		return fmt.Errorf("screencap failed: exit status %d", exitCode)
+	}
+	if !bytes.HasPrefix(output, pngSignature) {
+		return fmt.Errorf("screencap did not return a PNG image")
+	}
+
+	return os.WriteFile(localPath, output, 0o644)
+}
+
+// screenshotStep captures a "<serial>-<stage>.png" screenshot into the configured directory, if any.
+// Failures are recorded as warnings so they never abort processing.
+func (a *AndroidLockScreenDisabler) screenshotStep(ctx context.Context, result *DeviceResult, deviceTag, stage string) {
+	if a.cfg.screenshotDir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(a.cfg.screenshotDir, 0o755); err != nil {
+		a.addWarning(result, deviceTag, fmt.Sprintf("Could not create screenshot directory: %v", err))
+		return
+	}
+
+	path := filepath.Join(a.cfg.screenshotDir, safeFileName(result.Serial)+"-"+stage+".png")
+	if err := a.captureScreenshot(ctx, result.Serial, path); err != nil {
+		a.addWarning(result, deviceTag, fmt.Sprintf("Could not capture %s screenshot: %v", stage, err))
+		return
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("%s Saved %s screenshot to %s", deviceTag, stage, path), "📸", "device", result.Serial)
+	result.ScreenshotPaths = append(result.ScreenshotPaths, path)
+}
@@ -0,0 +1,37 @@
+package dlock
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCaptureScreenshotIgnoresADBDiagnostics(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake adb is a shell script")
+	}
+
+	// A fake adb that starts its daemon, as adb does on first use, before printing the image
+	dir := t.TempDir()
+	adb := filepath.Join(dir, "adb")
+	script := "#!/bin/sh\necho '* daemon not running; starting now at tcp:5037' >&2\nprintf '\\211PNG\\r\\n\\032\\nIMAGE'\n"
+	if err := os.WriteFile(adb, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := newMockDisabler(t, nil, WithADBExecutor(RealADBExecutor{Path: adb}))
+	path := filepath.Join(dir, "screen.png")
+	if err := a.CaptureScreenshot(testSerial, path); err != nil {
+		t.Fatalf("CaptureScreenshot() error = %v", err)
+	}
+
+	image, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append(append([]byte{}, pngSignature...), "IMAGE"...); !bytes.Equal(image, want) {
+		t.Errorf("CaptureScreenshot() saved %q, want %q", image, want)
+	}
+}
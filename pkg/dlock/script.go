@@ -0,0 +1,102 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// scriptResponses are canned outputs that walk a device through the full disable flow
+var scriptResponses = map[string]string{
+	"get-state":                   "device",
+	"shell echo 'test'":           "test",
+	"shell settings list secure":  "lockscreen.disabled=0",
+	"shell dumpsys trust":         "isDeviceSecure=true",
+	"shell dumpsys window policy": "keyguardShowing=false",
+}
+
+// ScriptExecutor records ADB commands into a shell script instead of executing them
+type ScriptExecutor struct {
+	mu      sync.Mutex
+	serials []string
+	lines   map[string][]string
+}
+
+// NewScriptExecutor creates a new ScriptExecutor
+func NewScriptExecutor() *ScriptExecutor {
+	return &ScriptExecutor{
+		lines: make(map[string][]string),
+	}
+}
+
+// Execute records the command and returns a canned successful response
+func (s *ScriptExecutor) Execute(_ context.Context, args []string) (int, []byte, error) {
+	deviceSerial, command := splitADBArgs(args)
+	s.appendLine(deviceSerial, "adb "+strings.Join(args, " "))
+	return 0, []byte(scriptResponses[command]), nil
+}
+
+// MarkStep writes a comment naming the step the following commands belong to
+func (s *ScriptExecutor) MarkStep(deviceSerial, step string) {
+	s.appendLine(deviceSerial, "# Step: "+step)
+}
+
+// Script returns the recorded commands as a shell script
+func (s *ScriptExecutor) Script() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString("# Generated by dlock\n")
+
+	// Commands not bound to a device come first
+	if hostLines, ok := s.lines[""]; ok {
+		sb.WriteString("\n# Host commands\n")
+		for _, line := range hostLines {
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	for _, serial := range s.serials {
+		if serial == "" {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n# Device: %s\n", serial))
+		for _, line := range s.lines[serial] {
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// WriteFile writes the recorded script to path and marks it executable
+func (s *ScriptExecutor) WriteFile(path string) error {
+	if err := os.WriteFile(path, []byte(s.Script()), 0755); err != nil {
+		return err
+	}
+	// WriteFile doesn't change the mode of an existing file
+	return os.Chmod(path, 0755)
+}
+
+// appendLine adds a line to the section of the given device
+func (s *ScriptExecutor) appendLine(deviceSerial, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lines[deviceSerial]; !ok {
+		s.serials = append(s.serials, deviceSerial)
+	}
+	s.lines[deviceSerial] = append(s.lines[deviceSerial], line)
+}
+
+// splitADBArgs separates the -s device serial from the remaining command
+func splitADBArgs(args []string) (string, string) {
+	if len(args) >= 2 && args[0] == "-s" {
+		return args[1], strings.Join(args[2:], " ")
+	}
+	return "", strings.Join(args, " ")
+}
@@ -0,0 +1,72 @@
+package dlock
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// serialPattern matches the characters adb uses in device serials, including host:port serials of TCP devices
+var serialPattern = regexp.MustCompile(`^[a-zA-Z0-9._:\-]+$`)
+
+// ValidateDeviceSerial returns ErrInvalidSerial if serial is empty or contains characters adb never uses in a serial.
+// Serials end up in host shell commands, so anything else could be used to inject commands.
+func ValidateDeviceSerial(serial string) error {
+	if !serialPattern.MatchString(serial) {
+		return fmt.Errorf("%w: %q", ErrInvalidSerial, serial)
+	}
+	return nil
+}
+
+// emulatorSerialPrefix starts the serial of every Android Emulator instance, e.g. emulator-5554
+const emulatorSerialPrefix = "emulator-"
+
+// IsEmulator reports whether serial names an Android Emulator instance rather than a physical device
+func IsEmulator(serial string) bool {
+	return strings.HasPrefix(serial, emulatorSerialPrefix)
+}
+
+// checkSerial validates a device serial, logging a warning when it is rejected
+func (a *AndroidLockScreenDisabler) checkSerial(serial string) error {
+	err := ValidateDeviceSerial(serial)
+	if err != nil {
+		a.log(LogLevelWarn, fmt.Sprintf("Rejected device serial: %v", err), "🚫")
+	}
+	return err
+}
+
+// ReadDevicesFromFile reads device serials separated by newlines or whitespace from a file, skipping blank lines
+// and lines starting with #. It returns ErrEmptyFile if the file lists no serial and ErrInvalidSerial if any is invalid.
+func ReadDevicesFromFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read devices file: %w", err)
+	}
+	defer file.Close()
+
+	var devices []string
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, serial := range strings.Fields(line) {
+			if err := ValidateDeviceSerial(serial); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			devices = append(devices, serial)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read devices file %s: %w", path, err)
+	}
+
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrEmptyFile)
+	}
+	return devices, nil
+}
@@ -0,0 +1,165 @@
+// Package server exposes an AndroidLockScreenDisabler over gRPC
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gifflet/dlock/pkg/dlock"
+	dlockpb "github.com/gifflet/dlock/proto"
+)
+
+// Server serves the DlockService defined in proto/dlock.proto
+type Server struct {
+	dlockpb.UnimplementedDlockServiceServer
+
+	disabler   *dlock.AndroidLockScreenDisabler
+	grpcServer *grpc.Server
+}
+
+// NewServer creates a Server that handles requests with the given disabler
+func NewServer(disabler *dlock.AndroidLockScreenDisabler) *Server {
+	s := &Server{
+		disabler:   disabler,
+		grpcServer: grpc.NewServer(),
+	}
+	dlockpb.RegisterDlockServiceServer(s.grpcServer, s)
+	return s
+}
+
+// Serve accepts gRPC connections on lis until Stop or GracefulStop is called
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// GracefulStop stops accepting connections and waits for in-flight requests to finish
+func (s *Server) GracefulStop() {
+	s.grpcServer.GracefulStop()
+}
+
+// Stop closes all connections immediately, cancelling in-flight requests
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
+// ProcessDevices disables the lock screen of the requested devices, or of every connected device when none are given
+func (s *Server) ProcessDevices(ctx context.Context, req *dlockpb.DevicesRequest) (*dlockpb.BatchResult, error) {
+	devices := req.GetSerials()
+	if len(devices) == 0 {
+		devices = s.disabler.GetConnectedDevices()
+	}
+
+	results := s.disabler.ProcessDevices(ctx, devices)
+	summary := dlock.NewBatchSummary(results)
+
+	batch := &dlockpb.BatchResult{
+		Results:        make([]*dlockpb.DeviceResult, 0, len(results)),
+		TotalDevices:   int32(summary.TotalDevices),
+		SuccessCount:   int32(summary.SuccessCount),
+		FailedDevices:  summary.FailedDevices,
+		CancelledCount: int32(summary.CancelledCount),
+		SkippedCount:   int32(summary.SkippedCount),
+	}
+	for _, result := range results {
+		batch.Results = append(batch.Results, toProtoResult(result))
+	}
+	return batch, nil
+}
+
+// GetConnectedDevices lists the serials of the connected devices
+func (s *Server) GetConnectedDevices(ctx context.Context, _ *dlockpb.Empty) (*dlockpb.DeviceList, error) {
+	return &dlockpb.DeviceList{Serials: s.disabler.GetConnectedDevices()}, nil
+}
+
+// GetDeviceInfo returns the model, manufacturer and Android version of a device
+func (s *Server) GetDeviceInfo(ctx context.Context, req *dlockpb.DeviceRequest) (*dlockpb.DeviceInfoResponse, error) {
+	if req.GetSerial() == "" {
+		return nil, status.Error(codes.InvalidArgument, "serial is required")
+	}
+	if err := dlock.ValidateDeviceSerial(req.GetSerial()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	info, err := s.disabler.GetDeviceInfoContext(ctx, req.GetSerial())
+	if err != nil {
+		return nil, deviceError(err)
+	}
+	return &dlockpb.DeviceInfoResponse{
+		Serial:         req.GetSerial(),
+		Model:          info.Model,
+		Manufacturer:   info.Manufacturer,
+		AndroidVersion: info.AndroidVersion,
+		ApiLevel:       info.APILevel,
+		SdkInt:         int32(info.SDKInt),
+		UsbPath:        info.USBPath,
+		TotalMemoryKb:  info.TotalMemoryKB,
+	}, nil
+}
+
+// WatchDevices streams an event every time a device connects, disconnects or changes state until the client goes away
+func (s *Server) WatchDevices(_ *dlockpb.Empty, stream dlockpb.DlockService_WatchDevicesServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	var sendErr error
+	err := s.disabler.WatchDeviceStates(ctx, func(change dlock.DeviceStateChange) {
+		if sendErr != nil {
+			return
+		}
+		if sendErr = stream.Send(&dlockpb.DeviceEvent{Serial: change.Serial, State: change.State}); sendErr != nil {
+			cancel()
+		}
+	})
+
+	switch {
+	case sendErr != nil:
+		return sendErr
+	case errors.Is(err, dlock.ErrNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case err != nil:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return nil
+}
+
+// deviceError converts an error reading a device to a gRPC status
+func deviceError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, dlock.ErrInvalidSerial):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, dlock.ErrDeviceOffline):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, dlock.ErrDeviceUnauthorized):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+}
+
+// toProtoResult converts a device result to its wire representation
+func toProtoResult(result dlock.DeviceResult) *dlockpb.DeviceResult {
+	return &dlockpb.DeviceResult{
+		Serial:       result.Serial,
+		Alias:        result.Alias,
+		Manufacturer: result.Manufacturer,
+		Model:        result.Model,
+		ApiLevel:     result.APILevel,
+		Success:      result.Success,
+		Status:       string(result.Status),
+		MethodUsed:   int32(result.MethodUsed),
+		LockDetected: result.LockDetected,
+		LockType:     string(result.LockType),
+		StartTime:    result.StartTime.Format(time.RFC3339),
+		DurationMs:   result.Duration.Milliseconds(),
+		Error:        result.Error,
+		Warnings:     result.Warnings,
+	}
+}
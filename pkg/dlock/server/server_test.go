@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/gifflet/dlock/pkg/dlock"
+	dlockpb "github.com/gifflet/dlock/proto"
+)
+
+// newTestClient serves a Server backed by executor over an in-memory connection and returns a client for it
+func newTestClient(t *testing.T, executor dlock.ADBExecutor) dlockpb.DlockServiceClient {
+	t.Helper()
+
+	disabler, err := dlock.New(dlock.WithADBExecutor(executor), dlock.WithLogging(false), dlock.WithMaxRetries(0))
+	if err != nil {
+		t.Fatalf("dlock.New() error = %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := NewServer(disabler)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return dlockpb.NewDlockServiceClient(conn)
+}
+
+// blockingExecutor runs no command until ctx is done
+type blockingExecutor struct{}
+
+func (blockingExecutor) Execute(ctx context.Context, _ []string) (int, []byte, error) {
+	<-ctx.Done()
+	return -1, nil, ctx.Err()
+}
+
+func TestGetDeviceInfo(t *testing.T) {
+	client := newTestClient(t, dlock.NewMockADBExecutor(map[string]dlock.MockResponse{
+		"-s PIXEL get-state":                             {Stdout: "device"},
+		"-s PIXEL shell getprop ro.product.model":        {Stdout: "Pixel 8"},
+		"-s PIXEL shell getprop ro.product.manufacturer": {Stdout: "Google"},
+		"-s PIXEL shell getprop ro.build.version.sdk":    {Stdout: "34"},
+		"-s LOCKED get-state":                            {ExitCode: 1, Stdout: "error: device unauthorized."},
+		"-s OFFLINE get-state":                           {Stdout: "offline"},
+		"get-state":                                      {ExitCode: 1, Stdout: "error: device 'GONE' not found"},
+	}))
+
+	tests := []struct {
+		serial    string
+		wantCode  codes.Code
+		wantModel string
+	}{
+		{"PIXEL", codes.OK, "Pixel 8"},
+		{"", codes.InvalidArgument, ""},
+		{"PIXEL; reboot", codes.InvalidArgument, ""},
+		{"GONE", codes.NotFound, ""},
+		{"OFFLINE", codes.NotFound, ""},
+		{"LOCKED", codes.FailedPrecondition, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serial, func(t *testing.T) {
+			info, err := client.GetDeviceInfo(context.Background(), &dlockpb.DeviceRequest{Serial: tt.serial})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("GetDeviceInfo() code = %v, want %v: %v", code, tt.wantCode, err)
+			}
+			if info.GetModel() != tt.wantModel {
+				t.Errorf("GetDeviceInfo() model = %q, want %q", info.GetModel(), tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestGetDeviceInfoDeadline(t *testing.T) {
+	client := newTestClient(t, blockingExecutor{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetDeviceInfo(ctx, &dlockpb.DeviceRequest{Serial: "PIXEL"})
+	if code := status.Code(err); code != codes.DeadlineExceeded {
+		t.Errorf("GetDeviceInfo() code = %v, want %v: %v", code, codes.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetDeviceInfo() returned after %v, want it bounded by the deadline", elapsed)
+	}
+}
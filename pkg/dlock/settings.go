@@ -0,0 +1,274 @@
+package dlock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SettingValue is the value of one Android setting at the time of a snapshot
+type SettingValue struct {
+	Namespace string // secure, system or global
+	Key       string
+	Value     string
+	Present   bool // false when the setting was not defined ("null")
+}
+
+// SettingsSnapshot holds the original values of the settings changed by the disable methods
+type SettingsSnapshot struct {
+	Serial   string
+	TakenAt  time.Time
+	Settings []SettingValue
+}
+
+// modifiedSettings lists the namespace and key of every setting written by the disable methods
+var modifiedSettings = [][2]string{
+	{"secure", "lockscreen.disabled"},
+	{"system", "lockscreen_disabled"},
+	{"global", "device_provisioned"},
+	{"secure", "user_setup_complete"},
+}
+
+// backedUpSettings lists the settings in a snapshot: the ones written by the disable methods,
+// and the animation scales when they are disabled after processing
+func (a *AndroidLockScreenDisabler) backedUpSettings() [][2]string {
+	if !a.cfg.disableAnimations {
+		return modifiedSettings
+	}
+	settings := make([][2]string, 0, len(modifiedSettings)+len(animationSettings))
+	settings = append(settings, modifiedSettings...)
+	return append(settings, animationSettings...)
+}
+
+// BackupDeviceSettings reads the settings changed by the disable methods, and the animation scales if
+// WithDisableAnimations is set, so they can be restored later
+func (a *AndroidLockScreenDisabler) BackupDeviceSettings(serial string) (*SettingsSnapshot, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return nil, err
+	}
+	return a.backupDeviceSettings(context.Background(), serial)
+}
+
+// backupDeviceSettings implements BackupDeviceSettings, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) backupDeviceSettings(ctx context.Context, serial string) (*SettingsSnapshot, error) {
+	snapshot := &SettingsSnapshot{Serial: serial, TakenAt: time.Now()}
+
+	for _, setting := range a.backedUpSettings() {
+		namespace, key := setting[0], setting[1]
+		success, output, errorMsg := a.runADBCommand(ctx, fmt.Sprintf("shell settings get %s %s", namespace, key), serial)
+		if !success {
+			return nil, fmt.Errorf("failed to read %s setting %s: %s", namespace, key, errorMsg)
+		}
+		snapshot.Settings = append(snapshot.Settings, SettingValue{
+			Namespace: namespace,
+			Key:       key,
+			Value:     output,
+			Present:   output != "null",
+		})
+	}
+
+	return snapshot, nil
+}
+
+// RestoreDeviceSettings writes every setting of a snapshot back to its original value,
+// deleting settings that were not defined when the snapshot was taken. It returns ErrInvalidSetting
+// without changing anything if the snapshot names a setting outside the secure, system and global namespaces.
+func (a *AndroidLockScreenDisabler) RestoreDeviceSettings(serial string, snap *SettingsSnapshot) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+	return a.restoreDeviceSettings(context.Background(), serial, snap)
+}
+
+// restoreDeviceSettings implements RestoreDeviceSettings, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) restoreDeviceSettings(ctx context.Context, serial string, snap *SettingsSnapshot) error {
+	if snap == nil {
+		return fmt.Errorf("no settings snapshot to restore")
+	}
+	for _, setting := range snap.Settings {
+		if err := validateSetting(setting.Namespace, setting.Key); err != nil {
+			return err
+		}
+	}
+
+	var failed []string
+	for _, setting := range snap.Settings {
+		command := fmt.Sprintf("shell settings delete %s %s", setting.Namespace, setting.Key)
+		if setting.Present {
+			command = fmt.Sprintf("shell settings put %s %s %s", setting.Namespace, setting.Key, shellArg(setting.Value))
+		}
+		if success, _, _ := a.runADBCommand(ctx, command, serial); !success {
+			failed = append(failed, setting.Namespace+"/"+setting.Key)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restore %d setting(s): %v", len(failed), failed)
+	}
+	a.log(LogLevelInfo, fmt.Sprintf("Restored original settings on device %s", a.deviceName(serial)), "↩️", "device", serial)
+	return nil
+}
+
+// settingsNamespaces are the Android settings tables compared by WithSettingsDiff
+var settingsNamespaces = []string{"secure", "system", "global"}
+
+// settingKeyPattern matches the characters used in setting keys, such as lockscreen.disabled
+var settingKeyPattern = regexp.MustCompile(`^[\w.\-]+$`)
+
+// validateSettingsNamespace returns ErrInvalidSetting if namespace is not one of settingsNamespaces
+func validateSettingsNamespace(namespace string) error {
+	for _, known := range settingsNamespaces {
+		if namespace == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown namespace %q", ErrInvalidSetting, namespace)
+}
+
+// validateSetting returns ErrInvalidSetting if namespace and key cannot name a setting.
+// Both end up in device shell commands, so anything else could be used to inject commands.
+func validateSetting(namespace, key string) error {
+	if err := validateSettingsNamespace(namespace); err != nil {
+		return err
+	}
+	if !settingKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key %q", ErrInvalidSetting, key)
+	}
+	return nil
+}
+
+// SettingsDiff is a setting whose value changed; an empty Before or After means the key was absent
+type SettingsDiff struct {
+	Key    string `json:"key"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DumpSettings returns every key=value pair of a settings namespace (secure, system or global).
+// It returns ErrInvalidSetting for any other namespace.
+func (a *AndroidLockScreenDisabler) DumpSettings(serial string, namespace string) (map[string]string, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return nil, err
+	}
+	return a.dumpSettings(context.Background(), serial, namespace)
+}
+
+// dumpSettings implements DumpSettings, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) dumpSettings(ctx context.Context, serial string, namespace string) (map[string]string, error) {
+	if err := validateSettingsNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	success, output, errorMsg := a.runADBCommand(ctx, "shell settings list "+namespace, serial)
+	if !success {
+		return nil, fmt.Errorf("failed to list %s settings on device %s: %s", namespace, serial, errorMsg)
+	}
+
+	settings := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" {
+			a.log(LogLevelDebug, fmt.Sprintf("Skipping unparsable %s setting line on device %s: %q", namespace, a.deviceName(serial), line), "❓", "device", serial)
+			continue
+		}
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// GetSecureSettings returns every key=value pair of the secure settings namespace
+func (a *AndroidLockScreenDisabler) GetSecureSettings(serial string) (map[string]string, error) {
+	return a.DumpSettings(serial, "secure")
+}
+
+// GetSystemSettings returns every key=value pair of the system settings namespace
+func (a *AndroidLockScreenDisabler) GetSystemSettings(serial string) (map[string]string, error) {
+	return a.DumpSettings(serial, "system")
+}
+
+// GetGlobalSettings returns every key=value pair of the global settings namespace
+func (a *AndroidLockScreenDisabler) GetGlobalSettings(serial string) (map[string]string, error) {
+	return a.DumpSettings(serial, "global")
+}
+
+// ExportAllSettings writes the secure, system and global settings of a device to outputPath as a JSON object
+// keyed by namespace, for diagnosing unusual device behavior
+func (a *AndroidLockScreenDisabler) ExportAllSettings(serial, outputPath string) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+
+	dumps, err := a.dumpAllSettings(context.Background(), serial)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(dumps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := os.WriteFile(outputPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write settings to %s: %w", outputPath, err)
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Exported settings of device %s to %s", a.deviceName(serial), outputPath), "💾", "device", serial)
+	return nil
+}
+
+// DiffSettings returns the keys whose value differs between two dumps, sorted by key
+func DiffSettings(before, after map[string]string) []SettingsDiff {
+	var diffs []SettingsDiff
+	for key, beforeValue := range before {
+		if afterValue, ok := after[key]; !ok || afterValue != beforeValue {
+			diffs = append(diffs, SettingsDiff{Key: key, Before: beforeValue, After: afterValue})
+		}
+	}
+	for key, afterValue := range after {
+		if _, ok := before[key]; !ok {
+			diffs = append(diffs, SettingsDiff{Key: key, After: afterValue})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+	return diffs
+}
+
+// dumpAllSettings dumps every namespace compared by WithSettingsDiff, keyed by namespace
+func (a *AndroidLockScreenDisabler) dumpAllSettings(ctx context.Context, serial string) (map[string]map[string]string, error) {
+	dumps := make(map[string]map[string]string, len(settingsNamespaces))
+	for _, namespace := range settingsNamespaces {
+		settings, err := a.dumpSettings(ctx, serial, namespace)
+		if err != nil {
+			return nil, err
+		}
+		dumps[namespace] = settings
+	}
+	return dumps, nil
+}
+
+// recordSettingsDiff dumps the settings again, logs what changed since before and stores it on the result.
+// Keys are prefixed with their namespace, such as "secure/lockscreen.disabled".
+func (a *AndroidLockScreenDisabler) recordSettingsDiff(ctx context.Context, result *DeviceResult, deviceTag string, before map[string]map[string]string) {
+	after, err := a.dumpAllSettings(ctx, result.Serial)
+	if err != nil {
+		a.addWarning(result, deviceTag, fmt.Sprintf("Could not dump settings after processing: %v", err))
+		return
+	}
+
+	for _, namespace := range settingsNamespaces {
+		for _, diff := range DiffSettings(before[namespace], after[namespace]) {
+			diff.Key = namespace + "/" + diff.Key
+			a.log(LogLevelInfo, fmt.Sprintf("%s Setting %s changed: %q -> %q", deviceTag, diff.Key, diff.Before, diff.After), "📝", "device", result.Serial)
+			result.SettingsDiff = append(result.SettingsDiff, diff)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package dlock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRestoreDeviceSettings(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{"shell settings": ok("")})
+
+	snap := &SettingsSnapshot{Serial: testSerial, Settings: []SettingValue{
+		{Namespace: "secure", Key: "lockscreen.disabled", Value: "0", Present: true},
+		{Namespace: "system", Key: "lockscreen_disabled", Present: false},
+		{Namespace: "global", Key: "device_name", Value: "Lab phone; reboot", Present: true},
+		{Namespace: "secure", Key: "lock_screen_owner_info", Value: "", Present: true},
+	}}
+	if err := a.RestoreDeviceSettings(testSerial, snap); err != nil {
+		t.Fatalf("RestoreDeviceSettings() error = %v", err)
+	}
+
+	prefix := "-s " + testSerial + " "
+	want := []string{
+		prefix + "shell settings put secure lockscreen.disabled " + shellArg("0"),
+		prefix + "shell settings delete system lockscreen_disabled",
+		prefix + "shell settings put global device_name " + shellArg("Lab phone; reboot"),
+		prefix + "shell settings put secure lock_screen_owner_info " + shellArg(""),
+	}
+	calls := mock.Calls()
+	if len(calls) != len(want) {
+		t.Fatalf("RestoreDeviceSettings() ran %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("command %d = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestRestoreDeviceSettingsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting SettingValue
+	}{
+		{"unknown namespace", SettingValue{Namespace: "config", Key: "lockscreen.disabled", Present: true}},
+		{"namespace injection", SettingValue{Namespace: "secure; reboot;", Key: "lockscreen.disabled"}},
+		{"key injection", SettingValue{Namespace: "secure", Key: "lockscreen.disabled $(reboot)", Value: "1", Present: true}},
+		{"empty key", SettingValue{Namespace: "secure", Key: ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell settings": ok("")})
+
+			snap := &SettingsSnapshot{Serial: testSerial, Settings: []SettingValue{
+				{Namespace: "secure", Key: "lockscreen.disabled", Value: "0", Present: true},
+				tt.setting,
+			}}
+			if err := a.RestoreDeviceSettings(testSerial, snap); !errors.Is(err, ErrInvalidSetting) {
+				t.Errorf("RestoreDeviceSettings() error = %v, want %v", err, ErrInvalidSetting)
+			}
+			if calls := mock.Calls(); len(calls) != 0 {
+				t.Errorf("RestoreDeviceSettings() ran %v, want nothing restored", calls)
+			}
+		})
+	}
+}
+
+func TestDumpSettings(t *testing.T) {
+	a, _ := newMockDisabler(t, map[string]MockResponse{
+		"shell settings list secure": ok("lockscreen.disabled=0\nandroid_id=abc=def\r\nunparsable\n\nempty=\n"),
+	})
+
+	settings, err := a.DumpSettings(testSerial, "secure")
+	if err != nil {
+		t.Fatalf("DumpSettings() error = %v", err)
+	}
+	want := map[string]string{"lockscreen.disabled": "0", "android_id": "abc=def", "empty": ""}
+	if len(settings) != len(want) {
+		t.Errorf("DumpSettings() = %v, want %v", settings, want)
+	}
+	for key, value := range want {
+		if got, ok := settings[key]; !ok || got != value {
+			t.Errorf("DumpSettings()[%q] = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestDumpSettingsInvalidNamespace(t *testing.T) {
+	for _, namespace := range []string{"", "config", "secure; reboot", "SECURE"} {
+		t.Run(namespace, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"shell settings list": ok("")})
+
+			if _, err := a.DumpSettings(testSerial, namespace); !errors.Is(err, ErrInvalidSetting) {
+				t.Errorf("DumpSettings(%q) error = %v, want %v", namespace, err, ErrInvalidSetting)
+			}
+			if calls := mock.Calls(); len(calls) != 0 {
+				t.Errorf("DumpSettings(%q) ran %v, want no adb invocation", namespace, calls)
+			}
+		})
+	}
+}
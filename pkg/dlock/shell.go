@@ -0,0 +1,164 @@
+package dlock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunShellCommands runs shell commands on a device in a single `adb shell` session, which saves spawning an adb
+// process and setting up an ADB connection per command. Commands run in order and independently of each other's
+// exit code. Executors that cannot feed standard input, the connection pool and dry runs of mutating commands
+// fall back to one adb invocation per command. It only returns an error if the serial is invalid or the device
+// run was cancelled.
+func (a *AndroidLockScreenDisabler) RunShellCommands(serial string, commands []string) ([]CommandResult, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return nil, err
+	}
+	return a.runShellCommands(context.Background(), serial, commands)
+}
+
+// runShellCommands implements RunShellCommands, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) runShellCommands(ctx context.Context, serial string, commands []string) ([]CommandResult, error) {
+	if len(commands) == 0 {
+		return []CommandResult{}, nil
+	}
+
+	if a.canRunShellSession(commands) {
+		if results, ok := a.runShellSession(ctx, serial, commands); ok {
+			return results, nil
+		}
+		a.log(LogLevelDebug, fmt.Sprintf("[%s] Shell session failed, running commands one by one", a.deviceName(serial)), "🐚", "device", serial)
+	}
+
+	results := make([]CommandResult, 0, len(commands))
+	for _, command := range commands {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		success, output, errorMsg := a.runADBCommand(ctx, "shell "+command, serial)
+		result := CommandResult{Command: command, Output: output}
+		if !success {
+			result.ExitCode, result.Output = parseExitError(errorMsg)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// canRunShellSession reports whether commands can be batched into a single adb shell session
+func (a *AndroidLockScreenDisabler) canRunShellSession(commands []string) bool {
+	if _, ok := a.cfg.executor.(ADBInputExecutor); !ok {
+		return false
+	}
+	// Pooled commands already skip spawning adb, and they would be lost in a session
+	if a.cfg.connPool != nil {
+		return false
+	}
+	if a.cfg.dryRun {
+		for _, command := range commands {
+			if isMutatingCommand("shell " + command) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// runShellSession feeds every command to one adb shell session, following each with a line carrying a random
+// delimiter, its index and its exit code. It reports false if the session failed or its output cannot be split.
+func (a *AndroidLockScreenDisabler) runShellSession(ctx context.Context, serial string, commands []string) ([]CommandResult, bool) {
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	delimiter := "__DLOCK_" + hex.EncodeToString(nonce) + "__"
+
+	var script strings.Builder
+	var timeout time.Duration
+	for i, command := range commands {
+		// A subshell keeps an exit in the command from ending the session, and merging stderr
+		// per command keeps it from ending up after the delimiter line
+		fmt.Fprintf(&script, "(\n%s\n) 2>&1\necho \"%s %d $?\"\n", command, delimiter, i)
+		timeout += a.commandTimeout("shell " + command)
+	}
+	script.WriteString("exit\n")
+
+	success, output, errorMsg := a.executeADBInvocation(ctx, []string{"-s", serial, "shell"}, timeout, []byte(script.String()))
+	if !success {
+		a.log(LogLevelDebug, fmt.Sprintf("[%s] Shell session error: %s", a.deviceName(serial), errorMsg), "🐚", "device", serial)
+		return nil, false
+	}
+
+	return parseShellSession(output, delimiter, commands)
+}
+
+// parseShellSession splits the output of a shell session into the result of each command
+func parseShellSession(output, delimiter string, commands []string) ([]CommandResult, bool) {
+	results := make([]CommandResult, 0, len(commands))
+	var commandOutput strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+
+		idx := strings.Index(line, delimiter+" ")
+		if idx == -1 {
+			commandOutput.WriteString(line + "\n")
+			continue
+		}
+		// Output not ending in a newline shares its last line with the delimiter
+		commandOutput.WriteString(line[:idx])
+
+		fields := strings.Fields(line[idx+len(delimiter):])
+		if len(fields) != 2 || fields[0] != strconv.Itoa(len(results)) || len(results) == len(commands) {
+			return nil, false
+		}
+		exitCode, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, false
+		}
+
+		results = append(results, CommandResult{
+			Command:  commands[len(results)],
+			Output:   strings.TrimSpace(commandOutput.String()),
+			ExitCode: exitCode,
+		})
+		commandOutput.Reset()
+	}
+
+	if len(results) != len(commands) {
+		return nil, false
+	}
+	return results, true
+}
+
+// parseExitError extracts the exit code and output from an "exit status N: output" error of runADBCommand.
+// If the command did not run, the exit code is -1 and the output is the error.
+func parseExitError(errorMsg string) (int, string) {
+	var exitCode int
+	if _, err := fmt.Sscanf(errorMsg, "exit status %d", &exitCode); err != nil {
+		return -1, errorMsg
+	}
+	if _, output, found := strings.Cut(errorMsg, ": "); found {
+		return exitCode, output
+	}
+	return exitCode, ""
+}
+
+// shellArg quotes s as a single literal argument of an `adb shell` command. The command line is parsed twice,
+// first by the host shell running adb and then by the device shell, so s is single-quoted for the device and
+// the result quoted again for the host: single quotes for sh, double quotes for cmd, which leaves them alone.
+func shellArg(s string) string {
+	deviceArg := singleQuote(s)
+	if runtime.GOOS == "windows" {
+		return `"` + deviceArg + `"`
+	}
+	return singleQuote(deviceArg)
+}
+
+// singleQuote quotes s for a POSIX shell, which expands nothing between single quotes
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,44 @@
+package dlock
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+)
+
+// printfShell runs printf with arg through sh -c and returns what it printed
+func printfShell(t *testing.T, arg string) string {
+	t.Helper()
+
+	output, err := exec.Command("sh", "-c", "printf '%s' "+arg).Output()
+	if err != nil {
+		t.Fatalf("sh -c printf %s: %v", arg, err)
+	}
+	return string(output)
+}
+
+func TestShellArg(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shellArg quotes for cmd on Windows")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	for _, value := range []string{
+		"",
+		"1",
+		"com.example/.Admin$Receiver",
+		"two words",
+		"it's",
+		`"double" \backslash`,
+		"$(reboot); `reboot` | reboot & reboot",
+		"*",
+	} {
+		// The host shell hands adb the argument the device shell parses in turn
+		deviceArg := printfShell(t, shellArg(value))
+		if got := printfShell(t, deviceArg); got != value {
+			t.Errorf("shellArg(%q) = %s, reaches the device command as %q", value, shellArg(value), got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SIMStatus is the state of a SIM card as reported by Android, such as READY or PIN_REQUIRED
+type SIMStatus string
+
+// SIM card states
+const (
+	SIMStatusUnknown     SIMStatus = ""
+	SIMStatusAbsent      SIMStatus = "ABSENT"
+	SIMStatusReady       SIMStatus = "READY"
+	SIMStatusPINRequired SIMStatus = "PIN_REQUIRED"
+	SIMStatusPUKRequired SIMStatus = "PUK_REQUIRED"
+)
+
+// Locked reports whether the SIM card waits for its PIN or PUK, which only someone at the device can enter
+func (s SIMStatus) Locked() bool {
+	return s == SIMStatusPINRequired || s == SIMStatusPUKRequired
+}
+
+// simCommands read the SIM state: the telephony registry dump first, then the gsm.sim.state property,
+// which lists the state of every slot on devices that do not dump it
+var simCommands = []string{"dumpsys telephony.registry", "getprop gsm.sim.state"}
+
+// simStatePattern matches the SIM state of a slot in `dumpsys telephony.registry`, e.g. mSimState=PIN_REQUIRED
+var simStatePattern = regexp.MustCompile(`(?i)simState\s*[=:]\s*([A-Z_]+)`)
+
+// HasSIMPINLock reports whether a SIM card of the device is locked by a PIN (or PUK). Unlike the lock screen,
+// a SIM PIN cannot be bypassed over ADB.
+func (a *AndroidLockScreenDisabler) HasSIMPINLock(serial string) (bool, error) {
+	if err := a.checkSerial(serial); err != nil {
+		return false, err
+	}
+	status, err := a.getSIMStatus(context.Background(), serial)
+	return status.Locked(), err
+}
+
+// getSIMStatus reads the SIM state of a device, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getSIMStatus(ctx context.Context, serial string) (SIMStatus, error) {
+	results, err := a.runShellCommands(ctx, serial, simCommands)
+	if err != nil {
+		return SIMStatusUnknown, err
+	}
+	if results[0].ExitCode != 0 && results[1].ExitCode != 0 {
+		return SIMStatusUnknown, fmt.Errorf("failed to read SIM state on device %s: %s", serial, results[0].Output)
+	}
+	return parseSIMStatus(results[0], results[1]), nil
+}
+
+// parseSIMStatus returns the state of the SIM cards found in the telephony registry dump or, failing that,
+// the gsm.sim.state property. A locked slot wins over the others, and an empty slot loses to any other known state.
+func parseSIMStatus(registry, property CommandResult) SIMStatus {
+	var states []SIMStatus
+	if registry.ExitCode == 0 {
+		for _, match := range simStatePattern.FindAllStringSubmatch(registry.Output, -1) {
+			states = append(states, SIMStatus(strings.ToUpper(match[1])))
+		}
+	}
+	if len(states) == 0 && property.ExitCode == 0 {
+		for _, state := range strings.Split(property.Output, ",") {
+			if state = strings.TrimSpace(state); state != "" {
+				states = append(states, SIMStatus(strings.ToUpper(state)))
+			}
+		}
+	}
+
+	status := SIMStatusUnknown
+	for _, state := range states {
+		if state.Locked() {
+			return state
+		}
+		if state == "UNKNOWN" {
+			continue
+		}
+		if status == SIMStatusUnknown || status == SIMStatusAbsent {
+			status = state
+		}
+	}
+	return status
+}
@@ -0,0 +1,97 @@
+package dlock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// slackTimeout bounds how long posting to a Slack webhook may take
+const slackTimeout = 10 * time.Second
+
+// SlackMessage is the payload of a Slack incoming webhook. Text is shown in notifications and
+// as the fallback when Blocks, which lay out the message, cannot be rendered.
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Slack layout block such as "header" or "section"
+type SlackBlock struct {
+	Type   string      `json:"type"`
+	Text   *SlackText  `json:"text,omitempty"`
+	Fields []SlackText `json:"fields,omitempty"`
+}
+
+// SlackText is a text object of a Slack block, either "plain_text" or "mrkdwn"
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// validateSlackWebhook checks that webhookURL is an absolute HTTPS URL
+func validateSlackWebhook(webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return fmt.Errorf("invalid Slack webhook URL: %w", err)
+	}
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("invalid Slack webhook URL %q: must be an https:// URL", webhookURL)
+	}
+	return nil
+}
+
+// SendSlackNotification posts msg to a Slack incoming webhook
+func SendSlackNotification(webhookURL string, msg SlackMessage) error {
+	if err := validateSlackWebhook(webhookURL); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	client := &http.Client{Timeout: slackTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// batchSlackMessage formats the outcome of a batch as a Slack message
+func (a *AndroidLockScreenDisabler) batchSlackMessage(stats *ProcessingStats, elapsed time.Duration) SlackMessage {
+	successCount, failedDevices, totalDevices := stats.GetStats()
+	summary := fmt.Sprintf("dlock batch complete: %d/%d devices successful", successCount, totalDevices)
+
+	failed := "none"
+	if len(failedDevices) > 0 {
+		failed = a.deviceNames(failedDevices)
+	}
+
+	return SlackMessage{
+		Text: summary,
+		Blocks: []SlackBlock{
+			{Type: "header", Text: &SlackText{Type: "plain_text", Text: "dlock: batch complete"}},
+			{Type: "section", Fields: []SlackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Total devices:*\n%d", totalDevices)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Duration:*\n%s", elapsed.Round(time.Second))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Successful:*\n%d", successCount)},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Failed:*\n%d", len(failedDevices))},
+			}},
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: fmt.Sprintf("*Failed devices:* %s", failed)}},
+		},
+	}
+}
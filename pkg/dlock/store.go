@@ -0,0 +1,164 @@
+package dlock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ProcessedDeviceStore remembers which devices had their lock screen disabled successfully,
+// so processing them again can be skipped. Implementations must be safe for concurrent use.
+type ProcessedDeviceStore interface {
+	MarkProcessed(serial string) error
+	IsProcessed(serial string) (bool, error)
+	Clear() error
+}
+
+// memoryStore is a ProcessedDeviceStore kept in memory
+type memoryStore struct {
+	mu        sync.Mutex
+	processed map[string]bool
+}
+
+// InMemoryStore creates a ProcessedDeviceStore kept in memory. It is the default,
+// with a new store for every batch or watch so only devices repeated within it are skipped.
+func InMemoryStore() ProcessedDeviceStore {
+	return &memoryStore{processed: make(map[string]bool)}
+}
+
+// MarkProcessed implements ProcessedDeviceStore
+func (s *memoryStore) MarkProcessed(serial string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed[serial] = true
+	return nil
+}
+
+// IsProcessed implements ProcessedDeviceStore
+func (s *memoryStore) IsProcessed(serial string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.processed[serial], nil
+}
+
+// Clear implements ProcessedDeviceStore
+func (s *memoryStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed = make(map[string]bool)
+	return nil
+}
+
+// fileStore is a ProcessedDeviceStore persisted as a JSON array of serials
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// FileStore creates a ProcessedDeviceStore persisted to a JSON file at path, so devices processed by
+// an earlier run are skipped. The file is created on the first successful device.
+func FileStore(path string) ProcessedDeviceStore {
+	return &fileStore{path: path}
+}
+
+// MarkProcessed implements ProcessedDeviceStore
+func (s *fileStore) MarkProcessed(serial string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	processed, err := s.load()
+	if err != nil {
+		return err
+	}
+	if processed[serial] {
+		return nil
+	}
+	processed[serial] = true
+	return s.save(processed)
+}
+
+// IsProcessed implements ProcessedDeviceStore
+func (s *fileStore) IsProcessed(serial string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	processed, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	return processed[serial], nil
+}
+
+// Clear implements ProcessedDeviceStore
+func (s *fileStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(map[string]bool{})
+}
+
+// load reads the processed serials, treating a missing file as empty
+func (s *fileStore) load() (map[string]bool, error) {
+	processed := make(map[string]bool)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return processed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read processed devices: %w", err)
+	}
+
+	var serials []string
+	if err := json.Unmarshal(data, &serials); err != nil {
+		return nil, fmt.Errorf("failed to parse processed devices %s: %w", s.path, err)
+	}
+	for _, serial := range serials {
+		processed[serial] = true
+	}
+	return processed, nil
+}
+
+// save writes the processed serials to a temporary file and renames it over the store,
+// so an interrupted write never leaves a truncated file behind
+func (s *fileStore) save(processed map[string]bool) error {
+	serials := make([]string, 0, len(processed))
+	for serial := range processed {
+		serials = append(serials, serial)
+	}
+	sort.Strings(serials)
+
+	data, err := json.MarshalIndent(serials, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write processed devices: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write processed devices: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write processed devices: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write processed devices: %w", err)
+	}
+	return nil
+}
+
+// processedStore returns the configured ProcessedDeviceStore, or the in-memory store of the batch
+func (a *AndroidLockScreenDisabler) processedStore(stats *ProcessingStats) ProcessedDeviceStore {
+	if a.cfg.processedStore != nil {
+		return a.cfg.processedStore
+	}
+	return stats.processed
+}
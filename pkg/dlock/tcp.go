@@ -0,0 +1,139 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hostnamePattern matches DNS hostnames and IPv4 addresses accepted by adb connect
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+
+// InvalidAddressError is returned when a host/port pair cannot be used with adb connect
+type InvalidAddressError struct {
+	Host   string
+	Port   int
+	Reason string
+}
+
+// Error implements the error interface
+func (e *InvalidAddressError) Error() string {
+	return fmt.Sprintf("invalid address %s:%d: %s", e.Host, e.Port, e.Reason)
+}
+
+// ConnectTCPDevice connects to a device over TCP/IP and returns its ADB serial
+func (a *AndroidLockScreenDisabler) ConnectTCPDevice(host string, port int) (string, error) {
+	if port < 1 || port > 65535 {
+		return "", &InvalidAddressError{Host: host, Port: port, Reason: "port must be between 1 and 65535"}
+	}
+	if net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		return "", &InvalidAddressError{Host: host, Port: port, Reason: "host is not a valid hostname or IP address"}
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	a.log(LogLevelInfo, fmt.Sprintf("Connecting to device at %s...", address), "🌐")
+
+	success, output, errorMsg := a.runADBCommand(context.Background(), "connect "+address, "")
+	if !success {
+		return "", fmt.Errorf("failed to connect to %s: %s", address, errorMsg)
+	}
+
+	// adb connect exits successfully even when the connection fails, so inspect the message
+	lowerOutput := strings.ToLower(output)
+	if strings.Contains(lowerOutput, "failed") || strings.Contains(lowerOutput, "cannot") ||
+		strings.Contains(lowerOutput, "unable") || !strings.Contains(lowerOutput, "connected to") {
+		return "", fmt.Errorf("failed to connect to %s: %s", address, output)
+	}
+
+	// Both "connected to X" and "already connected to X" report the resulting serial
+	serial := address
+	if idx := strings.Index(lowerOutput, "connected to "); idx != -1 {
+		if fields := strings.Fields(output[idx+len("connected to "):]); len(fields) > 0 {
+			serial = fields[0]
+		}
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Connected to device %s", a.deviceName(serial)), "✅")
+	return serial, nil
+}
+
+// pairingCodePattern matches the six-digit code shown by Android's wireless debugging pairing dialog
+var pairingCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// pairedGUIDPattern extracts the device GUID from the output of a successful adb pair
+var pairedGUIDPattern = regexp.MustCompile(`\[guid=([^\]\s]+)\]`)
+
+// mdnsServiceSuffix is appended to the device GUID in the serial adb uses for devices it discovers over mDNS
+const mdnsServiceSuffix = "._adb-tls-connect._tcp"
+
+// transportOf infers how a device is connected from its serial: host:port serials and mDNS service names are TCP
+func transportOf(serial string) TransportType {
+	if isTCPSerial(serial) || strings.HasSuffix(serial, mdnsServiceSuffix) {
+		return TransportTCP
+	}
+	return TransportUSB
+}
+
+// PairDevice pairs with an Android 11+ device using wireless debugging (`adb pair host:port code`).
+// The pairing port and code are shown under Developer options > Wireless debugging > Pair device with pairing code.
+// It returns the serial adb assigns once it discovers the paired device over mDNS. Pairing does not connect
+// the device, so when mDNS is unavailable, or adb does not report the device GUID, call ConnectTCPDevice with
+// the address and port shown on the Wireless debugging screen, which differs from the pairing port.
+func (a *AndroidLockScreenDisabler) PairDevice(ctx context.Context, host string, pairingPort int, pairingCode string) (string, error) {
+	if pairingPort < 1 || pairingPort > 65535 {
+		return "", &InvalidAddressError{Host: host, Port: pairingPort, Reason: "port must be between 1 and 65535"}
+	}
+	if net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		return "", &InvalidAddressError{Host: host, Port: pairingPort, Reason: "host is not a valid hostname or IP address"}
+	}
+	if !pairingCodePattern.MatchString(pairingCode) {
+		return "", fmt.Errorf("pairing code must be the 6 digits shown on the device")
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(pairingPort))
+	a.log(LogLevelInfo, fmt.Sprintf("Pairing with device at %s...", address), "🔗")
+
+	success, output, errorMsg := a.runADBCommand(ctx, "pair "+address+" "+pairingCode, "")
+	if !success {
+		return "", fmt.Errorf("failed to pair with %s: %s", address, errorMsg)
+	}
+
+	// adb pair may exit successfully even when pairing fails, so inspect the message
+	if !strings.Contains(strings.ToLower(output), "successfully paired") {
+		return "", fmt.Errorf("failed to pair with %s: %s", address, output)
+	}
+
+	// The pairing port only accepts pairing, so without the GUID there is no serial to return
+	match := pairedGUIDPattern.FindStringSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("paired with %s but adb did not report the device GUID; connect with ConnectTCPDevice: %s", address, output)
+	}
+	serial := match[1] + mdnsServiceSuffix
+
+	a.log(LogLevelInfo, fmt.Sprintf("Paired with device at %s", address), "✅")
+	return serial, nil
+}
+
+// DisconnectTCPDevice disconnects a device previously connected over TCP/IP
+func (a *AndroidLockScreenDisabler) DisconnectTCPDevice(serial string) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Disconnecting device %s...", a.deviceName(serial)), "🔌")
+
+	success, output, errorMsg := a.runADBCommand(context.Background(), "disconnect "+serial, "")
+	if !success {
+		return fmt.Errorf("failed to disconnect %s: %s", serial, errorMsg)
+	}
+
+	if strings.Contains(strings.ToLower(output), "error") {
+		return fmt.Errorf("failed to disconnect %s: %s", serial, output)
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Disconnected device %s", a.deviceName(serial)), "✅")
+	return nil
+}
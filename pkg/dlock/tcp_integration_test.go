@@ -0,0 +1,69 @@
+//go:build integration
+
+package dlock
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestPairDeviceIntegration pairs with a physical Android 11+ device over wireless debugging.
+//
+// To run it, connect the device and the host to the same Wi-Fi network, open Settings > Developer options >
+// Wireless debugging on the device, tap "Pair device with pairing code" and pass the IP address, port and
+// six-digit code shown in the dialog:
+//
+//	DLOCK_PAIR_HOST=192.168.1.20 DLOCK_PAIR_PORT=37123 DLOCK_PAIR_CODE=482913 DLOCK_CONNECT_PORT=41234 \
+//	    go test -tags integration -run TestPairDeviceIntegration ./pkg/dlock
+//
+// DLOCK_CONNECT_PORT is the port shown on the Wireless debugging screen itself, not in the pairing dialog;
+// when it is set, the test also connects to the device with ConnectTCPDevice.
+func TestPairDeviceIntegration(t *testing.T) {
+	host, code := os.Getenv("DLOCK_PAIR_HOST"), os.Getenv("DLOCK_PAIR_CODE")
+	if host == "" || code == "" {
+		t.Skip("set DLOCK_PAIR_HOST, DLOCK_PAIR_PORT and DLOCK_PAIR_CODE to pair with a device")
+	}
+	pairingPort, err := strconv.Atoi(os.Getenv("DLOCK_PAIR_PORT"))
+	if err != nil {
+		t.Fatalf("invalid DLOCK_PAIR_PORT: %v", err)
+	}
+
+	a, err := New(WithLogging(false))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	serial, err := a.PairDevice(ctx, host, pairingPort, code)
+	if err != nil {
+		t.Fatalf("PairDevice() error = %v", err)
+	}
+	t.Logf("paired, mDNS serial %s", serial)
+
+	connectPort := os.Getenv("DLOCK_CONNECT_PORT")
+	if connectPort == "" {
+		return
+	}
+	port, err := strconv.Atoi(connectPort)
+	if err != nil {
+		t.Fatalf("invalid DLOCK_CONNECT_PORT: %v", err)
+	}
+
+	serial, err = a.ConnectTCPDevice(host, port)
+	if err != nil {
+		t.Fatalf("ConnectTCPDevice() error = %v", err)
+	}
+	defer a.DisconnectTCPDevice(serial)
+
+	for _, device := range a.GetConnectedDevices() {
+		if device == serial {
+			return
+		}
+	}
+	t.Errorf("adb devices does not list %s after connecting", serial)
+}
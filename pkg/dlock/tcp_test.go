@@ -0,0 +1,51 @@
+package dlock
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPairDevice(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   MockResponse
+		wantSerial string
+		wantErr    string
+	}{
+		{
+			name:       "paired",
+			response:   ok("Successfully paired to 192.168.1.20:37123 [guid=adb-R58M123ABC-x1Yz2w]"),
+			wantSerial: "adb-R58M123ABC-x1Yz2w._adb-tls-connect._tcp",
+		},
+		{
+			name:     "paired without GUID",
+			response: ok("Successfully paired to 192.168.1.20:37123"),
+			wantErr:  "did not report the device GUID",
+		},
+		{
+			name:     "wrong code",
+			response: ok("Failed: Wrong password or connection was dropped."),
+			wantErr:  "failed to pair",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, mock := newMockDisabler(t, map[string]MockResponse{"pair": tt.response})
+
+			serial, err := a.PairDevice(context.Background(), "192.168.1.20", 37123, "482913")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("PairDevice() = %q, %v, want error containing %q", serial, err, tt.wantErr)
+				}
+			} else if err != nil || serial != tt.wantSerial {
+				t.Errorf("PairDevice() = %q, %v, want %q, nil", serial, err, tt.wantSerial)
+			}
+
+			if calls := mock.Calls(); len(calls) != 1 || calls[0] != "pair 192.168.1.20:37123 482913" {
+				t.Errorf("PairDevice() ran %v, want adb pair", calls)
+			}
+		})
+	}
+}
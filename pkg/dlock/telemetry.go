@@ -0,0 +1,175 @@
+package dlock
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// MethodAttemptEvent records the outcome of one lock screen disable method on a device
+type MethodAttemptEvent struct {
+	Manufacturer  string `json:"manufacturer"`
+	Model         string `json:"model"`
+	APILevel      int    `json:"api_level"` // 0 when unknown
+	MethodIndex   int    `json:"method_index"`
+	Success       bool   `json:"success"`
+	CorrelationID string `json:"correlation_id,omitempty"` // Correlation ID of the device run, see DeviceResult
+}
+
+// TelemetryRecorder receives an event for every disable method attempted, see WithTelemetry.
+// Devices are processed concurrently, so implementations must be safe for concurrent use.
+type TelemetryRecorder interface {
+	Record(event MethodAttemptEvent)
+}
+
+// FileTelemetryRecorder appends every event to a file as a JSON line; events that cannot be written are dropped
+type FileTelemetryRecorder struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileTelemetryRecorder creates a FileTelemetryRecorder appending to the file at path, which is created if needed
+func NewFileTelemetryRecorder(path string) *FileTelemetryRecorder {
+	return &FileTelemetryRecorder{Path: path}
+}
+
+// Record implements TelemetryRecorder
+func (r *FileTelemetryRecorder) Record(event MethodAttemptEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	appendFileWriter{path: r.Path}.Write(append(line, '\n'))
+}
+
+// MemoryTelemetryRecorder keeps every event in memory, which is useful in tests
+type MemoryTelemetryRecorder struct {
+	mu     sync.Mutex
+	events []MethodAttemptEvent
+}
+
+// Record implements TelemetryRecorder
+func (r *MemoryTelemetryRecorder) Record(event MethodAttemptEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns the events recorded so far
+func (r *MemoryTelemetryRecorder) Events() []MethodAttemptEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	eventsCopy := make([]MethodAttemptEvent, len(r.events))
+	copy(eventsCopy, r.events)
+	return eventsCopy
+}
+
+// recordTelemetry reports the outcome of every attempted method to the telemetry recorder, if one is configured.
+// Dry runs are not recorded since their methods never change the device.
+func (a *AndroidLockScreenDisabler) recordTelemetry(deviceInfo DeviceInfo, methods []MethodResult, correlationID string) {
+	if a.cfg.telemetry == nil || a.cfg.dryRun {
+		return
+	}
+
+	for _, method := range methods {
+		if !method.Attempted {
+			continue
+		}
+		a.cfg.telemetry.Record(MethodAttemptEvent{
+			Manufacturer:  deviceInfo.Manufacturer,
+			Model:         deviceInfo.Model,
+			APILevel:      deviceInfo.SDKInt,
+			MethodIndex:   method.MethodIndex,
+			Success:       method.Success,
+			CorrelationID: correlationID,
+		})
+	}
+}
+
+// methodSuccessKey groups telemetry events in the method success report
+type methodSuccessKey struct {
+	manufacturer string
+	apiLevel     int
+	method       int
+}
+
+// GenerateMethodSuccessReport reads the JSON lines written by a FileTelemetryRecorder and prints a table
+// of how often each method succeeded per manufacturer and API level to standard output
+func GenerateMethodSuccessReport(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read telemetry file: %w", err)
+	}
+	defer file.Close()
+
+	var events []MethodAttemptEvent
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event MethodAttemptEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to parse telemetry file %s:%d: %w", path, lineNumber, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read telemetry file %s: %w", path, err)
+	}
+
+	return writeMethodSuccessReport(os.Stdout, events)
+}
+
+// writeMethodSuccessReport writes the attempts, successes and success rate of every
+// manufacturer, API level and method combination as an aligned table
+func writeMethodSuccessReport(w io.Writer, events []MethodAttemptEvent) error {
+	attempts := make(map[methodSuccessKey]int)
+	successes := make(map[methodSuccessKey]int)
+	for _, event := range events {
+		key := methodSuccessKey{manufacturer: event.Manufacturer, apiLevel: event.APILevel, method: event.MethodIndex}
+		attempts[key]++
+		if event.Success {
+			successes[key]++
+		}
+	}
+
+	keys := make([]methodSuccessKey, 0, len(attempts))
+	for key := range attempts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].manufacturer != keys[j].manufacturer {
+			return keys[i].manufacturer < keys[j].manufacturer
+		}
+		if keys[i].apiLevel != keys[j].apiLevel {
+			return keys[i].apiLevel < keys[j].apiLevel
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "MANUFACTURER\tAPI\tMETHOD\tATTEMPTS\tSUCCESSES\tSUCCESS RATE")
+	for _, key := range keys {
+		manufacturer := key.manufacturer
+		if manufacturer == "" {
+			manufacturer = "unknown"
+		}
+		apiLevel := "unknown"
+		if key.apiLevel > 0 {
+			apiLevel = fmt.Sprint(key.apiLevel)
+		}
+		rate := float64(successes[key]) / float64(attempts[key]) * 100
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\t%.0f%%\n", manufacturer, apiLevel, key.method, attempts[key], successes[key], rate)
+	}
+	return table.Flush()
+}
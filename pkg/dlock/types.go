@@ -1,6 +1,11 @@
 package dlock
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // DeviceInfo holds information about an Android device
 type DeviceInfo struct {
@@ -8,6 +13,187 @@ type DeviceInfo struct {
 	Manufacturer   string
 	AndroidVersion string
 	APILevel       string
+	SDKInt         int // APILevel as a number, 0 when unknown
+	USBPath        string
+	TotalMemoryKB  int64
+	Biometrics     BiometricStatus
+	SIMStatus      SIMStatus
+}
+
+// lowMemoryThresholdKB is the total RAM at or below which a device is considered low-memory (1GB)
+const lowMemoryThresholdKB = 1024 * 1024
+
+// IsLowMemory reports whether the device has 1GB of RAM or less
+func (d DeviceInfo) IsLowMemory() bool {
+	return d.TotalMemoryKB > 0 && d.TotalMemoryKB <= lowMemoryThresholdKB
+}
+
+// LockType identifies a kind of lock screen credential
+type LockType string
+
+// Lock types
+const (
+	LockTypeNone      LockType = "none"
+	LockTypePIN       LockType = "pin"
+	LockTypePattern   LockType = "pattern"
+	LockTypePassword  LockType = "password"
+	LockTypeBiometric LockType = "biometric"
+	LockTypeAdmin     LockType = "admin"   // Lock enforced by a device admin password policy
+	LockTypeUnknown   LockType = "unknown" // A lock is present but its kind could not be determined
+)
+
+// LockScreenInfo describes the lock screen detected on a device
+type LockScreenInfo struct {
+	Type        LockType
+	Description string // How the lock was detected
+}
+
+// HasLock reports whether any lock screen was detected
+func (i LockScreenInfo) HasLock() bool {
+	return i.Type != LockTypeNone
+}
+
+// ResultStatus describes how processing of a device ended
+type ResultStatus string
+
+// Result statuses
+const (
+	StatusSuccess   ResultStatus = "success"
+	StatusFailed    ResultStatus = "failed"
+	StatusCancelled ResultStatus = "cancelled"
+	StatusSkipped   ResultStatus = "skipped"
+)
+
+// DeviceResult holds the outcome of processing a single device
+type DeviceResult struct {
+	Serial          string         `json:"serial"`
+	Alias           string         `json:"alias,omitempty"`
+	Manufacturer    string         `json:"manufacturer,omitempty"`
+	Model           string         `json:"model,omitempty"`
+	APILevel        string         `json:"api_level,omitempty"`
+	Success         bool           `json:"success"`
+	Status          ResultStatus   `json:"status"`
+	MethodUsed      int            `json:"method_used,omitempty"` // Number of the method that succeeded, 0 if none
+	LockDetected    bool           `json:"lock_detected"`
+	LockType        LockType       `json:"lock_type,omitempty"`
+	StartTime       time.Time      `json:"start_time"`
+	Duration        time.Duration  `json:"duration"`
+	Error           string         `json:"error,omitempty"`
+	Err             error          `json:"-"` // Error wrapping a sentinel such as ErrAllMethodsFailed, for errors.Is
+	Warnings        []string       `json:"warnings"`
+	MethodResults   []MethodResult `json:"method_results,omitempty"`
+	ScreenshotPaths []string       `json:"screenshot_paths,omitempty"` // Before/after screenshots, see WithScreenshots
+	SettingsDiff    []SettingsDiff `json:"settings_diff,omitempty"`    // Settings changed while processing, see WithSettingsDiff
+	AttemptCount    int            `json:"attempt_count"`              // Passes of the batch that processed the device, see WithAutoRetryFailures
+	CorrelationID   string         `json:"correlation_id,omitempty"`   // ID of this device run in logs and telemetry, see DeviceCorrelationID
+}
+
+// CommandResult is the outcome of one shell command run by RunShellCommands
+type CommandResult struct {
+	Command  string `json:"command"`
+	Output   string `json:"output"`    // The error when the command could not run
+	ExitCode int    `json:"exit_code"` // -1 when the command could not run
+}
+
+// HistoryRecorder stores the result of every processed device, see WithHistory and the history package.
+// Devices are processed concurrently, so implementations must be safe for concurrent use.
+type HistoryRecorder interface {
+	Record(result DeviceResult) error
+}
+
+// MethodResult describes a lock screen disable method of a device: its attempt or, when Attempted is false,
+// why it was not tried, such as an earlier method succeeding
+type MethodResult struct {
+	MethodIndex  int           `json:"method_index"`
+	MethodName   string        `json:"method_name"`
+	Attempted    bool          `json:"attempted"`
+	Success      bool          `json:"success"`
+	Command      string        `json:"command"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	Duration     time.Duration `json:"duration"`
+}
+
+// ProcessingReport holds the per-device results of a batch run
+type ProcessingReport struct {
+	Results        []DeviceResult `json:"results"`
+	WasInterrupted bool           `json:"was_interrupted"`
+	Err            error          `json:"-"` // Why no device was processed: ErrADBNotAvailable or ErrNoDevices
+}
+
+// BatchSummary aggregates the results of a batch of devices
+type BatchSummary struct {
+	TotalDevices    int
+	SuccessCount    int
+	FailedDevices   []string
+	CancelledCount  int
+	SkippedCount    int
+	MethodAttempts  map[int]int // Attempts per method number
+	MethodSuccesses map[int]int // Successes per method number
+}
+
+// NewBatchSummary computes a BatchSummary from per-device results
+func NewBatchSummary(results []DeviceResult) BatchSummary {
+	summary := BatchSummary{
+		TotalDevices:    len(results),
+		MethodAttempts:  make(map[int]int),
+		MethodSuccesses: make(map[int]int),
+	}
+
+	for _, result := range results {
+		switch {
+		case result.Success:
+			summary.SuccessCount++
+		case result.Status == StatusSkipped:
+			summary.SkippedCount++
+		default:
+			summary.FailedDevices = append(summary.FailedDevices, result.Serial)
+		}
+		if result.Status == StatusCancelled {
+			summary.CancelledCount++
+		}
+
+		for _, method := range result.MethodResults {
+			if !method.Attempted {
+				continue
+			}
+			summary.MethodAttempts[method.MethodIndex]++
+			if method.Success {
+				summary.MethodSuccesses[method.MethodIndex]++
+			}
+		}
+	}
+
+	return summary
+}
+
+// AddWarning records a non-fatal issue observed while processing the device
+func (r *DeviceResult) AddWarning(warning string) {
+	r.Warnings = append(r.Warnings, warning)
+}
+
+// Device states listed by `adb devices`
+const (
+	DeviceStateDevice        = "device"
+	DeviceStateOffline       = "offline"
+	DeviceStateUnauthorized  = "unauthorized"
+	DeviceStateNoPermissions = "no-permissions" // The host user may not open the USB device, usually a missing udev rule
+)
+
+// TransportType is how a device is connected to the host
+type TransportType string
+
+// Transport types
+const (
+	TransportUSB TransportType = "usb"
+	TransportTCP TransportType = "tcp" // Connected with adb connect or discovered over mDNS
+)
+
+// DeviceStatus is a device listed by `adb devices` together with its state
+type DeviceStatus struct {
+	Serial     string        `json:"serial"`
+	State      string        `json:"state"`
+	Transport  TransportType `json:"transport"` // Inferred from the serial format
+	IsEmulator bool          `json:"is_emulator"`
 }
 
 // ProcessingStats holds the statistics for device processing
@@ -15,7 +201,46 @@ type ProcessingStats struct {
 	mu            sync.Mutex
 	successCount  int
 	failedDevices []string
+	unauthorized  []string
+	offline       []string
+	frpLocked     []string
 	totalDevices  int
+	results       []DeviceResult
+	attempts      map[int]int
+	successes     map[int]int
+	onResult      func(DeviceResult)   // Called with every recorded result, outside the lock
+	attempt       int                  // Pass of the batch being processed, counting from 1
+	holdFailures  bool                 // Hold back failed results while the pass may still be retried
+	held          []DeviceResult       // Failed results held back during the current pass
+	inProgress    atomic.Int64         // Devices being processed right now
+	deviceCancels sync.Map             // Serial -> *context.CancelFunc of each device being processed
+	processed     ProcessedDeviceStore // Devices processed successfully, unless WithProcessedStore is set
+	// Device info fetched upfront by BatchGetDeviceInfo for the first pass, read-only while it runs
+	deviceInfo map[string]DeviceInfo
+}
+
+// ProcessHandle controls a batch started by ProcessDevicesStream
+type ProcessHandle struct {
+	stats *ProcessingStats
+}
+
+// CancelDevice cancels the processing of a single device of the batch, which is recorded as cancelled,
+// without affecting the other devices. It reports whether the device was being processed or waiting for a slot.
+func (h *ProcessHandle) CancelDevice(serial string) bool {
+	cancel, ok := h.stats.deviceCancels.LoadAndDelete(serial)
+	if !ok {
+		return false
+	}
+	(*cancel.(*context.CancelFunc))()
+	return true
+}
+
+// LiveStats is a snapshot of a batch taken while it is being processed
+type LiveStats struct {
+	Success    int `json:"success"`
+	Failed     int `json:"failed"`
+	InProgress int `json:"in_progress"`
+	Total      int `json:"total"`
 }
 
 // IncrementSuccess safely increments the success counter
@@ -32,6 +257,176 @@ func (ps *ProcessingStats) AddFailedDevice(deviceSerial string) {
 	ps.failedDevices = append(ps.failedDevices, deviceSerial)
 }
 
+// AddUnauthorizedDevice safely records a device that has not accepted the host's RSA key
+func (ps *ProcessingStats) AddUnauthorizedDevice(deviceSerial string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.unauthorized = append(ps.unauthorized, deviceSerial)
+}
+
+// UnauthorizedDevices safely retrieves the devices that were not authorized for USB debugging
+func (ps *ProcessingStats) UnauthorizedDevices() []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	unauthorizedCopy := make([]string, len(ps.unauthorized))
+	copy(unauthorizedCopy, ps.unauthorized)
+	return unauthorizedCopy
+}
+
+// UnauthorizedCount safely retrieves how many devices were not authorized for USB debugging
+func (ps *ProcessingStats) UnauthorizedCount() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return len(ps.unauthorized)
+}
+
+// AddOfflineDevice safely records a device that ADB lists as offline
+func (ps *ProcessingStats) AddOfflineDevice(deviceSerial string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.offline = append(ps.offline, deviceSerial)
+}
+
+// OfflineDevices safely retrieves the devices that were offline
+func (ps *ProcessingStats) OfflineDevices() []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	offlineCopy := make([]string, len(ps.offline))
+	copy(offlineCopy, ps.offline)
+	return offlineCopy
+}
+
+// OfflineCount safely retrieves how many devices were offline
+func (ps *ProcessingStats) OfflineCount() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return len(ps.offline)
+}
+
+// AddFRPDevice safely records a device blocked by Factory Reset Protection
+func (ps *ProcessingStats) AddFRPDevice(deviceSerial string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.frpLocked = append(ps.frpLocked, deviceSerial)
+}
+
+// FRPDevices safely retrieves the devices that were blocked by Factory Reset Protection
+func (ps *ProcessingStats) FRPDevices() []string {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	frpCopy := make([]string, len(ps.frpLocked))
+	copy(frpCopy, ps.frpLocked)
+	return frpCopy
+}
+
+// AddResult safely records the result of a processed device
+func (ps *ProcessingStats) AddResult(result DeviceResult) {
+	ps.mu.Lock()
+	if ps.holdFailures && result.Status == StatusFailed {
+		ps.held = append(ps.held, result)
+		ps.mu.Unlock()
+		return
+	}
+	ps.results = append(ps.results, result)
+	ps.mu.Unlock()
+
+	if ps.onResult != nil {
+		ps.onResult(result)
+	}
+}
+
+// currentAttempt safely retrieves the pass of the batch being processed
+func (ps *ProcessingStats) currentAttempt() int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.attempt
+}
+
+// beginPass starts a pass of the batch, holding back failed results if the pass may be retried
+func (ps *ProcessingStats) beginPass(attempt int, holdFailures bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.attempt = attempt
+	ps.holdFailures = holdFailures
+}
+
+// takeHeldFailures safely retrieves and clears the failed results held back during the current pass
+func (ps *ProcessingStats) takeHeldFailures() []DeviceResult {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	held := ps.held
+	ps.held = nil
+	ps.holdFailures = false
+	return held
+}
+
+// forgetFailures safely removes the devices from the failure lists so they can be processed again
+func (ps *ProcessingStats) forgetFailures(deviceSerials []string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	retried := make(map[string]bool, len(deviceSerials))
+	for _, serial := range deviceSerials {
+		retried[serial] = true
+	}
+	keep := func(serials []string) []string {
+		kept := serials[:0]
+		for _, serial := range serials {
+			if !retried[serial] {
+				kept = append(kept, serial)
+			}
+		}
+		return kept
+	}
+
+	ps.failedDevices = keep(ps.failedDevices)
+	ps.unauthorized = keep(ps.unauthorized)
+	ps.offline = keep(ps.offline)
+	ps.frpLocked = keep(ps.frpLocked)
+}
+
+// Results safely retrieves the per-device results recorded so far
+func (ps *ProcessingStats) Results() []DeviceResult {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	resultsCopy := make([]DeviceResult, len(ps.results))
+	copy(resultsCopy, ps.results)
+	return resultsCopy
+}
+
+// RecordMethodAttempt safely records an attempt of the given method number and its outcome
+func (ps *ProcessingStats) RecordMethodAttempt(method int, success bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.attempts[method]++
+	if success {
+		ps.successes[method]++
+	}
+}
+
+// MethodAttempts safely retrieves how many times each method number was attempted
+func (ps *ProcessingStats) MethodAttempts() map[int]int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return copyCounts(ps.attempts)
+}
+
+// MethodSuccesses safely retrieves how many times each method number succeeded
+func (ps *ProcessingStats) MethodSuccesses() map[int]int {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return copyCounts(ps.successes)
+}
+
+// copyCounts returns a copy of a method counter map
+func copyCounts(counts map[int]int) map[int]int {
+	countsCopy := make(map[int]int, len(counts))
+	for method, count := range counts {
+		countsCopy[method] = count
+	}
+	return countsCopy
+}
+
 // GetStats safely retrieves current statistics
 func (ps *ProcessingStats) GetStats() (int, []string, int) {
 	ps.mu.Lock()
@@ -41,9 +436,52 @@ func (ps *ProcessingStats) GetStats() (int, []string, int) {
 	return ps.successCount, failedCopy, ps.totalDevices
 }
 
+// InProgress retrieves how many devices are being processed right now
+func (ps *ProcessingStats) InProgress() int {
+	return int(ps.inProgress.Load())
+}
+
+// GetLiveStats safely retrieves a snapshot of the statistics; it may be polled while devices are processed
+func (ps *ProcessingStats) GetLiveStats() LiveStats {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return LiveStats{
+		Success:    ps.successCount,
+		Failed:     len(ps.failedDevices),
+		InProgress: ps.InProgress(),
+		Total:      ps.totalDevices,
+	}
+}
+
+// progress safely retrieves how many devices have a final result, the total and their average duration
+func (ps *ProcessingStats) progress() (completed, total int, average time.Duration) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	var elapsed time.Duration
+	for _, result := range ps.results {
+		elapsed += result.Duration
+	}
+	if len(ps.results) > 0 {
+		average = elapsed / time.Duration(len(ps.results))
+	}
+	return len(ps.results), ps.totalDevices, average
+}
+
+// addDevice safely counts a device that joined the batch after it started, such as in watch mode
+func (ps *ProcessingStats) addDevice() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.totalDevices++
+}
+
 // NewProcessingStats creates a new ProcessingStats instance
 func NewProcessingStats(totalDevices int) *ProcessingStats {
 	return &ProcessingStats{
 		totalDevices: totalDevices,
+		attempt:      1,
+		processed:    InMemoryStore(),
+		attempts:     make(map[int]int),
+		successes:    make(map[int]int),
 	}
 }
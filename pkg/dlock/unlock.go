@@ -0,0 +1,201 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unlockSwipeAttempts is how many times UnlockScreen swipes before giving up
+const unlockSwipeAttempts = 3
+
+// screenSizePattern matches the resolution reported by `wm size`; the last match is the override size, if any
+var screenSizePattern = regexp.MustCompile(`(\d+)x(\d+)`)
+
+// Screen size used for the swipe gesture when `wm size` cannot be read
+const (
+	fallbackScreenWidth  = 1080
+	fallbackScreenHeight = 1920
+)
+
+// UnlockScreen wakes the device and swipes up to dismiss a swipe-only lock screen, without changing any setting.
+// Devices protected by a PIN, pattern or password return ErrRequiresCredential.
+func (a *AndroidLockScreenDisabler) UnlockScreen(serial string) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+	return a.unlockScreen(context.Background(), serial)
+}
+
+// unlockScreen implements UnlockScreen, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) unlockScreen(ctx context.Context, serial string) error {
+	if lockInfo, err := a.checkExistingLockScreen(ctx, serial); err == nil && lockInfo.HasLock() {
+		return fmt.Errorf("%w: %s lock on device %s", ErrRequiresCredential, lockInfo.Type, serial)
+	}
+
+	width, height := a.screenSize(ctx, serial)
+	swipe := fmt.Sprintf("shell input swipe %d %d %d %d", width/2, height*9/10, width/2, height/10)
+
+	for attempt := 1; attempt <= unlockSwipeAttempts; attempt++ {
+		if success, _, errorMsg := a.runADBCommand(ctx, "shell input keyevent KEYCODE_WAKEUP", serial); !success {
+			return fmt.Errorf("failed to wake device %s: %s", serial, errorMsg)
+		}
+		if !a.cfg.sleep(ctx, time.Second) {
+			return ctx.Err()
+		}
+
+		if success, _, errorMsg := a.runADBCommand(ctx, swipe, serial); !success {
+			return fmt.Errorf("failed to swipe on device %s: %s", serial, errorMsg)
+		}
+
+		// A dry run does not swipe, so the keyguard is still showing
+		showing, err := a.getKeyguardIsShowing(ctx, serial)
+		if (err == nil && !showing) || a.cfg.dryRun {
+			a.log(LogLevelInfo, fmt.Sprintf("Screen unlocked on device %s", a.deviceName(serial)), "🔓", "device", serial)
+			return nil
+		}
+		a.log(LogLevelDebug, fmt.Sprintf("Keyguard still showing on device %s after swipe %d/%d", a.deviceName(serial), attempt, unlockSwipeAttempts), "🔁", "device", serial)
+	}
+
+	return fmt.Errorf("keyguard still showing on device %s after %d swipes", serial, unlockSwipeAttempts)
+}
+
+// screenSize returns the screen resolution of a device, falling back to 1080x1920 when it cannot be read
+func (a *AndroidLockScreenDisabler) screenSize(ctx context.Context, serial string) (int, int) {
+	width, height, err := a.readScreenSize(ctx, serial)
+	if err != nil {
+		return fallbackScreenWidth, fallbackScreenHeight
+	}
+	return width, height
+}
+
+// readScreenSize returns the screen resolution reported by `wm size`, or ErrScreenResolutionUnknown
+func (a *AndroidLockScreenDisabler) readScreenSize(ctx context.Context, serial string) (int, int, error) {
+	success, output, errorMsg := a.runADBCommand(ctx, "shell wm size", serial)
+	if !success {
+		return 0, 0, fmt.Errorf("%w on device %s: %s", ErrScreenResolutionUnknown, serial, errorMsg)
+	}
+
+	matches := screenSizePattern.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, 0, fmt.Errorf("%w on device %s: %q", ErrScreenResolutionUnknown, serial, output)
+	}
+
+	last := matches[len(matches)-1]
+	width, _ := strconv.Atoi(last[1])
+	height, _ := strconv.Atoi(last[2])
+	if width == 0 || height == 0 {
+		return 0, 0, fmt.Errorf("%w on device %s: %q", ErrScreenResolutionUnknown, serial, output)
+	}
+	return width, height, nil
+}
+
+// PatternPoint is a dot of the 3x3 pattern lock grid, with Row and Col counted from 0 at the top left
+type PatternPoint struct {
+	Row int
+	Col int
+}
+
+// DismissPatternLock unlocks a pattern lock once by drawing pattern on the screen, without changing any setting.
+// The grid position is estimated from the screen resolution, so this does not work on every device; it is meant
+// for devices with a known pattern that lock during automation. Patterns of more than two dots are drawn with
+// `input motionevent`, which older Android versions lack. It returns ErrScreenResolutionUnknown if the screen
+// resolution cannot be read.
+func (a *AndroidLockScreenDisabler) DismissPatternLock(serial string, pattern []PatternPoint) error {
+	if err := a.checkSerial(serial); err != nil {
+		return err
+	}
+	return a.dismissPatternLock(context.Background(), serial, pattern)
+}
+
+// dismissPatternLock implements DismissPatternLock, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) dismissPatternLock(ctx context.Context, serial string, pattern []PatternPoint) error {
+	if err := validatePattern(pattern); err != nil {
+		return err
+	}
+
+	width, height, err := a.readScreenSize(ctx, serial)
+	if err != nil {
+		return err
+	}
+
+	// Wake the device and swipe up to bring up the pattern
+	if success, _, errorMsg := a.runADBCommand(ctx, "shell input keyevent KEYCODE_WAKEUP", serial); !success {
+		return fmt.Errorf("failed to wake device %s: %s", serial, errorMsg)
+	}
+	if !a.cfg.sleep(ctx, time.Second) {
+		return ctx.Err()
+	}
+	if success, _, errorMsg := a.runADBCommand(ctx, fmt.Sprintf("shell input swipe %d %d %d %d", width/2, height*9/10, width/2, height/10), serial); !success {
+		return fmt.Errorf("failed to swipe on device %s: %s", serial, errorMsg)
+	}
+	if !a.cfg.sleep(ctx, time.Second) {
+		return ctx.Err()
+	}
+
+	if success, _, errorMsg := a.runADBCommand(ctx, patternGestureCommand(pattern, width, height), serial); !success {
+		return fmt.Errorf("failed to draw pattern on device %s: %s", serial, errorMsg)
+	}
+
+	if !a.cfg.sleep(ctx, time.Second) {
+		return ctx.Err()
+	}
+	if showing, err := a.getKeyguardIsShowing(ctx, serial); err == nil && showing && !a.cfg.dryRun {
+		return fmt.Errorf("keyguard still showing on device %s after drawing the pattern", serial)
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Pattern lock dismissed on device %s", a.deviceName(serial)), "🔓", "device", serial)
+	return nil
+}
+
+// validatePattern checks that pattern has at least two distinct dots, all on the 3x3 grid
+func validatePattern(pattern []PatternPoint) error {
+	if len(pattern) < 2 {
+		return fmt.Errorf("pattern must have at least 2 points, got %d", len(pattern))
+	}
+
+	seen := make(map[PatternPoint]bool, len(pattern))
+	for _, point := range pattern {
+		if point.Row < 0 || point.Row > 2 || point.Col < 0 || point.Col > 2 {
+			return fmt.Errorf("pattern point (%d, %d) is outside the 3x3 grid", point.Row, point.Col)
+		}
+		if seen[point] {
+			return fmt.Errorf("pattern point (%d, %d) is used more than once", point.Row, point.Col)
+		}
+		seen[point] = true
+	}
+	return nil
+}
+
+// patternPointPosition estimates the screen coordinates of a pattern dot: the grid is centered horizontally
+// with dots a quarter of the screen width apart, and its center row sits at two thirds of the screen height
+func patternPointPosition(point PatternPoint, width, height int) (int, int) {
+	spacing := width / 4
+	return spacing * (point.Col + 1), height*2/3 + spacing*(point.Row-1)
+}
+
+// patternGestureCommand builds the single adb shell command that draws pattern without lifting the finger
+func patternGestureCommand(pattern []PatternPoint, width, height int) string {
+	if len(pattern) == 2 {
+		x1, y1 := patternPointPosition(pattern[0], width, height)
+		x2, y2 := patternPointPosition(pattern[1], width, height)
+		return fmt.Sprintf("shell input swipe %d %d %d %d 500", x1, y1, x2, y2)
+	}
+
+	events := make([]string, 0, len(pattern))
+	for i, point := range pattern {
+		action := "MOVE"
+		switch i {
+		case 0:
+			action = "DOWN"
+		case len(pattern) - 1:
+			action = "UP"
+		}
+		x, y := patternPointPosition(point, width, height)
+		events = append(events, fmt.Sprintf("input motionevent %s %d %d", action, x, y))
+	}
+	return fmt.Sprintf(`shell "%s"`, strings.Join(events, " && "))
+}
@@ -0,0 +1,51 @@
+package dlock
+
+import (
+	"strings"
+	"testing"
+)
+
+// swipeLockResponses describe a device without a credential whose keyguard is showing
+var swipeLockResponses = map[string]MockResponse{
+	"shell dumpsys trust": ok("Trust manager state:\n" +
+		" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0, deviceLocked=1\n" +
+		" isDeviceSecure=false\n"),
+	"shell settings get secure lockscreen.disabled": ok("null"),
+	"shell dumpsys device_policy":                   ok(""),
+	"shell wm size":                                 ok("Physical size: 1080x2400\n"),
+	"shell dumpsys window policy":                   ok("    mKeyguardDelegate\n      KeyguardServiceDelegate\n        keyguardShowing=true\n"),
+}
+
+// inputCalls returns the commands of mock that inject input events
+func inputCalls(mock *MockADBExecutor) []string {
+	var calls []string
+	for _, call := range mock.Calls() {
+		if strings.Contains(call, "shell input") {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func TestUnlockScreenDryRun(t *testing.T) {
+	a, mock := newMockDisabler(t, swipeLockResponses, WithDryRun(true))
+
+	if err := a.UnlockScreen(testSerial); err != nil {
+		t.Errorf("UnlockScreen() error = %v, want nil", err)
+	}
+	if calls := inputCalls(mock); len(calls) != 0 {
+		t.Errorf("UnlockScreen() ran %v in dry-run mode, want no input events", calls)
+	}
+}
+
+func TestDismissPatternLockDryRun(t *testing.T) {
+	a, mock := newMockDisabler(t, swipeLockResponses, WithDryRun(true))
+
+	pattern := []PatternPoint{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {2, 2}}
+	if err := a.DismissPatternLock(testSerial, pattern); err != nil {
+		t.Errorf("DismissPatternLock() error = %v, want nil", err)
+	}
+	if calls := inputCalls(mock); len(calls) != 0 {
+		t.Errorf("DismissPatternLock() ran %v in dry-run mode, want no input events", calls)
+	}
+}
@@ -0,0 +1,83 @@
+package dlock
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// usbDevicesPath is the sysfs directory listing USB devices on Linux
+const usbDevicesPath = "/sys/bus/usb/devices"
+
+// GetUSBPath returns the USB topology path of a device (e.g. 1-1.3.2) on Linux hosts
+func (a *AndroidLockScreenDisabler) GetUSBPath(deviceSerial string) (string, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return "", err
+	}
+	return a.getUSBPath(context.Background(), deviceSerial)
+}
+
+// getUSBPath implements GetUSBPath, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getUSBPath(ctx context.Context, deviceSerial string) (string, error) {
+	if runtime.GOOS != "linux" {
+		return "", ErrNotSupported
+	}
+
+	// Method 1: Match the device serial against sysfs entries
+	if path, ok := findUSBPathInSysfs(deviceSerial); ok {
+		return path, nil
+	}
+
+	// Method 2: Fall back to the usb: field reported by adb devices -l
+	success, output, errorMsg := a.runADBCommand(ctx, "devices -l", "")
+	if !success {
+		return "", fmt.Errorf("failed to list devices: %s", errorMsg)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != deviceSerial {
+			continue
+		}
+
+		for _, field := range fields[2:] {
+			if strings.HasPrefix(field, "usb:") {
+				return strings.TrimPrefix(field, "usb:"), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("USB path not found for device %s", deviceSerial)
+}
+
+// findUSBPathInSysfs scans sysfs for a USB device whose serial matches deviceSerial
+func findUSBPathInSysfs(deviceSerial string) (string, bool) {
+	entries, err := os.ReadDir(usbDevicesPath)
+	if err != nil {
+		return "", false
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		// Interface entries (e.g. 1-1.3:1.0) have no serial of their own
+		if strings.Contains(name, ":") {
+			continue
+		}
+
+		serial, err := os.ReadFile(filepath.Join(usbDevicesPath, name, "serial"))
+		if err != nil {
+			continue
+		}
+
+		if strings.TrimSpace(string(serial)) == deviceSerial {
+			return name, true
+		}
+	}
+
+	return "", false
+}
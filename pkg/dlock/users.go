@@ -0,0 +1,133 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// userFlagManagedProfile is Android's UserInfo.FLAG_MANAGED_PROFILE, set on work profiles
+const userFlagManagedProfile = 0x20
+
+// userInfoPattern matches `pm list users` lines such as "UserInfo{10:Work profile:1030} running"
+var userInfoPattern = regexp.MustCompile(`UserInfo\{(\d+):([^:}]*):([0-9a-fA-F]+)\}(\s+running)?`)
+
+// AndroidUser is a user account on a device, as listed by `pm list users`
+type AndroidUser struct {
+	ID        int
+	Name      string
+	IsRunning bool
+	flags     int64 // UserInfo flags
+}
+
+// ListAndroidUsers returns the user accounts of a device
+func (a *AndroidLockScreenDisabler) ListAndroidUsers(deviceSerial string) ([]AndroidUser, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return nil, err
+	}
+	return a.listAndroidUsers(context.Background(), deviceSerial)
+}
+
+// listAndroidUsers implements ListAndroidUsers, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) listAndroidUsers(ctx context.Context, deviceSerial string) ([]AndroidUser, error) {
+	success, output, errorMsg := a.runADBCommand(ctx, "shell pm list users", deviceSerial)
+	if !success {
+		return nil, fmt.Errorf("failed to list users on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	var users []AndroidUser
+	for _, match := range userInfoPattern.FindAllStringSubmatch(output, -1) {
+		id, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		flags, _ := strconv.ParseInt(match[3], 16, 64)
+		users = append(users, AndroidUser{
+			ID:        id,
+			Name:      match[2],
+			IsRunning: match[4] != "",
+			flags:     flags,
+		})
+	}
+	return users, nil
+}
+
+// workProfileUsers returns the work profiles of a device
+func (a *AndroidLockScreenDisabler) workProfileUsers(ctx context.Context, deviceSerial string) []AndroidUser {
+	users, err := a.listAndroidUsers(ctx, deviceSerial)
+	if err != nil {
+		return nil
+	}
+
+	var profiles []AndroidUser
+	for _, user := range users {
+		if user.flags&userFlagManagedProfile != 0 {
+			profiles = append(profiles, user)
+		}
+	}
+	return profiles
+}
+
+// HasWorkProfile reports whether the device has a work profile with its own lock screen (work challenge)
+func (a *AndroidLockScreenDisabler) HasWorkProfile(deviceSerial string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.hasWorkProfile(context.Background(), deviceSerial)
+}
+
+// hasWorkProfile implements HasWorkProfile, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) hasWorkProfile(ctx context.Context, deviceSerial string) bool {
+	return len(a.workProfileUsers(ctx, deviceSerial)) > 0
+}
+
+// DisableWorkProfileLock disables the work challenge of every work profile on the device.
+// locksettings refuses to clear a work challenge protected by a credential unless it is supplied,
+// and some devices only allow it when the work profile is unlocked.
+func (a *AndroidLockScreenDisabler) DisableWorkProfileLock(deviceSerial string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.disableWorkProfileLock(context.Background(), deviceSerial)
+}
+
+// disableWorkProfileLock implements DisableWorkProfileLock, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) disableWorkProfileLock(ctx context.Context, deviceSerial string) bool {
+	profiles := a.workProfileUsers(ctx, deviceSerial)
+	if len(profiles) == 0 {
+		a.log(LogLevelDebug, fmt.Sprintf("No work profile found on device %s", a.deviceName(deviceSerial)), "ℹ️", "device", deviceSerial)
+		return false
+	}
+
+	allDisabled := true
+	for _, profile := range profiles {
+		if !a.disableLockScreenForUser(ctx, deviceSerial, profile.ID) {
+			allDisabled = false
+		}
+	}
+	return allDisabled
+}
+
+// DisableLockScreenForUser disables the lock screen of one user account by passing --user to locksettings
+func (a *AndroidLockScreenDisabler) DisableLockScreenForUser(deviceSerial string, userID int) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.disableLockScreenForUser(context.Background(), deviceSerial, userID)
+}
+
+// disableLockScreenForUser implements DisableLockScreenForUser, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) disableLockScreenForUser(ctx context.Context, deviceSerial string, userID int) bool {
+	a.log(LogLevelDebug, fmt.Sprintf("Disabling lock screen of user %d on device %s...", userID, a.deviceName(deviceSerial)), "👤", "device", deviceSerial)
+	a.runADBCommand(ctx, fmt.Sprintf("shell locksettings --user %d clear", userID), deviceSerial)
+
+	success, _, errorMsg := a.runADBCommand(ctx, fmt.Sprintf("shell locksettings --user %d set-disabled true", userID), deviceSerial)
+	if !success {
+		a.log(LogLevelWarn, fmt.Sprintf("Failed to disable lock screen of user %d on device %s: %s", userID, a.deviceName(deviceSerial), errorMsg), "❌", "device", deviceSerial)
+		return false
+	}
+
+	a.log(LogLevelInfo, fmt.Sprintf("Lock screen of user %d disabled on device %s", userID, a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+	return true
+}
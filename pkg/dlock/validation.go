@@ -1,105 +1,303 @@
 package dlock
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// CheckDevicePermissions checks if device has necessary permissions for lock screen modifications
-func (a *AndroidLockScreenDisabler) CheckDevicePermissions(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Checking permissions for device %s...", deviceSerial), "🔐")
+// CheckDevicePermissions checks if device has necessary permissions for lock screen modifications,
+// returning an error wrapping ErrPermissionDenied if it does not
+func (a *AndroidLockScreenDisabler) CheckDevicePermissions(deviceSerial string) error {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return err
+	}
+	return a.checkDevicePermissions(context.Background(), deviceSerial)
+}
+
+// checkDevicePermissions implements CheckDevicePermissions, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) checkDevicePermissions(ctx context.Context, deviceSerial string) error {
+	a.log(LogLevelDebug, fmt.Sprintf("Checking permissions for device %s...", a.deviceName(deviceSerial)), "🔐", "device", deviceSerial)
 
 	// Test basic shell access
-	success, _, _ := a.runADBCommand("shell echo 'test'", deviceSerial)
+	success, _, errorMsg := a.runADBCommand(ctx, "shell echo 'test'", deviceSerial)
 	if !success {
-		a.log(fmt.Sprintf("No shell access to device %s", deviceSerial), "❌")
-		return false
+		a.log(LogLevelError, fmt.Sprintf("No shell access to device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		return fmt.Errorf("%w: no shell access to device %s: %s", ErrPermissionDenied, deviceSerial, errorMsg)
 	}
 
 	// Check if we can access settings (get just the list without head command)
-	success, output, _ := a.runADBCommand("shell settings list secure", deviceSerial)
+	success, output, _ := a.runADBCommand(ctx, "shell settings list secure", deviceSerial)
 	if !success || output == "" {
-		a.log(fmt.Sprintf("Cannot access settings on device %s", deviceSerial), "❌")
+		a.log(LogLevelError, fmt.Sprintf("Cannot access settings on device %s", a.deviceName(deviceSerial)), "❌", "device", deviceSerial)
+		return fmt.Errorf("%w: cannot access settings on device %s", ErrPermissionDenied, deviceSerial)
+	}
+
+	a.log(LogLevelDebug, fmt.Sprintf("Device %s has necessary permissions", a.deviceName(deviceSerial)), "✅", "device", deviceSerial)
+	return nil
+}
+
+// setupWizardPackage is the Google Setup Wizard, which hosts the Factory Reset Protection account check
+const setupWizardPackage = "com.google.android.setupwizard"
+
+// DetectFRP reports whether the device is held by Factory Reset Protection: the Setup Wizard is in the
+// foreground and setup has not completed
+func (a *AndroidLockScreenDisabler) DetectFRP(deviceSerial string) bool {
+	if a.checkSerial(deviceSerial) != nil {
+		return false
+	}
+	return a.detectFRP(context.Background(), deviceSerial)
+}
+
+// detectFRP implements DetectFRP, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) detectFRP(ctx context.Context, deviceSerial string) bool {
+	success, output, _ := a.runADBCommand(ctx, "shell settings get secure user_setup_complete", deviceSerial)
+	if success && output == "1" {
 		return false
 	}
 
-	a.log(fmt.Sprintf("Device %s has necessary permissions", deviceSerial), "✅")
-	return true
+	success, output, _ = a.runADBCommand(ctx, "shell dumpsys activity activities", deviceSerial)
+	if !success {
+		return false
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "ResumedActivity") && strings.Contains(line, setupWizardPackage) {
+			return true
+		}
+	}
+	return false
 }
 
-// CheckExistingLockScreen checks if device has any lock screen configured
-func (a *AndroidLockScreenDisabler) CheckExistingLockScreen(deviceSerial string) (bool, string) {
-	a.log(fmt.Sprintf("Checking if device %s has existing lock screen configured...", deviceSerial), "🔍")
+// AuthStatus describes whether ADB may talk to a device
+type AuthStatus string
+
+// Authorization statuses
+const (
+	AuthStatusAuthorized   AuthStatus = "authorized"
+	AuthStatusUnauthorized AuthStatus = "unauthorized" // The RSA key has not been accepted on the device
+	AuthStatusOffline      AuthStatus = "offline"
+	AuthStatusUnknown      AuthStatus = "unknown"
+)
+
+// GetDeviceAuthorizationStatus reports the authorization state of a device as listed by `adb devices`
+func (a *AndroidLockScreenDisabler) GetDeviceAuthorizationStatus(deviceSerial string) AuthStatus {
+	if a.checkSerial(deviceSerial) != nil {
+		return AuthStatusUnknown
+	}
+	return a.getDeviceAuthorizationStatus(context.Background(), deviceSerial)
+}
+
+// getDeviceAuthorizationStatus implements GetDeviceAuthorizationStatus, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getDeviceAuthorizationStatus(ctx context.Context, deviceSerial string) AuthStatus {
+	success, output, _ := a.runADBCommand(ctx, "devices", "")
+	if !success {
+		return AuthStatusUnknown
+	}
+
+	switch parseDeviceStates(output)[deviceSerial] {
+	case DeviceStateDevice:
+		return AuthStatusAuthorized
+	case DeviceStateUnauthorized:
+		return AuthStatusUnauthorized
+	case DeviceStateOffline:
+		return AuthStatusOffline
+	default:
+		return AuthStatusUnknown
+	}
+}
+
+// CheckExistingLockScreen detects which kind of lock screen, if any, is configured on the device.
+// It returns LockTypeUnknown and an error when none of the detection commands could run.
+func (a *AndroidLockScreenDisabler) CheckExistingLockScreen(deviceSerial string) (LockScreenInfo, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return LockScreenInfo{Type: LockTypeUnknown}, err
+	}
+	return a.checkExistingLockScreen(context.Background(), deviceSerial)
+}
+
+// checkExistingLockScreen implements CheckExistingLockScreen, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) checkExistingLockScreen(ctx context.Context, deviceSerial string) (LockScreenInfo, error) {
+	a.log(LogLevelDebug, fmt.Sprintf("Checking if device %s has existing lock screen configured...", a.deviceName(deviceSerial)), "🔍", "device", deviceSerial)
+
+	// Read everything the detection methods look at in one shell session
+	results, err := a.runShellCommands(ctx, deviceSerial, []string{
+		"dumpsys trust",
+		"locksettings get-disabled",
+		"dumpsys activity services KeyguardService",
+		"settings get secure lock_pattern_enabled",
+		"settings get secure lockscreen.password_type",
+		"settings get secure lockscreen.disabled",
+		"dumpsys device_policy",
+		biometricCommands[0],
+		biometricCommands[1],
+	})
+	if err != nil {
+		return LockScreenInfo{Type: LockTypeUnknown}, err
+	}
+	trust, lockSettings, keyguardService := results[0], results[1], results[2]
+	patternEnabled, passwordType, lockScreenDisabled := results[3], results[4], results[5]
+	devicePolicy := results[6]
+
+	anySucceeded := false
+	for _, result := range results[:7] {
+		anySucceeded = anySucceeded || result.ExitCode == 0
+	}
+	lockType := lockTypeFromSettings(patternEnabled, passwordType)
+	if lockType == LockTypeUnknown && parseBiometricStatus(results[7], results[8]).HasEnrollment() {
+		lockType = LockTypeBiometric
+	}
 
 	// Method 1: Check keyguard state
-	success, output, _ := a.runADBCommand("shell dumpsys trust", deviceSerial)
-	if success && output != "" {
-		if strings.Contains(strings.ToLower(output), "isdevicesecure=true") ||
-			strings.Contains(strings.ToLower(output), "iskeyguardsecure=true") {
-			return true, "Device has secure lock screen (detected via trust manager)"
+	if trust.ExitCode == 0 && trust.Output != "" {
+		if strings.Contains(strings.ToLower(trust.Output), "isdevicesecure=true") ||
+			strings.Contains(strings.ToLower(trust.Output), "iskeyguardsecure=true") {
+			return LockScreenInfo{Type: lockType, Description: "Device has secure lock screen (detected via trust manager)"}, nil
 		}
 	}
 
 	// Method 2: Check lock pattern/PIN/password settings
 	lockScreenDisabledLockSettingsMethod := false
-	success, output, _ = a.runADBCommand("shell locksettings get-disabled", deviceSerial)
-	if success {
-		lockScreenDisabledLockSettingsMethod = strings.Contains(strings.ToLower(output), "true")
+	if lockSettings.ExitCode == 0 {
+		lockScreenDisabledLockSettingsMethod = strings.Contains(strings.ToLower(lockSettings.Output), "true")
 		if !lockScreenDisabledLockSettingsMethod {
-			return true, "Device has lock configured (detected via locksettings)"
+			return LockScreenInfo{Type: lockType, Description: "Device has lock configured (detected via locksettings)"}, nil
 		}
 	}
 
 	// Method 3: Check keyguard manager
-	success, output, _ = a.runADBCommand("shell dumpsys activity services KeyguardService", deviceSerial)
-	if success && output != "" {
-		if strings.Contains(strings.ToLower(output), "secure=true") ||
-			strings.Contains(strings.ToLower(output), "enabled=true") {
-			return true, "Device has keyguard enabled (detected via KeyguardService)"
+	if keyguardService.ExitCode == 0 && keyguardService.Output != "" {
+		if strings.Contains(strings.ToLower(keyguardService.Output), "secure=true") ||
+			strings.Contains(strings.ToLower(keyguardService.Output), "enabled=true") {
+			return LockScreenInfo{Type: lockType, Description: "Device has keyguard enabled (detected via KeyguardService)"}, nil
 		}
 	}
 
 	// Method 4: Check lock settings in secure database
-	lockMethods := []string{
-		"shell settings get secure lock_pattern_enabled",
-		"shell settings get secure lockscreen.password_type",
-		"shell settings get secure lockscreen.disabled",
+	if settingSet(patternEnabled) && patternEnabled.Output == "1" {
+		return LockScreenInfo{Type: LockTypePattern, Description: "Device has lock pattern enabled"}, nil
+	}
+	if settingSet(passwordType) && passwordType.Output != "0" {
+		return LockScreenInfo{Type: lockTypeFromPasswordType(passwordType.Output), Description: fmt.Sprintf("Device has password type configured (type: %s)", passwordType.Output)}, nil
+	}
+	if settingSet(lockScreenDisabled) && lockScreenDisabled.Output == "0" && !lockScreenDisabledLockSettingsMethod {
+		return LockScreenInfo{Type: LockTypeUnknown, Description: "Lock screen is explicitly enabled in settings"}, nil
 	}
 
-	for _, method := range lockMethods {
-		success, output, _ := a.runADBCommand(method, deviceSerial)
-		if success && output != "" && output != "null" {
-			if strings.Contains(method, "lock_pattern_enabled") && output == "1" {
-				return true, "Device has lock pattern enabled"
-			}
-			if strings.Contains(method, "password_type") && output != "0" {
-				return true, fmt.Sprintf("Device has password type configured (type: %s)", output)
-			}
-			if strings.Contains(method, "lockscreen.disabled") && output == "0" && !lockScreenDisabledLockSettingsMethod {
-				return true, "Lock screen is explicitly enabled in settings"
-			}
+	// Method 5: Check device policy manager for admin locks
+	if devicePolicy.ExitCode == 0 && devicePolicy.Output != "" {
+		if strings.Contains(strings.ToLower(devicePolicy.Output), "passwordquality") ||
+			strings.Contains(strings.ToLower(devicePolicy.Output), "minimumpasswordlength") {
+			return LockScreenInfo{Type: LockTypeAdmin, Description: "Device has admin-enforced password policy"}, nil
 		}
 	}
 
-	// Method 5: Check device policy manager for admin locks
-	success, output, _ = a.runADBCommand("shell dumpsys device_policy", deviceSerial)
-	if success && output != "" {
-		if strings.Contains(strings.ToLower(output), "passwordquality") ||
-			strings.Contains(strings.ToLower(output), "minimumpasswordlength") {
-			return true, "Device has admin-enforced password policy"
+	if !anySucceeded {
+		return LockScreenInfo{Type: LockTypeUnknown}, fmt.Errorf("could not query lock screen state on device %s", deviceSerial)
+	}
+
+	return LockScreenInfo{Type: LockTypeNone, Description: "No lock screen detected"}, nil
+}
+
+// lockTypeFromSettings determines the credential kind of a lock already known to be present
+// from the lock_pattern_enabled and lockscreen.password_type settings
+func lockTypeFromSettings(patternEnabled, passwordType CommandResult) LockType {
+	if patternEnabled.ExitCode == 0 && patternEnabled.Output == "1" {
+		return LockTypePattern
+	}
+
+	if passwordType.ExitCode == 0 {
+		if lockType := lockTypeFromPasswordType(passwordType.Output); lockType != LockTypeNone {
+			return lockType
 		}
 	}
 
-	return false, "No lock screen detected"
+	return LockTypeUnknown
+}
+
+// settingSet reports whether a `settings get` command read a value
+func settingSet(result CommandResult) bool {
+	return result.ExitCode == 0 && result.Output != "" && result.Output != "null"
+}
+
+// lockTypeFromPasswordType maps lockscreen.password_type, a DevicePolicyManager password quality, to a LockType
+func lockTypeFromPasswordType(value string) LockType {
+	quality, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return LockTypeUnknown
+	}
+
+	switch {
+	case quality == 0:
+		return LockTypeNone
+	case quality == 0x8000: // PASSWORD_QUALITY_BIOMETRIC_WEAK
+		return LockTypeBiometric
+	case quality == 0x10000: // PASSWORD_QUALITY_SOMETHING
+		return LockTypePattern
+	case quality == 0x20000 || quality == 0x30000: // PASSWORD_QUALITY_NUMERIC(_COMPLEX)
+		return LockTypePIN
+	case quality >= 0x40000: // PASSWORD_QUALITY_ALPHABETIC and stronger
+		return LockTypePassword
+	default:
+		return LockTypeUnknown
+	}
+}
+
+// GetKeyguardIsShowing checks whether the keyguard is showing using the window policy dump
+func (a *AndroidLockScreenDisabler) GetKeyguardIsShowing(deviceSerial string) (bool, error) {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return false, err
+	}
+	return a.getKeyguardIsShowing(context.Background(), deviceSerial)
+}
+
+// getKeyguardIsShowing implements GetKeyguardIsShowing, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) getKeyguardIsShowing(ctx context.Context, deviceSerial string) (bool, error) {
+	success, output, errorMsg := a.runADBCommand(ctx, "shell dumpsys window policy", deviceSerial)
+	if !success {
+		return false, fmt.Errorf("failed to read window policy on device %s: %s", deviceSerial, errorMsg)
+	}
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		lowerLine := strings.ToLower(line)
+		idx := strings.Index(lowerLine, "keyguardshowing=")
+		if idx == -1 {
+			continue
+		}
+
+		value := lowerLine[idx+len("keyguardshowing="):]
+		switch {
+		case strings.HasPrefix(value, "true"):
+			return true, nil
+		case strings.HasPrefix(value, "false"):
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("keyguardShowing not found in window policy output")
 }
 
 // CheckLockScreenStatus checks if device is showing lock screen
 func (a *AndroidLockScreenDisabler) CheckLockScreenStatus(deviceSerial string) (bool, error) {
-	a.log(fmt.Sprintf("Checking lock screen status on device %s...", deviceSerial), "🔍")
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return false, err
+	}
+	return a.checkLockScreenStatus(context.Background(), deviceSerial)
+}
+
+// checkLockScreenStatus implements CheckLockScreenStatus, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) checkLockScreenStatus(ctx context.Context, deviceSerial string) (bool, error) {
+	a.log(LogLevelDebug, fmt.Sprintf("Checking lock screen status on device %s...", a.deviceName(deviceSerial)), "🔍", "device", deviceSerial)
+
+	// Method 0: Query keyguard state via window policy (stable across Android versions)
+	if showing, err := a.getKeyguardIsShowing(ctx, deviceSerial); err == nil {
+		return showing, nil
+	}
 
 	// Method 1: Check if keyguard is showing
-	success, output, _ := a.runADBCommand("shell dumpsys window", deviceSerial)
+	success, output, _ := a.runADBCommand(ctx, "shell dumpsys window", deviceSerial)
 	if success && output != "" {
 		lines := strings.Split(output, "\n")
 		for _, line := range lines {
@@ -117,7 +315,7 @@ func (a *AndroidLockScreenDisabler) CheckLockScreenStatus(deviceSerial string) (
 	}
 
 	// Method 2: Check power manager state
-	success, output, _ = a.runADBCommand("shell dumpsys power", deviceSerial)
+	success, output, _ = a.runADBCommand(ctx, "shell dumpsys power", deviceSerial)
 	if success && output != "" {
 		lines := strings.Split(output, "\n")
 		for _, line := range lines {
@@ -131,7 +329,7 @@ func (a *AndroidLockScreenDisabler) CheckLockScreenStatus(deviceSerial string) (
 	}
 
 	// Method 3: Try to get current activity (may fail if locked)
-	success, output, _ = a.runADBCommand("shell dumpsys activity activities", deviceSerial)
+	success, output, _ = a.runADBCommand(ctx, "shell dumpsys activity activities", deviceSerial)
 	if success && output != "" {
 		lines := strings.Split(output, "\n")
 		for _, line := range lines {
@@ -148,12 +346,12 @@ func (a *AndroidLockScreenDisabler) CheckLockScreenStatus(deviceSerial string) (
 	}
 
 	// Method 4: Check settings values
-	success, output, _ = a.runADBCommand("shell settings get secure lockscreen.disabled", deviceSerial)
+	success, output, _ = a.runADBCommand(ctx, "shell settings get secure lockscreen.disabled", deviceSerial)
 	if success && output == "1" {
 		return false, nil // Lock screen is disabled in settings
 	}
 
-	success, output, _ = a.runADBCommand("shell locksettings get-disabled", deviceSerial)
+	success, output, _ = a.runADBCommand(ctx, "shell locksettings get-disabled", deviceSerial)
 	if success && strings.Contains(strings.ToLower(output), "true") {
 		return false, nil // Lock screen is disabled via locksettings
 	}
@@ -162,35 +360,91 @@ func (a *AndroidLockScreenDisabler) CheckLockScreenStatus(deviceSerial string) (
 	return true, fmt.Errorf("unable to determine lock screen status definitively")
 }
 
-// ValidateLockScreenRemoval validates that lock screen has been successfully removed after reboot
-func (a *AndroidLockScreenDisabler) ValidateLockScreenRemoval(deviceSerial string) bool {
-	a.log(fmt.Sprintf("Validating lock screen removal on device %s...", deviceSerial), "🔍")
+// rebootlessMinSDK is the lowest API level on which Methods 1 and 2 may take effect without a reboot
+const rebootlessMinSDK = 26
+
+// mayApplyWithoutReboot reports whether the method that succeeded may have removed the lock screen
+// without a reboot, so VerifyWithoutReboot is worth a try
+func mayApplyWithoutReboot(methodIndex, sdkInt int) bool {
+	return (methodIndex == 1 || methodIndex == 2) && sdkInt >= rebootlessMinSDK
+}
+
+// VerifyWithoutReboot reports whether the lock screen is already gone after a successful method attempt,
+// without rebooting: it waits 3 seconds for the settings to take effect and checks the lock screen status
+func (a *AndroidLockScreenDisabler) VerifyWithoutReboot(serial string) bool {
+	if a.checkSerial(serial) != nil {
+		return false
+	}
+	return a.verifyWithoutReboot(context.Background(), serial)
+}
+
+// verifyWithoutReboot implements VerifyWithoutReboot, bounding every ADB command by ctx
+func (a *AndroidLockScreenDisabler) verifyWithoutReboot(ctx context.Context, serial string) bool {
+	if !a.cfg.sleep(ctx, 3*time.Second) {
+		return false
+	}
+
+	locked, err := a.checkLockScreenStatus(ctx, serial)
+	if err != nil {
+		a.log(LogLevelDebug, fmt.Sprintf("Could not verify lock screen removal without reboot on device %s: %v", a.deviceName(serial), err), "❓", "device", serial)
+		return false
+	}
+	return !locked
+}
+
+// ValidateLockScreenRemoval validates that lock screen has been successfully removed after reboot,
+// returning an error wrapping ErrValidationFailed if it is still present or its status is unknown
+func (a *AndroidLockScreenDisabler) ValidateLockScreenRemoval(deviceSerial string) error {
+	if err := a.checkSerial(deviceSerial); err != nil {
+		return err
+	}
+	return a.validateLockScreenRemoval(context.Background(), deviceSerial)
+}
+
+// validateLockScreenRemoval implements ValidateLockScreenRemoval, bounding every ADB command and pause by ctx.
+// It returns ctx's error if ctx is done while waiting for the device to settle.
+func (a *AndroidLockScreenDisabler) validateLockScreenRemoval(ctx context.Context, deviceSerial string) error {
+	a.log(LogLevelDebug, fmt.Sprintf("Validating lock screen removal on device %s...", a.deviceName(deviceSerial)), "🔍", "device", deviceSerial)
 
 	// Wait a moment for UI to stabilize
-	time.Sleep(3 * time.Second)
+	if !a.cfg.sleep(ctx, 3*time.Second) {
+		return ctx.Err()
+	}
+
+	// The device may have gone to sleep while settling, and a sleeping device always looks locked
+	if on, err := a.isScreenOn(ctx, deviceSerial); err != nil {
+		a.log(LogLevelDebug, fmt.Sprintf("Could not read screen state on device %s: %v", a.deviceName(deviceSerial), err), "❓", "device", deviceSerial)
+	} else if !on {
+		a.log(LogLevelDebug, fmt.Sprintf("Screen is off on device %s, waking it before validating", a.deviceName(deviceSerial)), "💡", "device", deviceSerial)
+		if err := a.wakeScreen(ctx, deviceSerial); err != nil {
+			a.log(LogLevelDebug, err.Error(), "❌", "device", deviceSerial)
+		}
+	}
 
 	// Check lock screen status
-	isLocked, err := a.CheckLockScreenStatus(deviceSerial)
+	isLocked, err := a.checkLockScreenStatus(ctx, deviceSerial)
 
 	if err != nil {
-		a.log(fmt.Sprintf("Warning: Could not definitively determine lock screen status on device %s: %v",
-			deviceSerial, err), "⚠️")
+		a.log(LogLevelWarn, fmt.Sprintf("Warning: Could not definitively determine lock screen status on device %s: %v",
+			a.deviceName(deviceSerial), err), "⚠️", "device", deviceSerial)
 		// Try to wake up the device and check again
-		a.runADBCommand("shell input keyevent KEYCODE_WAKEUP", deviceSerial)
-		time.Sleep(2 * time.Second)
+		a.runADBCommand(ctx, "shell input keyevent KEYCODE_WAKEUP", deviceSerial)
+		if !a.cfg.sleep(ctx, 2*time.Second) {
+			return ctx.Err()
+		}
 
-		isLocked, err = a.CheckLockScreenStatus(deviceSerial)
+		isLocked, err = a.checkLockScreenStatus(ctx, deviceSerial)
 		if err != nil {
-			a.log(fmt.Sprintf("Still unable to determine lock screen status on device %s", deviceSerial), "⚠️")
-			return false
+			a.log(LogLevelWarn, fmt.Sprintf("Still unable to determine lock screen status on device %s", a.deviceName(deviceSerial)), "⚠️", "device", deviceSerial)
+			return fmt.Errorf("%w: lock screen status of device %s unknown: %v", ErrValidationFailed, deviceSerial, err)
 		}
 	}
 
 	if !isLocked {
-		a.log(fmt.Sprintf("✅ Lock screen successfully removed on device %s!", deviceSerial), "🎉")
-		return true
+		a.log(LogLevelInfo, fmt.Sprintf("✅ Lock screen successfully removed on device %s!", a.deviceName(deviceSerial)), "🎉", "device", deviceSerial)
+		return nil
 	} else {
-		a.log(fmt.Sprintf("❌ Lock screen is still present on device %s", deviceSerial), "😞")
-		return false
+		a.log(LogLevelWarn, fmt.Sprintf("❌ Lock screen is still present on device %s", a.deviceName(deviceSerial)), "😞", "device", deviceSerial)
+		return fmt.Errorf("%w: lock screen still present on device %s", ErrValidationFailed, deviceSerial)
 	}
 }
@@ -0,0 +1,411 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSerial is the device serial used by tests running against a MockADBExecutor
+const testSerial = "TEST123"
+
+// newMockDisabler creates a disabler that runs its ADB commands against a MockADBExecutor
+// with the given responses, logs nothing and does not sleep
+func newMockDisabler(t *testing.T, responses map[string]MockResponse, opts ...Option) (*AndroidLockScreenDisabler, *MockADBExecutor) {
+	t.Helper()
+
+	mock := NewMockADBExecutor(responses)
+	a, err := New(append([]Option{WithADBExecutor(mock), WithLogging(false)}, opts...)...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a.cfg.sleep = func(ctx context.Context, _ time.Duration) bool {
+		return ctx.Err() == nil
+	}
+	return a, mock
+}
+
+// ok returns a successful MockResponse printing stdout
+func ok(stdout string) MockResponse {
+	return MockResponse{Stdout: stdout}
+}
+
+func TestCheckExistingLockScreen(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]MockResponse
+		wantType  LockType
+		wantDesc  string
+		wantErr   bool
+	}{
+		{
+			name: "Android 5 stock AOSP without lock",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0\n" +
+					"   Enabled agents:\n" +
+					"   Events (max=64):\n"),
+				"shell locksettings get-disabled":                    {ExitCode: 127, Stdout: "/system/bin/sh: locksettings: not found"},
+				"shell settings get secure lock_pattern_enabled":     ok("0"),
+				"shell settings get secure lockscreen.password_type": ok("0"),
+				"shell settings get secure lockscreen.disabled":      ok("null"),
+				"shell dumpsys device_policy":                        ok(""),
+			},
+			wantType: LockTypeNone,
+			wantDesc: "No lock screen detected",
+		},
+		{
+			name: "Android 5 pattern",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0\n"),
+				"shell locksettings get-disabled":                {ExitCode: 127, Stdout: "/system/bin/sh: locksettings: not found"},
+				"shell settings get secure lock_pattern_enabled": ok("1"),
+			},
+			wantType: LockTypePattern,
+			wantDesc: "lock pattern enabled",
+		},
+		{
+			name: "Android 7 PIN",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=1, deviceLocked=1\n" +
+					"   Enabled agents:\n"),
+				"shell locksettings get-disabled":                    ok("false"),
+				"shell settings get secure lockscreen.password_type": ok("131072"),
+			},
+			wantType: LockTypePIN,
+			wantDesc: "detected via locksettings",
+		},
+		{
+			name: "Android 9 password",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0, deviceLocked=1, strongAuthRequired=0x0\n" +
+					" isDeviceSecure=true\n"),
+				"shell settings get secure lockscreen.password_type": ok("262144"),
+			},
+			wantType: LockTypePassword,
+			wantDesc: "detected via trust manager",
+		},
+		{
+			name: "Android 10 without lock",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0, deviceLocked=0, strongAuthRequired=0x0\n" +
+					" isDeviceSecure=false\n"),
+				"shell locksettings get-disabled":               ok("true"),
+				"shell settings get secure lockscreen.disabled": ok("1"),
+			},
+			wantType: LockTypeNone,
+			wantDesc: "No lock screen detected",
+		},
+		{
+			name: "Android 12 keyguard secure",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0xc13) (current): trusted=0, trustManaged=0, deviceLocked=1, isActiveUnlockRunning=0\n" +
+					" IsKeyguardSecure=TRUE\n"),
+				"shell settings get secure lock_pattern_enabled": ok("1"),
+			},
+			wantType: LockTypePattern,
+			wantDesc: "detected via trust manager",
+		},
+		{
+			name: "Android 14 biometric",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0xc13) (current): trusted=0, trustManaged=0, deviceLocked=1, isActiveUnlockRunning=0, strongAuthRequired=0x0\n" +
+					" isDeviceSecure=true\n"),
+				"shell settings get secure lockscreen.password_type": ok("null"),
+				"shell dumpsys fingerprint":                          ok("Fingerprint Manager:\n user 0 enrolled: 2\n"),
+			},
+			wantType: LockTypeBiometric,
+			wantDesc: "detected via trust manager",
+		},
+		{
+			name: "Samsung One UI numeric complex PIN",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0x13) (current): trusted=0, trustManaged=0, deviceLocked=1\n" +
+					"   Enabled agents:\n" +
+					"    com.samsung.android.bixby.agent/.trustagent.SamsungTrustAgent\n" +
+					" isDeviceSecure=true\n"),
+				"shell settings get secure lockscreen.password_type": ok("196608"),
+			},
+			wantType: LockTypePIN,
+			wantDesc: "detected via trust manager",
+		},
+		{
+			name: "Pixel with Smart Lock",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust": ok("Trust manager state:\n" +
+					" User \"Owner\" (id=0, flags=0xc13) (current): trusted=1, trustManaged=1, deviceLocked=0\n" +
+					"   Enabled agents:\n" +
+					"    com.google.android.gms/.auth.trustagent.GoogleTrustAgent\n" +
+					"     bound=1, connected=1, managingTrust=1, trusted=1\n" +
+					" isDeviceSecure=true\n"),
+				"shell settings get secure lock_pattern_enabled": ok("1"),
+			},
+			wantType: LockTypePattern,
+			wantDesc: "detected via trust manager",
+		},
+		{
+			name: "Xiaomi MIUI without trust service",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust":             {ExitCode: 0, Stdout: "Can't find service: trust"},
+				"shell locksettings get-disabled": {ExitCode: 255, Stdout: "cmd: Failure calling service lock_settings"},
+				"shell dumpsys activity services KeyguardService": ok("ACTIVITY MANAGER SERVICES (dumpsys activity services)\n" +
+					"  * ServiceRecord{5f1c2d0 u0 com.android.systemui/.keyguard.KeyguardService}\n" +
+					"    KeyguardViewMediator: secure=true showing=true\n"),
+			},
+			wantType: LockTypeUnknown,
+			wantDesc: "detected via KeyguardService",
+		},
+		{
+			name: "stock AOSP lock screen explicitly enabled",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust":                           ok("Trust manager state:\n"),
+				"shell settings get secure lockscreen.disabled": ok("0"),
+			},
+			wantType: LockTypeUnknown,
+			wantDesc: "explicitly enabled",
+		},
+		{
+			name: "admin password policy",
+			responses: map[string]MockResponse{
+				"shell dumpsys trust":             ok("Trust manager state:\n"),
+				"shell locksettings get-disabled": ok("true"),
+				"shell dumpsys device_policy": ok("Enabled Device Admins (User 0, provisioningState: 0):\n" +
+					"  com.example.mdm/.AdminReceiver:\n" +
+					"    passwordQuality=0x20000\n" +
+					"    minimumPasswordLength=6\n"),
+			},
+			wantType: LockTypeAdmin,
+			wantDesc: "admin-enforced password policy",
+		},
+		{
+			name:      "no command succeeds",
+			responses: map[string]MockResponse{},
+			wantType:  LockTypeUnknown,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newMockDisabler(t, tt.responses)
+
+			info, err := a.CheckExistingLockScreen(testSerial)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckExistingLockScreen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if info.Type != tt.wantType {
+				t.Errorf("CheckExistingLockScreen() type = %q, want %q", info.Type, tt.wantType)
+			}
+			if !strings.Contains(info.Description, tt.wantDesc) {
+				t.Errorf("CheckExistingLockScreen() description = %q, want it to contain %q", info.Description, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestCheckLockScreenStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  map[string]MockResponse
+		wantLocked bool
+		wantErr    bool
+	}{
+		{
+			name:       "window policy keyguard showing",
+			responses:  map[string]MockResponse{"shell dumpsys window policy": ok("    mKeyguardDelegate\n      KeyguardShowing=true\n")},
+			wantLocked: true,
+		},
+		{
+			name:       "window policy keyguard hidden",
+			responses:  map[string]MockResponse{"shell dumpsys window policy": ok("    isKeyguardShowingAndNotOccluded=false keyguardShowing=false\n")},
+			wantLocked: false,
+		},
+		{
+			name: "window dump dreaming lock screen",
+			responses: map[string]MockResponse{
+				"shell dumpsys window": ok("  mDreamingLockscreen=true mDreamingSleepToken=null\n"),
+			},
+			wantLocked: true,
+		},
+		{
+			name: "power manager asleep",
+			responses: map[string]MockResponse{
+				"shell dumpsys power": ok("Power Manager State:\n  mWakefulness=Asleep\n"),
+			},
+			wantLocked: true,
+		},
+		{
+			name: "launcher resumed",
+			responses: map[string]MockResponse{
+				"shell dumpsys activity activities": ok("  mResumedActivity: ActivityRecord{a1b2c3 u0 com.google.android.apps.nexuslauncher/.NexusLauncherActivity t12}\n"),
+			},
+			wantLocked: false,
+		},
+		{
+			name: "locksettings disabled",
+			responses: map[string]MockResponse{
+				"shell locksettings get-disabled": ok("true"),
+			},
+			wantLocked: false,
+		},
+		{
+			name:       "undetermined",
+			responses:  map[string]MockResponse{},
+			wantLocked: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newMockDisabler(t, tt.responses)
+
+			locked, err := a.CheckLockScreenStatus(testSerial)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckLockScreenStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if locked != tt.wantLocked {
+				t.Errorf("CheckLockScreenStatus() = %v, want %v", locked, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestValidateLockScreenRemoval(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]MockResponse
+		wantErr   error
+	}{
+		{
+			name: "lock screen removed",
+			responses: map[string]MockResponse{
+				"shell dumpsys window policy": ok("keyguardShowing=false"),
+			},
+		},
+		{
+			name: "lock screen still present",
+			responses: map[string]MockResponse{
+				"shell dumpsys window policy": ok("keyguardShowing=true"),
+			},
+			wantErr: ErrValidationFailed,
+		},
+		{
+			name:      "status unknown",
+			responses: map[string]MockResponse{},
+			wantErr:   ErrValidationFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := newMockDisabler(t, tt.responses)
+
+			err := a.ValidateLockScreenRemoval(testSerial)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateLockScreenRemoval() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLockScreenRemovalCancelled(t *testing.T) {
+	a, mock := newMockDisabler(t, map[string]MockResponse{"shell dumpsys window policy": ok("keyguardShowing=false")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := a.validateLockScreenRemoval(ctx, testSerial); !errors.Is(err, context.Canceled) {
+		t.Errorf("validateLockScreenRemoval() error = %v, want %v", err, context.Canceled)
+	}
+	if calls := mock.Calls(); len(calls) != 0 {
+		t.Errorf("validateLockScreenRemoval() ran %v after cancellation, want nothing", calls)
+	}
+}
+
+func TestLockTypeFromPasswordType(t *testing.T) {
+	tests := []struct {
+		value string
+		want  LockType
+	}{
+		{"0", LockTypeNone},
+		{"32768", LockTypeBiometric},
+		{"65536", LockTypePattern},
+		{"131072", LockTypePIN},
+		{"196608", LockTypePIN},
+		{"262144", LockTypePassword},
+		{"327680", LockTypePassword},
+		{"393216", LockTypePassword},
+		{" 131072 ", LockTypePIN},
+		{"4096", LockTypeUnknown},
+		{"null", LockTypeUnknown},
+		{"", LockTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := lockTypeFromPasswordType(tt.value); got != tt.want {
+			t.Errorf("lockTypeFromPasswordType(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLockTypeFromSettings(t *testing.T) {
+	failed := CommandResult{ExitCode: 1}
+
+	tests := []struct {
+		name           string
+		patternEnabled CommandResult
+		passwordType   CommandResult
+		want           LockType
+	}{
+		{"pattern enabled", CommandResult{Output: "1"}, CommandResult{Output: "131072"}, LockTypePattern},
+		{"PIN", CommandResult{Output: "0"}, CommandResult{Output: "131072"}, LockTypePIN},
+		{"password without pattern setting", failed, CommandResult{Output: "262144"}, LockTypePassword},
+		{"biometric", CommandResult{Output: "null"}, CommandResult{Output: "32768"}, LockTypeBiometric},
+		{"no credential quality", CommandResult{Output: "0"}, CommandResult{Output: "0"}, LockTypeUnknown},
+		{"unreadable", failed, failed, LockTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lockTypeFromSettings(tt.patternEnabled, tt.passwordType); got != tt.want {
+				t.Errorf("lockTypeFromSettings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDeviceAuthorizationStatus(t *testing.T) {
+	devices := ok("List of devices attached\n" +
+		"AUTHORIZED\t" + DeviceStateDevice + "\n" +
+		"UNAUTHORIZED\t" + DeviceStateUnauthorized + "\n" +
+		"OFFLINE\t" + DeviceStateOffline + "\n" +
+		"NOPERM\t" + DeviceStateNoPermissions + " (user in plugdev group; are your udev rules wrong?)\n")
+
+	tests := []struct {
+		serial string
+		want   AuthStatus
+	}{
+		{"AUTHORIZED", AuthStatusAuthorized},
+		{"UNAUTHORIZED", AuthStatusUnauthorized},
+		{"OFFLINE", AuthStatusOffline},
+		{"NOPERM", AuthStatusUnknown},
+		{"MISSING", AuthStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		a, _ := newMockDisabler(t, map[string]MockResponse{"devices": devices})
+		if got := a.GetDeviceAuthorizationStatus(tt.serial); got != tt.want {
+			t.Errorf("GetDeviceAuthorizationStatus(%q) = %q, want %q", tt.serial, got, tt.want)
+		}
+	}
+}
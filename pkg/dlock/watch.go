@@ -0,0 +1,226 @@
+package dlock
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultWatchGracePeriod is how long a processed device may stay disconnected without being processed again
+const defaultWatchGracePeriod = 2 * time.Minute
+
+// watchHeartbeatInterval is how often Watch logs the progress of the devices it processed
+const watchHeartbeatInterval = time.Minute
+
+// Watch follows `adb track-devices` and processes every device that comes online until ctx is done.
+// Devices are processed once; a processed device that reconnects within the grace period is skipped.
+// In-flight devices are cancelled and waited for before Watch returns nil.
+func (a *AndroidLockScreenDisabler) Watch(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		processed sync.Map // serial -> time the device was last seen leaving, zero while connected
+		inFlight  sync.Map // serial -> struct{} while the device is being processed
+		online    = make(map[string]bool)
+		stats     = NewProcessingStats(0)
+		slots     = make(chan struct{}, a.cfg.concurrency)
+		started   bool
+	)
+
+	a.batchStats.Store(stats)
+
+	err := a.trackDevices(ctx, func() {
+		started = true
+		a.log(LogLevelInfo, "Watching for new devices (press Ctrl+C to stop)...", "👀")
+		go a.watchHeartbeat(ctx, stats)
+	}, func(devices map[string]string) {
+		now := time.Now()
+		for serial := range online {
+			if devices[serial] != DeviceStateDevice {
+				delete(online, serial)
+				if _, ok := processed.Load(serial); ok {
+					processed.Store(serial, now)
+				}
+				a.log(LogLevelDebug, fmt.Sprintf("Device %s disconnected", a.deviceName(serial)), "🔌", "device", serial)
+			}
+		}
+
+		for serial, state := range devices {
+			if state != DeviceStateDevice || online[serial] {
+				continue
+			}
+			online[serial] = true
+
+			if !a.isTargetDevice(serial) || !a.shouldProcessWatchedDevice(serial, now, &processed, &inFlight) {
+				continue
+			}
+
+			a.log(LogLevelInfo, fmt.Sprintf("New device detected: %s", a.deviceName(serial)), "📱", "device", serial)
+			processed.Store(serial, time.Time{})
+			inFlight.Store(serial, struct{}{})
+			stats.addDevice()
+			wg.Add(1)
+			go func(serial string) {
+				defer inFlight.Delete(serial)
+				a.processDeviceInSlot(ctx, slots, serial, stats, &wg)
+			}(serial)
+		}
+	})
+	if !started {
+		return err
+	}
+
+	cancel()
+	a.log(LogLevelInfo, "Stopping watch, waiting for in-flight devices...", "⏳")
+	wg.Wait()
+	return err
+}
+
+// watchHeartbeat logs the live statistics of a watch every watchHeartbeatInterval until ctx is done
+func (a *AndroidLockScreenDisabler) watchHeartbeat(ctx context.Context, stats *ProcessingStats) {
+	ticker := time.NewTicker(watchHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		live := stats.GetLiveStats()
+		a.log(LogLevelInfo, fmt.Sprintf("Still watching: %d device(s) processed, %d in progress, %d succeeded, %d failed",
+			live.Total, live.InProgress, live.Success, live.Failed), "💓")
+	}
+}
+
+// DeviceStateChange reports a device whose adb state changed
+type DeviceStateChange struct {
+	Serial string
+	State  string // adb state such as "device", "unauthorized" or "offline", or "disconnected"
+}
+
+// WatchDeviceStates follows `adb track-devices` and calls onChange for every device that connects,
+// disconnects or changes state until ctx is done. Devices connected when the watch starts are reported first.
+// It returns nil once ctx is done.
+func (a *AndroidLockScreenDisabler) WatchDeviceStates(ctx context.Context, onChange func(DeviceStateChange)) error {
+	known := make(map[string]string)
+	return a.trackDevices(ctx, nil, func(devices map[string]string) {
+		for serial := range known {
+			if _, ok := devices[serial]; !ok {
+				delete(known, serial)
+				onChange(DeviceStateChange{Serial: serial, State: "disconnected"})
+			}
+		}
+
+		for serial, state := range devices {
+			if known[serial] == state {
+				continue
+			}
+			known[serial] = state
+			onChange(DeviceStateChange{Serial: serial, State: state})
+		}
+	})
+}
+
+// trackDevices streams `adb track-devices` and calls onSnapshot with the state of every listed device
+// each time the list changes. onStart, if set, is called once the stream is running.
+// It returns nil once ctx is done, or the error that ended the stream.
+func (a *AndroidLockScreenDisabler) trackDevices(ctx context.Context, onStart func(), onSnapshot func(map[string]string)) error {
+	streamer, ok := a.cfg.executor.(ADBStreamer)
+	if !ok {
+		return fmt.Errorf("watch requires an executor that supports streaming: %w", ErrNotSupported)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := streamer.Stream(ctx, []string{"track-devices"})
+	if err != nil {
+		return fmt.Errorf("failed to start adb track-devices: %w", err)
+	}
+	defer stream.Close()
+
+	// Close the stream on cancellation so the blocked read below returns
+	go func() {
+		<-ctx.Done()
+		stream.Close()
+	}()
+
+	if onStart != nil {
+		onStart()
+	}
+
+	reader := bufio.NewReader(stream)
+	for {
+		devices, err := readTrackDevicesSnapshot(reader)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("adb track-devices stopped: %w", err)
+		}
+		onSnapshot(devices)
+	}
+}
+
+// shouldProcessWatchedDevice reports whether a device that just came online needs processing
+func (a *AndroidLockScreenDisabler) shouldProcessWatchedDevice(serial string, now time.Time, processed, inFlight *sync.Map) bool {
+	if _, busy := inFlight.Load(serial); busy {
+		processed.Store(serial, time.Time{})
+		return false
+	}
+
+	value, seen := processed.Load(serial)
+	if !seen {
+		return true
+	}
+
+	leftAt := value.(time.Time)
+	if leftAt.IsZero() || now.Sub(leftAt) <= a.cfg.watchGracePeriod {
+		a.log(LogLevelDebug, fmt.Sprintf("Device %s reconnected within the grace period, skipping", a.deviceName(serial)), "⏭️", "device", serial)
+		processed.Store(serial, time.Time{})
+		return false
+	}
+	return true
+}
+
+// isTargetDevice reports whether a device is selected by the configured target devices
+func (a *AndroidLockScreenDisabler) isTargetDevice(serial string) bool {
+	if len(a.cfg.targetDevices) == 0 {
+		return true
+	}
+	for _, target := range a.cfg.targetDevices {
+		if target == serial {
+			return true
+		}
+	}
+	return false
+}
+
+// readTrackDevicesSnapshot reads one length-prefixed device list from `adb track-devices`
+// and returns the state of every listed device keyed by serial
+func readTrackDevicesSnapshot(r *bufio.Reader) (map[string]string, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.ParseUint(string(header), 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid track-devices length prefix %q", header)
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	return parseDeviceStates(string(payload)), nil
+}
@@ -0,0 +1,264 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: dlock.proto
+
+package dlockpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	DlockService_ProcessDevices_FullMethodName      = "/dlock.v1.DlockService/ProcessDevices"
+	DlockService_GetConnectedDevices_FullMethodName = "/dlock.v1.DlockService/GetConnectedDevices"
+	DlockService_GetDeviceInfo_FullMethodName       = "/dlock.v1.DlockService/GetDeviceInfo"
+	DlockService_WatchDevices_FullMethodName        = "/dlock.v1.DlockService/WatchDevices"
+)
+
+// DlockServiceClient is the client API for DlockService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DlockService disables lock screens on the devices connected to the host running the server
+type DlockServiceClient interface {
+	// ProcessDevices disables the lock screen of the given devices, or of every connected device when none are given
+	ProcessDevices(ctx context.Context, in *DevicesRequest, opts ...grpc.CallOption) (*BatchResult, error)
+	// GetConnectedDevices lists the serials of the connected devices
+	GetConnectedDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DeviceList, error)
+	// GetDeviceInfo returns the model, manufacturer and Android version of a device
+	GetDeviceInfo(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (*DeviceInfoResponse, error)
+	// WatchDevices streams an event every time a device connects, disconnects or changes state
+	WatchDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DlockService_WatchDevicesClient, error)
+}
+
+type dlockServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDlockServiceClient(cc grpc.ClientConnInterface) DlockServiceClient {
+	return &dlockServiceClient{cc}
+}
+
+func (c *dlockServiceClient) ProcessDevices(ctx context.Context, in *DevicesRequest, opts ...grpc.CallOption) (*BatchResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchResult)
+	err := c.cc.Invoke(ctx, DlockService_ProcessDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dlockServiceClient) GetConnectedDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*DeviceList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceList)
+	err := c.cc.Invoke(ctx, DlockService_GetConnectedDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dlockServiceClient) GetDeviceInfo(ctx context.Context, in *DeviceRequest, opts ...grpc.CallOption) (*DeviceInfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceInfoResponse)
+	err := c.cc.Invoke(ctx, DlockService_GetDeviceInfo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dlockServiceClient) WatchDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (DlockService_WatchDevicesClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DlockService_ServiceDesc.Streams[0], DlockService_WatchDevices_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dlockServiceWatchDevicesClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DlockService_WatchDevicesClient interface {
+	Recv() (*DeviceEvent, error)
+	grpc.ClientStream
+}
+
+type dlockServiceWatchDevicesClient struct {
+	grpc.ClientStream
+}
+
+func (x *dlockServiceWatchDevicesClient) Recv() (*DeviceEvent, error) {
+	m := new(DeviceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DlockServiceServer is the server API for DlockService service.
+// All implementations must embed UnimplementedDlockServiceServer
+// for forward compatibility
+//
+// DlockService disables lock screens on the devices connected to the host running the server
+type DlockServiceServer interface {
+	// ProcessDevices disables the lock screen of the given devices, or of every connected device when none are given
+	ProcessDevices(context.Context, *DevicesRequest) (*BatchResult, error)
+	// GetConnectedDevices lists the serials of the connected devices
+	GetConnectedDevices(context.Context, *Empty) (*DeviceList, error)
+	// GetDeviceInfo returns the model, manufacturer and Android version of a device
+	GetDeviceInfo(context.Context, *DeviceRequest) (*DeviceInfoResponse, error)
+	// WatchDevices streams an event every time a device connects, disconnects or changes state
+	WatchDevices(*Empty, DlockService_WatchDevicesServer) error
+	mustEmbedUnimplementedDlockServiceServer()
+}
+
+// UnimplementedDlockServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDlockServiceServer struct {
+}
+
+func (UnimplementedDlockServiceServer) ProcessDevices(context.Context, *DevicesRequest) (*BatchResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessDevices not implemented")
+}
+func (UnimplementedDlockServiceServer) GetConnectedDevices(context.Context, *Empty) (*DeviceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnectedDevices not implemented")
+}
+func (UnimplementedDlockServiceServer) GetDeviceInfo(context.Context, *DeviceRequest) (*DeviceInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeviceInfo not implemented")
+}
+func (UnimplementedDlockServiceServer) WatchDevices(*Empty, DlockService_WatchDevicesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchDevices not implemented")
+}
+func (UnimplementedDlockServiceServer) mustEmbedUnimplementedDlockServiceServer() {}
+
+// UnsafeDlockServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DlockServiceServer will
+// result in compilation errors.
+type UnsafeDlockServiceServer interface {
+	mustEmbedUnimplementedDlockServiceServer()
+}
+
+func RegisterDlockServiceServer(s grpc.ServiceRegistrar, srv DlockServiceServer) {
+	s.RegisterService(&DlockService_ServiceDesc, srv)
+}
+
+func _DlockService_ProcessDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DlockServiceServer).ProcessDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DlockService_ProcessDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DlockServiceServer).ProcessDevices(ctx, req.(*DevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DlockService_GetConnectedDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DlockServiceServer).GetConnectedDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DlockService_GetConnectedDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DlockServiceServer).GetConnectedDevices(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DlockService_GetDeviceInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DlockServiceServer).GetDeviceInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DlockService_GetDeviceInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DlockServiceServer).GetDeviceInfo(ctx, req.(*DeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DlockService_WatchDevices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DlockServiceServer).WatchDevices(m, &dlockServiceWatchDevicesServer{ServerStream: stream})
+}
+
+type DlockService_WatchDevicesServer interface {
+	Send(*DeviceEvent) error
+	grpc.ServerStream
+}
+
+type dlockServiceWatchDevicesServer struct {
+	grpc.ServerStream
+}
+
+func (x *dlockServiceWatchDevicesServer) Send(m *DeviceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// DlockService_ServiceDesc is the grpc.ServiceDesc for DlockService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DlockService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "dlock.v1.DlockService",
+	HandlerType: (*DlockServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ProcessDevices",
+			Handler:    _DlockService_ProcessDevices_Handler,
+		},
+		{
+			MethodName: "GetConnectedDevices",
+			Handler:    _DlockService_GetConnectedDevices_Handler,
+		},
+		{
+			MethodName: "GetDeviceInfo",
+			Handler:    _DlockService_GetDeviceInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDevices",
+			Handler:       _DlockService_WatchDevices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "dlock.proto",
+}